@@ -3,39 +3,110 @@ package brain
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
+	"strings"
 	"text/template"
 	"time"
 
 	"entgo.io/ent/dialect/sql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/pikocloud/pikobrain/internal/documents"
 	"github.com/pikocloud/pikobrain/internal/ent"
 	"github.com/pikocloud/pikobrain/internal/ent/message"
+	"github.com/pikocloud/pikobrain/internal/metrics"
+	"github.com/pikocloud/pikobrain/internal/moderation"
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
 	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tracing"
 )
 
+var tracer = otel.Tracer("github.com/pikocloud/pikobrain/internal/brain")
+
 type Brain struct {
 	iterations int
 	parallel   bool
 	depth      int
 	db         *ent.Client
 	vision     *Vision
+	embedding  *Embedding
 	prompt     *template.Template
 	config     types.Config
 	provider   types.Provider
 	toolbox    types.Toolbox
 	definition Definition
+
+	capabilities types.Capabilities
+	transcriber  types.Transcriber
+	limiter      *ratelimit.Provider
+	inputChecks  *moderation.Checkers
+	outputChecks *moderation.Checkers
 }
 
 func (m *Brain) Definition() Definition {
 	return m.definition
 }
 
+// Capabilities of the main model (detected or overridden by definition).
+func (m *Brain) Capabilities() types.Capabilities {
+	return m.capabilities
+}
+
+// RateLimit returns stats of provider rate limiter. False if rate limit is not configured.
+func (m *Brain) RateLimit() (ratelimit.Stats, bool) {
+	if m.limiter == nil {
+		return ratelimit.Stats{}, false
+	}
+	return m.limiter.Stats(), true
+}
+
+// Embed texts using configured embedding model. Returns [ErrEmbeddingDisabled] if embedding model is not set.
+func (m *Brain) Embed(ctx context.Context, input []string) (_ *types.Embeddings, err error) {
+	if m.embedding == nil {
+		return nil, ErrEmbeddingDisabled
+	}
+	ctx, span := tracer.Start(ctx, "embed "+m.embedding.Model, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("gen_ai.system", m.definition.Provider.String()),
+		attribute.String("gen_ai.request.model", m.embedding.Model),
+		attribute.Int("inputs", len(input)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	// checked during initialization
+	embedder := m.provider.(types.Embedder)
+	started := time.Now()
+	res, err := embedder.Embed(ctx, m.embedding.Model, input)
+	var inputTokens int
+	if res != nil {
+		inputTokens = res.InputToken
+	}
+	metrics.Invoke(m.definition.Provider.String(), m.embedding.Model, time.Since(started), inputTokens, 0, 0, err)
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+	if len(res.Vectors) != len(input) {
+		return nil, fmt.Errorf("number of vectors (%d) doesn't match input (%d)", len(res.Vectors), len(input))
+	}
+	return res, nil
+}
+
 // Run model using only provided state.
 func (m *Brain) Run(ctx context.Context, messages []types.Message, thread string) (Response, error) {
+	return m.run(ctx, messages, thread, true)
+}
+
+// run model. Input moderation can be skipped if new messages are already checked (see [Brain.Append]).
+func (m *Brain) run(ctx context.Context, messages []types.Message, thread string, checkInput bool) (_ Response, err error) {
+	defer metrics.RunStarted()()
+	ctx, span := tracer.Start(ctx, "run", trace.WithAttributes(attribute.String("thread", thread), attribute.Int("messages", len(messages))))
+	defer func() { tracing.End(span, err) }()
+
 	// generate prompt
 	var prompt bytes.Buffer
 
@@ -51,56 +122,225 @@ func (m *Brain) Run(ctx context.Context, messages []types.Message, thread string
 
 	tools := m.toolbox.Snapshot()
 	toolSet := tools.Definitions()
+	if !m.capabilities.Tools {
+		toolSet = nil
+	}
 	var ans Response
 
-	// if vision model set - replace all images with results from vision
-	if m.vision != nil {
-		responses, err := m.replaceImagesByDescription(ctx, messages)
+	// original files, before they are replaced by text for the model
+	attachments := collectAttachments(messages)
+	if len(attachments) > 0 && len(toolSet) > 0 {
+		// tools reference files by labels
+		messages = labelAttachments(messages)
+	}
+
+	if m.transcriber != nil {
+		replaced, err := m.replaceAudioByTranscript(ctx, messages)
+		if err != nil {
+			return ans, err
+		}
+		messages = replaced
+	}
+
+	// if main model can not see and vision model set - replace all images with results from vision
+	if m.useVision() {
+		replaced, responses, err := m.replaceImagesByDescription(ctx, messages)
 		ans = append(ans, responses...)
 		if err != nil {
 			return ans, err
 		}
+		messages = replaced
+	}
+
+	if !m.capabilities.Documents {
+		converted, err := documentsToText(messages)
+		if err != nil {
+			return ans, err
+		}
+		messages = converted
+	}
+
+	if err := m.checkContent(messages); err != nil {
+		return ans, err
+	}
+
+	user, text := lastTurn(messages)
+	if checkInput && !m.inputChecks.Empty() {
+		if err := m.moderate(ctx, m.inputChecks, thread, user, text); err != nil {
+			return ans, err
+		}
 	}
 
+	// tools may restrict access by caller
+	ctx = types.WithCaller(ctx, types.Caller{Thread: thread, User: user})
+	ctx = types.WithAttachments(ctx, attachments)
+
 	slog.Debug("running model", "messages", len(messages), "tools", len(tools), "prompt", prompt.String())
 
-	for range m.iterations {
-		res, err := m.provider.Invoke(ctx, cfg, messages, toolSet)
+	for i := range m.iterations {
+		res, next, err := m.iterate(ctx, i, cfg, tools, toolSet, messages)
+		if res != nil {
+			ans = append(ans, res)
+		}
 		if err != nil {
-			return ans, fmt.Errorf("invoke provider: %w", err)
+			return ans, err
 		}
-		ans = append(ans, res)
-
-		calls := res.ToolCalls()
-		if len(calls) == 0 {
+		if next == nil {
 			break
 		}
+		messages = next
+	}
 
-		for _, msg := range res.Output {
-			slog.Debug("output message", "message", msg)
+	if reply := ans.Reply(); !m.outputChecks.Empty() && reply.Mime.IsText() {
+		if err := m.moderate(ctx, m.outputChecks, thread, "", string(reply.Data)); err != nil {
+			return ans, err
 		}
+	}
+	return ans, nil
+}
 
-		messages = append(messages, res.Output...)
-		// TODO: parallel call
-		for _, call := range calls {
-			slog.Debug("calling tool", "tool", call.ToolName, "id", call.ToolID, "input", call.Content.String())
-			started := time.Now()
-			result, err := tools.Call(ctx, call.ToolName, call.Content.Data)
-			if err != nil {
-				return ans, fmt.Errorf("call tool %q: %w", call.ToolName, err)
+// moderate text and record blocked content in DB.
+func (m *Brain) moderate(ctx context.Context, checks *moderation.Checkers, thread string, user string, text string) (err error) {
+	ctx, span := tracer.Start(ctx, "moderation", trace.WithAttributes(attribute.String("thread", thread)))
+	defer func() { tracing.End(span, err) }()
+
+	err = checks.Check(ctx, text)
+	var blocked *moderation.BlockedError
+	if !errors.As(err, &blocked) {
+		return err
+	}
+	metrics.Blocked(blocked.Stage.String(), blocked.Checker.String())
+	slog.Warn("content blocked by moderation", "stage", blocked.Stage, "checker", blocked.Checker, "reason", blocked.Reason, "thread", thread, "user", user)
+
+	record := m.db.Moderation.Create().SetStage(blocked.Stage).SetChecker(blocked.Checker).SetReason(blocked.Reason).SetContent([]byte(text))
+	if thread != "" {
+		record.SetThread(thread)
+	}
+	if user != "" {
+		record.SetUser(user)
+	}
+	if dbErr := record.Exec(ctx); dbErr != nil {
+		return errors.Join(err, fmt.Errorf("save moderation event: %w", dbErr))
+	}
+	return err
+}
+
+// labelAttachments adds text label before every attachment (in the same order as collectAttachments),
+// so the model can reference files by number or name. Messages are copied.
+func labelAttachments(messages []types.Message) []types.Message {
+	var ans = make([]types.Message, 0, len(messages))
+	var n int
+	for _, msg := range messages {
+		if msg.Role != types.RoleUser {
+			ans = append(ans, msg)
+			continue
+		}
+		var parts = make([]types.Content, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			if !part.Mime.IsText() {
+				n++
+				label := fmt.Sprintf("[attachment #%d: %s]", n, part.Mime)
+				if part.Name != "" {
+					label = fmt.Sprintf("[attachment #%d: %s, %s]", n, part.Name, part.Mime)
+				}
+				parts = append(parts, types.Text(label))
+			}
+			parts = append(parts, part)
+		}
+		msg.Parts = parts
+		ans = append(ans, msg)
+	}
+	return ans
+}
+
+// collectAttachments returns non-text parts of user messages.
+func collectAttachments(messages []types.Message) []types.Content {
+	var ans []types.Content
+	for _, msg := range messages {
+		if msg.Role != types.RoleUser {
+			continue
+		}
+		for _, part := range msg.Parts {
+			if !part.Mime.IsText() {
+				ans = append(ans, part)
 			}
-			duration := time.Since(started)
-			slog.Debug("call result", "tool", call.ToolName, "id", call.ToolID, "result", result.String(), "input", call.Content.String(), "duration", duration)
-			messages = append(messages, types.Message{
-				ToolID:   call.ToolID,
-				ToolName: call.ToolName,
-				Role:     types.RoleToolResult,
-				Content:  result,
-			})
 		}
+	}
+	return ans
+}
 
+// lastTurn returns author and text of user messages after the last model message (new input).
+func lastTurn(messages []types.Message) (user string, text string) {
+	var texts []string
+	for i := len(messages) - 1; i >= 0 && messages[i].Role == types.RoleUser; i-- {
+		texts = append(texts, messages[i].Text())
+		if user == "" {
+			user = messages[i].User
+		}
 	}
-	return ans, nil
+	slices.Reverse(texts)
+	return user, strings.Join(texts, "\n")
+}
+
+// iterate invokes model once and calls requested tools.
+// Returns messages for the next iteration or nil if model doesn't need tools anymore.
+func (m *Brain) iterate(ctx context.Context, iteration int, cfg types.Config, tools types.Snapshot, toolSet []types.ToolDefinition, messages []types.Message) (_ *types.Invoke, _ []types.Message, err error) {
+	ctx, span := tracer.Start(ctx, "iteration", trace.WithAttributes(attribute.Int("iteration", iteration)))
+	defer func() { tracing.End(span, err) }()
+
+	res, err := m.invoke(ctx, cfg, messages, toolSet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invoke provider: %w", err)
+	}
+
+	calls := res.ToolCalls()
+	if len(calls) == 0 {
+		return res, nil, nil
+	}
+
+	for _, msg := range res.Output {
+		slog.Debug("output message", "message", msg)
+	}
+
+	messages = append(messages, res.Output...)
+	// TODO: parallel call
+	for _, call := range calls {
+		result, err := m.callTool(ctx, tools, call)
+		if err != nil {
+			return res, nil, fmt.Errorf("call tool %q: %w", call.ToolName, err)
+		}
+		messages = append(messages, types.Message{
+			ToolID:   call.ToolID,
+			ToolName: call.ToolName,
+			Role:     types.RoleToolResult,
+			Parts:    []types.Content{result},
+		})
+	}
+	return res, messages, nil
+}
+
+func (m *Brain) callTool(ctx context.Context, tools types.Snapshot, call types.Message) (types.Content, error) {
+	return CallTool(ctx, tools, call.ToolName, call.ToolID, call.Content().Data)
+}
+
+// CallTool from snapshot with tracing and metrics. Call ID is optional.
+func CallTool(ctx context.Context, tools types.Snapshot, name string, id string, input json.RawMessage) (_ types.Content, err error) {
+	ctx, span := tracer.Start(ctx, "tool "+name, trace.WithAttributes(
+		attribute.String("tool.name", name),
+		attribute.String("tool.call_id", id),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	slog.Debug("calling tool", "tool", name, "id", id, "input", string(input))
+	started := time.Now()
+	result, err := tools.Call(ctx, name, input)
+	duration := time.Since(started)
+	metrics.ToolCall(name, duration, err)
+	if err != nil {
+		return result, err
+	}
+	slog.Debug("call result", "tool", name, "id", id, "result", result.String(), "input", string(input), "duration", duration)
+	return result, nil
 }
 
 func (m *Brain) Chat(ctx context.Context, thread string, messages ...types.Message) (Response, error) {
@@ -109,37 +349,50 @@ func (m *Brain) Chat(ctx context.Context, thread string, messages ...types.Messa
 		return res, fmt.Errorf("append to thread %q: %w", thread, err)
 	}
 
-	rawHistory, err := m.db.Message.Query().Where(message.Thread(thread)).Order(message.ByID(sql.OrderDesc())).Limit(m.depth).All(ctx)
+	qctx, span := tracer.Start(ctx, "db history", trace.WithAttributes(attribute.String("thread", thread), attribute.Int("depth", m.depth)))
+	rawHistory, err := m.history(qctx, thread)
+	tracing.End(span, err)
 	if err != nil {
 		return res, fmt.Errorf("query history: %w", err)
 	}
 
-	slices.Reverse(rawHistory) // from oldest to newest
-
 	// history must start from user role
 	for i, msg := range rawHistory {
-		if msg.Role == types.RoleUser {
+		if isUserMessage(msg) {
 			rawHistory = rawHistory[i:]
 			break
 		}
 	}
 
+	rawHistory = m.fitContext(rawHistory)
+
 	var history = make([]types.Message, 0, len(rawHistory))
 	for _, msg := range rawHistory {
+		part := types.Content{
+			Data: msg.Content,
+			Mime: msg.Mime,
+			Name: msg.FileName,
+		}
+		// records of the same message are stored one by one
+		if msg.Part > 0 {
+			if n := len(history); n > 0 {
+				history[n-1].Parts = append(history[n-1].Parts, part)
+			}
+			continue // beginning of message is out of window
+		}
 		history = append(history, types.Message{
 			ToolID:   msg.ToolID,
 			ToolName: msg.ToolName,
 			Role:     msg.Role,
 			User:     msg.User,
-			Content: types.Content{
-				Data: msg.Content,
-				Mime: msg.Mime,
-			},
+			Parts:    []types.Content{part},
 		})
 	}
+	history = foldNotes(history)
 	slog.Debug("running chat", "thread", thread, "raw_history", len(rawHistory), "filtered", len(history), "depth", m.depth)
 
-	exec, err := m.Run(ctx, history, thread)
+	// new messages are moderated by Append
+	exec, err := m.run(ctx, history, thread, false)
 	if err != nil {
 		return res, fmt.Errorf("run: %w", err)
 	}
@@ -152,70 +405,282 @@ func (m *Brain) Chat(ctx context.Context, thread string, messages ...types.Messa
 	return slices.Concat(res, exec, saved), nil
 }
 
-// Append messages to thread.
+// Append messages to thread. New user input is moderated before saving, so blocked content never gets to the history.
 func (m *Brain) Append(ctx context.Context, thread string, messages []types.Message) (Response, error) {
 	messages = withoutEmptyMessages(messages)
 	if len(messages) == 0 {
 		return nil, nil
 	}
 	var res Response
-	// if vision model set - replace all images with results from vision
-	if m.vision != nil {
-		v, err := m.replaceImagesByDescription(ctx, messages)
+	// audio is stored as transcript, raw audio would be rejected by every later run of the thread
+	if m.transcriber != nil {
+		replaced, err := m.replaceAudioByTranscript(ctx, messages)
+		if err != nil {
+			return nil, fmt.Errorf("replace audio by transcript: %w", err)
+		}
+		messages = replaced
+	} else if hasAudio(messages) {
+		return nil, fmt.Errorf("audio requires transcription model: %w", ErrUnsupportedContent)
+	}
+	// if main model can not see and vision model set - replace all images with results from vision
+	if m.useVision() {
+		replaced, v, err := m.replaceImagesByDescription(ctx, messages)
 		if err != nil {
 			return nil, fmt.Errorf("replace images by description: %w", err)
 		}
+		messages = replaced
 		res = v
 	}
+	if user, text := lastTurn(messages); text != "" && !m.inputChecks.Empty() {
+		if err := m.moderate(ctx, m.inputChecks, thread, user, text); err != nil {
+			return res, err
+		}
+	}
+	return res, m.save(ctx, thread, messages)
+}
+
+// save messages to thread in one transaction.
+func (m *Brain) save(ctx context.Context, thread string, messages []types.Message) (err error) {
+	ctx, span := tracer.Start(ctx, "db append", trace.WithAttributes(attribute.String("thread", thread), attribute.Int("messages", len(messages))))
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := m.db.Tx(ctx)
 	if err != nil {
-		return res, fmt.Errorf("begin transaction: %w", err)
+		return fmt.Errorf("begin transaction: %w", err)
 	}
 
-	err = tx.Message.MapCreateBulk(messages, func(create *ent.MessageCreate, i int) {
-		msg := messages[i]
-		create.SetThread(thread).SetMime(msg.Content.Mime).SetContent(msg.Content.Data).SetRole(msg.Role)
-		if msg.User != "" {
-			create.SetUser(msg.User)
+	// each part stored as separate record
+	var records []*ent.MessageCreate
+	for _, msg := range messages {
+		for i, part := range msg.Parts {
+			create := tx.Message.Create().SetThread(thread).SetMime(part.Mime).SetContent(part.Data).SetRole(msg.Role).SetPart(i)
+			if msg.User != "" {
+				create.SetUser(msg.User)
+			}
+			if msg.ToolName != "" {
+				create.SetToolName(msg.ToolName)
+			}
+			if msg.ToolID != "" {
+				create.SetToolID(msg.ToolID)
+			}
+			if part.Name != "" {
+				create.SetFileName(part.Name)
+			}
+			records = append(records, create)
 		}
-		if msg.ToolName != "" {
-			create.SetToolName(msg.ToolName)
+	}
+	err = tx.Message.CreateBulk(records...).Exec(ctx)
+
+	if err != nil {
+		return errors.Join(tx.Rollback(), err)
+	}
+
+	return tx.Commit()
+}
+
+// invoke provider and record metrics.
+func (m *Brain) invoke(ctx context.Context, config types.Config, messages []types.Message, tools []types.ToolDefinition) (_ *types.Invoke, err error) {
+	ctx, span := tracer.Start(ctx, "invoke "+config.Model, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("gen_ai.system", m.definition.Provider.String()),
+		attribute.String("gen_ai.request.model", config.Model),
+		attribute.Int("gen_ai.request.max_tokens", config.MaxTokens),
+		attribute.Int("messages", len(messages)),
+		attribute.Int("tools", len(tools)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	started := time.Now()
+	res, err := m.provider.Invoke(ctx, config, withoutReasoning(messages), tools)
+	var inputTokens, outputTokens, cachedTokens int
+	if res != nil {
+		inputTokens, outputTokens, cachedTokens = res.InputToken, res.OutputToken, res.CachedToken
+	}
+	metrics.Invoke(m.definition.Provider.String(), config.Model, time.Since(started), inputTokens, outputTokens, cachedTokens, err)
+	span.SetAttributes(
+		attribute.Int("gen_ai.usage.input_tokens", inputTokens),
+		attribute.Int("gen_ai.usage.output_tokens", outputTokens),
+		attribute.Int("gen_ai.usage.cached_tokens", cachedTokens),
+	)
+	return res, err
+}
+
+func (m *Brain) useVision() bool {
+	return m.vision != nil && !m.capabilities.Vision
+}
+
+// checkContent rejects content which can not be processed by the model.
+func (m *Brain) checkContent(messages []types.Message) error {
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if !m.capabilities.Vision && part.Mime.IsImage() {
+				return fmt.Errorf("model %q doesn't support images: %w", m.config.Model, ErrUnsupportedContent)
+			}
+			if part.Mime.IsAudio() {
+				return fmt.Errorf("audio requires transcription model: %w", ErrUnsupportedContent)
+			}
+		}
+	}
+	return nil
+}
+
+func hasAudio(messages []types.Message) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if part.Mime.IsAudio() {
+				return true
+			}
 		}
-		if msg.ToolID != "" {
-			create.SetToolID(msg.ToolID)
+	}
+	return false
+}
+
+// fitContext drops the oldest messages which do not fit model context window (if known).
+// Tokens are estimated roughly, so the window is used with margin.
+func (m *Brain) fitContext(history []*ent.Message) []*ent.Message {
+	if m.capabilities.MaxContext <= 0 {
+		return history
+	}
+	budget := m.capabilities.MaxContext*3/4 - m.config.MaxTokens - types.Text(m.config.Prompt).EstimateTokens()
+	var used int
+	for i := len(history) - 1; i >= 0; i-- {
+		msg := history[i]
+		if msg.Role == types.RoleReasoning { // not sent to model
+			continue
+		}
+		used += types.Content{Data: msg.Content, Mime: msg.Mime}.EstimateTokens()
+		if used > budget && i < len(history)-1 { // the last message is always kept
+			history = history[i+1:]
+			slog.Debug("history truncated to fit context", "max_context", m.capabilities.MaxContext, "messages", len(history))
+			break
+		}
+	}
+	// history must start from user role
+	for i, msg := range history {
+		if isUserMessage(msg) {
+			return history[i:]
+		}
+	}
+	return history
+}
+
+// foldNotes moves notes to the end of their turn (before the next user message) and converts them to user messages.
+// Notes may be appended while the thread is running, so they must not split tool calls and results.
+func foldNotes(history []types.Message) []types.Message {
+	var (
+		out     = make([]types.Message, 0, len(history))
+		pending []types.Message
+	)
+	for _, msg := range history {
+		switch msg.Role {
+		case types.RoleNote:
+			msg.Role = types.RoleUser
+			pending = append(pending, msg)
+			continue
+		case types.RoleUser:
+			out = append(out, pending...)
+			pending = nil
 		}
-	}).Exec(ctx)
+		out = append(out, msg)
+	}
+	return append(out, pending...)
+}
 
+// isUserMessage returns true for the first record of user message.
+// history of thread (from oldest to newest) within depth. Depth is counted in messages, not in parts.
+func (m *Brain) history(ctx context.Context, thread string) ([]*ent.Message, error) {
+	heads, err := m.db.Message.Query().Where(message.Thread(thread), message.Part(0)).Order(message.ByID(sql.OrderDesc())).Limit(m.depth).IDs(ctx)
 	if err != nil {
-		return res, errors.Join(tx.Rollback(), err)
+		return nil, fmt.Errorf("query messages: %w", err)
 	}
+	if len(heads) == 0 {
+		return nil, nil
+	}
+	// parts of message are saved in one transaction after the first part
+	return m.db.Message.Query().Where(message.Thread(thread), message.IDGTE(slices.Min(heads))).Order(message.ByID()).All(ctx)
+}
 
-	return res, tx.Commit()
+func isUserMessage(record *ent.Message) bool {
+	return record.Role == types.RoleUser && record.Part == 0
+}
+
+func (m *Brain) replaceAudioByTranscript(ctx context.Context, messages []types.Message) ([]types.Message, error) {
+	messages = slices.Clone(messages) // parts are replaced in copies: caller's messages stay unchanged
+	for i, msg := range messages {
+		parts := slices.Clone(msg.Parts)
+		for j, part := range msg.Parts {
+			if !part.Mime.IsAudio() {
+				continue
+			}
+			text, err := m.transcriber.Transcribe(ctx, m.definition.Transcription.TranscribeConfig, part)
+			if err != nil {
+				return nil, fmt.Errorf("transcribe audio: %w", err)
+			}
+			parts[j] = types.Text(text)
+			slog.Debug("audio replaced by transcript", "model", m.definition.Transcription.Model, "messageIdx", i, "partIdx", j, "value", text)
+		}
+		messages[i].Parts = parts
+	}
+	return messages, nil
 }
 
-func (m *Brain) replaceImagesByDescription(ctx context.Context, messages []types.Message) (Response, error) {
+// documentsToText replaces documents by extracted text. Big documents are split to several parts.
+func documentsToText(messages []types.Message) ([]types.Message, error) {
+	var out = make([]types.Message, 0, len(messages))
+	for i, msg := range messages {
+		if !slices.ContainsFunc(msg.Parts, func(c types.Content) bool { return c.Mime.IsDocument() }) {
+			out = append(out, msg)
+			continue
+		}
+		var parts = make([]types.Content, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			if !part.Mime.IsDocument() {
+				parts = append(parts, part)
+				continue
+			}
+			chunks, err := documents.ToText(part, documents.DefaultChunkSize)
+			if err != nil {
+				return nil, fmt.Errorf("extract text from document %q: %w", part.Name, err)
+			}
+			parts = append(parts, chunks...)
+			slog.Debug("document replaced by text", "messageIdx", i, "name", part.Name, "chunks", len(chunks))
+		}
+		msg.Parts = parts
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+func (m *Brain) replaceImagesByDescription(ctx context.Context, messages []types.Message) ([]types.Message, Response, error) {
 	var ans Response
+	messages = slices.Clone(messages) // parts are replaced in copies: caller's messages stay unchanged
 	for i, msg := range messages {
-		if msg.Role == types.RoleUser && msg.Content.Mime.IsImage() {
-			result, err := m.provider.Invoke(ctx, types.Config{
+		if msg.Role != types.RoleUser {
+			continue
+		}
+		parts := slices.Clone(msg.Parts)
+		for j, part := range msg.Parts {
+			if !part.Mime.IsImage() {
+				continue
+			}
+			result, err := m.invoke(ctx, types.Config{
 				Model:     m.vision.Model,
 				MaxTokens: m.config.MaxTokens,
-			}, []types.Message{msg}, nil)
+			}, []types.Message{{Role: types.RoleUser, User: msg.User, Parts: []types.Content{part}}}, nil)
 			if err != nil {
-				return ans, fmt.Errorf("invoke vision model: %w", err)
+				return nil, ans, fmt.Errorf("invoke vision model: %w", err)
 			}
 			ans = append(ans, result)
 			for _, out := range result.Output {
 				if out.Role == types.RoleAssistant {
-					out.Role = types.RoleUser
-					messages[i] = out
-					slog.Debug("message replaced by vision model", "model", m.vision.Model, "messageIdx", i, "value", out.Content.String())
+					parts[j] = types.Text(out.Text())
+					slog.Debug("image replaced by vision model", "model", m.vision.Model, "messageIdx", i, "partIdx", j, "value", out.Text())
 					break
 				}
 			}
 		}
+		messages[i].Parts = parts
 	}
-	return ans, nil
+	return messages, ans, nil
 }
 
 type promptContext struct {
@@ -243,6 +708,15 @@ func (r Response) TotalOutputTokens() int {
 	return sum
 }
 
+// TotalCachedTokens returns sum of input tokens read from prompt cache.
+func (r Response) TotalCachedTokens() int {
+	var sum int
+	for _, msg := range r {
+		sum += msg.CachedToken
+	}
+	return sum
+}
+
 // TotalTokens returns sum of all  tokens.
 func (r Response) TotalTokens() int {
 	var sum int
@@ -262,18 +736,35 @@ func (r Response) Messages() []types.Message {
 }
 
 // Reply returns first non-tool calling model response.
-// If nothing found, empty text content returned.
+// Text parts of multi-part response are joined by new line. If nothing found, empty text content returned.
 func (r Response) Reply() types.Content {
 	for _, m := range r {
 		for _, c := range m.Output {
-			if c.Role == types.RoleAssistant {
-				return c.Content
+			if c.Role != types.RoleAssistant {
+				continue
+			}
+			if text := c.Text(); len(c.Parts) > 1 && text != "" {
+				return types.Text(text)
 			}
+			return c.Content()
 		}
 	}
 	return types.Text("")
 }
 
+// Reasoning messages of all responses.
+func (r Response) Reasoning() []types.Message {
+	var ans []types.Message
+	for _, m := range r {
+		for _, c := range m.Output {
+			if c.Role == types.RoleReasoning {
+				ans = append(ans, c)
+			}
+		}
+	}
+	return ans
+}
+
 // Called returns how many times function (tool) with specified name has been called.
 func (r Response) Called(name string) int {
 	var count int
@@ -293,9 +784,23 @@ func withoutEmptyMessages(messages []types.Message) []types.Message {
 	// filter empty messages
 	var out = make([]types.Message, 0, len(messages))
 	for _, msg := range messages {
-		if len(msg.Content.Data) != 0 {
+		msg.Parts = slices.DeleteFunc(slices.Clone(msg.Parts), func(c types.Content) bool {
+			return len(c.Data) == 0
+		})
+		if len(msg.Parts) != 0 {
 			out = append(out, msg)
 		}
 	}
 	return out
 }
+
+// withoutReasoning drops reasoning which is not needed by models.
+// Only signed reasoning (produced during the current run) is kept since providers require it to continue after tool calls.
+func withoutReasoning(messages []types.Message) []types.Message {
+	if !slices.ContainsFunc(messages, func(msg types.Message) bool { return msg.Role == types.RoleReasoning }) {
+		return messages
+	}
+	return slices.DeleteFunc(slices.Clone(messages), func(msg types.Message) bool {
+		return msg.Role == types.RoleReasoning && msg.Signature == ""
+	})
+}
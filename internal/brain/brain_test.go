@@ -31,10 +31,10 @@ func userMessage(name string, content string) types.Message {
 	return types.Message{
 		Role: types.RoleUser,
 		User: name,
-		Content: types.Content{
+		Parts: []types.Content{{
 			Mime: types.MIMEText,
 			Data: []byte(content),
-		},
+		}},
 	}
 }
 
@@ -132,7 +132,7 @@ func testBrain(t *testing.T, definition brain.Definition) {
 	search:
 		for _, msg := range out {
 			for _, content := range msg.Output {
-				if strings.Contains(content.Content.String(), "scatter") {
+				if strings.Contains(content.Text(), "scatter") {
 					found = true
 					break search
 				}
@@ -156,7 +156,7 @@ func testBrain(t *testing.T, definition brain.Definition) {
 		for _, r := range out {
 			for _, o := range r.Output {
 				if o.Role == types.RoleAssistant {
-					found = strings.Contains(o.Content.String(), "135")
+					found = strings.Contains(o.Text(), "135")
 					if found {
 						break search
 					}
@@ -179,10 +179,10 @@ func testBrain(t *testing.T, definition brain.Definition) {
 			{
 				Role: types.RoleUser,
 				User: "reddec",
-				Content: types.Content{
+				Parts: []types.Content{{
 					Data: picture,
 					Mime: types.MIMEJpg,
-				},
+				}},
 			},
 			userMessage("reddec", "Describe image"),
 		}, "")
@@ -194,6 +194,29 @@ func testBrain(t *testing.T, definition brain.Definition) {
 
 		t.Logf("%s", reply)
 		require.Contains(t, strings.ToLower(string(reply.Data)), "eiffel")
+
+		// image and caption in one message
+		out, err = b.Run(ctx, []types.Message{
+			{
+				Role: types.RoleUser,
+				User: "reddec",
+				Parts: []types.Content{
+					{
+						Data: picture,
+						Mime: types.MIMEJpg,
+					},
+					types.Text("Describe image"),
+				},
+			},
+		}, "")
+		require.NoError(t, err)
+
+		dumpOutput(t, out)
+
+		reply = out.Reply()
+
+		t.Logf("%s", reply)
+		require.Contains(t, strings.ToLower(string(reply.Data)), "eiffel")
 	})
 
 	t.Run("petstore", func(t *testing.T) {
@@ -229,7 +252,7 @@ func testBrain(t *testing.T, definition brain.Definition) {
 		for _, r := range out {
 			for _, o := range r.Output {
 				if o.Role == types.RoleAssistant {
-					found = strings.Contains(o.Content.String(), name)
+					found = strings.Contains(o.Text(), name)
 					if found {
 						break search
 					}
@@ -244,7 +267,9 @@ func testBrain(t *testing.T, definition brain.Definition) {
 func dumpOutput(t *testing.T, out brain.Response) {
 	for _, msg := range out {
 		for _, content := range msg.Output {
-			t.Logf("%s: %s: %s", content.Role, content.Content.Mime, content.Content.String())
+			for _, part := range content.Parts {
+				t.Logf("%s: %s: %s", content.Role, part.Mime, part.String())
+			}
 		}
 	}
 }
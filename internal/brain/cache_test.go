@@ -0,0 +1,118 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/brain"
+	"github.com/pikocloud/pikobrain/internal/providers/bedrock"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+// fakeBedrock is Converse API stub which records requests and reports cache usage.
+type fakeBedrock struct {
+	lock     sync.Mutex
+	requests []map[string]any
+}
+
+func (f *fakeBedrock) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req map[string]any
+	if err := json.Unmarshal(data, &req); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.lock.Lock()
+	f.requests = append(f.requests, req)
+	f.lock.Unlock()
+
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write([]byte(`{
+		"output": {"message": {"role": "assistant", "content": [{"text": "ok"}]}},
+		"stopReason": "end_turn",
+		"usage": {"inputTokens": 10, "outputTokens": 2, "totalTokens": 162, "cacheReadInputTokens": 100, "cacheWriteInputTokens": 50},
+		"metrics": {"latencyMs": 1}
+	}`))
+}
+
+// cachePoints returns number of cache points in system prompt and tools of request.
+func (f *fakeBedrock) cachePoints(idx int) (system int, tools int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	req := f.requests[idx]
+	count := func(list any) int {
+		var n int
+		items, _ := list.([]any)
+		for _, item := range items {
+			if block, ok := item.(map[string]any); ok && block["cachePoint"] != nil {
+				n++
+			}
+		}
+		return n
+	}
+	toolConfig, _ := req["toolConfig"].(map[string]any)
+	return count(req["system"]), count(toolConfig["tools"])
+}
+
+func TestBrain_cache(t *testing.T) {
+	// no real AWS configuration
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	fake := &fakeBedrock{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	ctx := context.Background()
+	db := newTestDB(t)
+	var tools types.DynamicToolbox
+	tools.Add(types.MustTool("get_time", "Current time", func(ctx context.Context, _ struct{}) (types.Content, error) {
+		return types.Text("12:00"), nil
+	}))
+	require.NoError(t, tools.Update(ctx, true))
+
+	newBrain := func(model string) *brain.Brain {
+		definition := brain.Default()
+		definition.Provider = brain.ProviderBedrock
+		definition.Model = model
+		definition.Cache = true
+		definition.Bedrock = &bedrock.Config{Endpoint: srv.URL}
+		b, err := brain.New(ctx, db, &tools, definition)
+		require.NoError(t, err)
+		return b
+	}
+
+	out, err := newBrain("anthropic.claude-3-7-sonnet-20250219-v1:0").Run(ctx, []types.Message{userMessage("alice", "hello")}, "")
+	require.NoError(t, err)
+	system, toolPoints := fake.cachePoints(0)
+	assert.Equal(t, 1, system)
+	assert.Equal(t, 1, toolPoints)
+	// input tokens include cache reads and writes
+	assert.Equal(t, 160, out.TotalInputTokens())
+	assert.Equal(t, 100, out.TotalCachedTokens())
+	assert.Equal(t, 2, out.TotalOutputTokens())
+
+	// model without prompt caching
+	_, err = newBrain("meta.llama3-1-70b-instruct-v1:0").Run(ctx, []types.Message{userMessage("alice", "hello")}, "")
+	require.NoError(t, err)
+	system, toolPoints = fake.cachePoints(1)
+	assert.Zero(t, system)
+	assert.Zero(t, toolPoints)
+}
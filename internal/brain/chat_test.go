@@ -0,0 +1,379 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/brain"
+	"github.com/pikocloud/pikobrain/internal/ent"
+	"github.com/pikocloud/pikobrain/internal/ent/message"
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
+	"github.com/pikocloud/pikobrain/internal/moderation"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/threads"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+// fakeOpenAI is OpenAI-compatible server which records requests and replies by script (or "ok" by default).
+type fakeOpenAI struct {
+	lock     sync.Mutex
+	requests []openai.ChatCompletionRequest
+	replies  []openai.ChatCompletionMessage
+}
+
+func (f *fakeOpenAI) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var req openai.ChatCompletionRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.lock.Lock()
+	f.requests = append(f.requests, req)
+	reply := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "ok"}
+	if len(f.replies) > 0 {
+		reply = f.replies[0]
+		f.replies = f.replies[1:]
+	}
+	f.lock.Unlock()
+
+	finish := openai.FinishReasonStop
+	if len(reply.ToolCalls) > 0 {
+		finish = openai.FinishReasonToolCalls
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(openai.ChatCompletionResponse{
+		ID:      "test",
+		Object:  "chat.completion",
+		Model:   req.Model,
+		Choices: []openai.ChatCompletionChoice{{Message: reply, FinishReason: finish}},
+		Usage:   openai.Usage{PromptTokens: 10, CompletionTokens: 1, TotalTokens: 11},
+	})
+}
+
+func (f *fakeOpenAI) Requests() []openai.ChatCompletionRequest {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.requests
+}
+
+// textOf message sent to the model.
+func textOf(msg openai.ChatCompletionMessage) string {
+	text := msg.Content
+	for _, part := range msg.MultiContent {
+		text += part.Text
+	}
+	return text
+}
+
+func newTestDB(t *testing.T) *ent.Client {
+	t.Helper()
+	db, err := ent.New(context.Background(), ent.Config{
+		URL:          "sqlite://:memory:?cache=shared&_fk=1&_pragma=foreign_keys(1)",
+		MaxConn:      3,
+		IdleConn:     3,
+		IdleTimeout:  time.Minute,
+		ConnLifeTime: time.Hour,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// newFakeBrain creates brain backed by fake OpenAI server. Definition can be adjusted by fn.
+func newFakeBrain(t *testing.T, db *ent.Client, toolbox types.Toolbox, fn func(definition *brain.Definition)) (*brain.Brain, *fakeOpenAI) {
+	t.Helper()
+	fake := &fakeOpenAI{}
+	mux := http.NewServeMux()
+	mux.Handle("POST /chat/completions", fake)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	token := "test"
+	definition := brain.Default()
+	definition.URL = srv.URL
+	definition.Secret = utils.Value[string]{Value: &token}
+	if fn != nil {
+		fn(&definition)
+	}
+	if toolbox == nil {
+		toolbox = &types.DynamicToolbox{}
+	}
+	b, err := brain.New(context.Background(), db, toolbox, definition)
+	require.NoError(t, err)
+	return b, fake
+}
+
+func TestBrain_Chat_blockedInput(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	b, fake := newFakeBrain(t, db, nil, func(definition *brain.Definition) {
+		definition.Moderation = &moderation.Config{
+			Input: []moderation.CheckerConfig{{Type: moderation.KindKeywords, Keywords: []string{"forbidden"}}},
+		}
+	})
+	const thread = "blocked-input"
+
+	_, err := b.Chat(ctx, thread, userMessage("alice", "tell me forbidden things"))
+	require.ErrorIs(t, err, moderation.ErrBlocked)
+	assert.Empty(t, fake.Requests())
+
+	out, err := b.Chat(ctx, thread, userMessage("alice", "hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(out.Reply().Data))
+
+	// model sees only clean message
+	requests := fake.Requests()
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0].Messages, 2) // prompt and user message
+	assert.Equal(t, "hello", textOf(requests[0].Messages[1]))
+
+	// blocked message is not saved to the thread, but recorded as moderation event
+	history, err := db.Message.Query().Where(message.Thread(thread)).Order(message.ByID()).All(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, types.RoleUser, history[0].Role)
+	assert.Equal(t, "hello", string(history[0].Content))
+	assert.Equal(t, types.RoleAssistant, history[1].Role)
+
+	events, err := db.Moderation.Query().Where(entmoderation.Thread(thread)).All(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, moderation.StageInput, events[0].Stage)
+	assert.Equal(t, moderation.KindKeywords, events[0].Checker)
+	assert.Equal(t, "alice", events[0].User)
+	assert.Equal(t, "tell me forbidden things", string(events[0].Content))
+}
+
+func TestBrain_Run_capabilities(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	var tools types.DynamicToolbox
+	tools.Add(types.MustTool("get_time", "Current time", func(ctx context.Context, _ struct{}) (types.Content, error) {
+		return types.Text("12:00"), nil
+	}))
+	require.NoError(t, tools.Update(ctx, true))
+
+	cases := []struct {
+		model string
+		tools bool
+	}{
+		{model: "gpt-4o-mini", tools: true},
+		{model: "gpt-4.1", tools: true},
+		{model: "o1-mini", tools: false},
+		{model: "my-local-model", tools: true}, // unknown models are not restricted
+	}
+	for _, c := range cases {
+		t.Run(c.model, func(t *testing.T) {
+			b, fake := newFakeBrain(t, db, &tools, func(definition *brain.Definition) {
+				definition.Model = c.model
+			})
+			assert.Equal(t, c.tools, b.Capabilities().Tools)
+
+			_, err := b.Run(ctx, []types.Message{userMessage("alice", "what time is it?")}, "")
+			require.NoError(t, err)
+
+			requests := fake.Requests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, c.model, requests[0].Model)
+			if c.tools {
+				require.Len(t, requests[0].Tools, 1)
+				assert.Equal(t, "get_time", requests[0].Tools[0].Function.Name)
+			} else {
+				assert.Empty(t, requests[0].Tools)
+			}
+		})
+	}
+}
+
+func TestBrain_Chat_noteToRunningThread(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	const thread = "running-note"
+
+	internal, err := threads.New(db, threads.Config{Own: true, Tools: []string{threads.ToolAppendNote}})
+	require.NoError(t, err)
+	var tools types.DynamicToolbox
+	tools.Add(internal...)
+	require.NoError(t, tools.Update(ctx, true))
+
+	b, fake := newFakeBrain(t, db, &tools, nil)
+	fake.replies = []openai.ChatCompletionMessage{{
+		Role: openai.ChatMessageRoleAssistant,
+		ToolCalls: []openai.ToolCall{{
+			ID:       "call-1",
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: threads.ToolAppendNote, Arguments: `{"thread":"` + thread + `","text":"likes tea"}`},
+		}},
+	}}
+
+	_, err = b.Chat(ctx, thread, userMessage("alice", "remember that I like tea"))
+	require.NoError(t, err)
+
+	// note is saved while thread is running: between user message and model output
+	records, err := db.Message.Query().Where(message.Thread(thread)).Order(message.ByID()).All(ctx)
+	require.NoError(t, err)
+	var roles []types.Role
+	for _, record := range records {
+		roles = append(roles, record.Role)
+	}
+	assert.Equal(t, []types.Role{types.RoleUser, types.RoleNote, types.RoleToolCall, types.RoleAssistant}, roles)
+
+	_, err = b.Chat(ctx, thread, userMessage("alice", "what do I like?"))
+	require.NoError(t, err)
+
+	// model output of the turn is kept together, note is moved to the end of the turn
+	requests := fake.Requests()
+	require.Len(t, requests, 3)
+	history := requests[2].Messages
+	require.Len(t, history, 6)
+	assert.Equal(t, openai.ChatMessageRoleSystem, history[0].Role)
+	assert.Equal(t, "remember that I like tea", textOf(history[1]))
+	assert.Equal(t, openai.ChatMessageRoleAssistant, history[2].Role)
+	require.Len(t, history[2].ToolCalls, 1)
+	assert.Equal(t, "ok", textOf(history[3]))
+	assert.Equal(t, openai.ChatMessageRoleUser, history[4].Role)
+	assert.Equal(t, "likes tea", textOf(history[4]))
+	assert.Equal(t, threads.DefaultNoteUser, history[4].Name)
+	assert.Equal(t, "what do I like?", textOf(history[5]))
+}
+
+func TestBrain_Append_audioWithoutTranscription(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	b, _ := newFakeBrain(t, db, nil, nil)
+	const thread = "raw-audio"
+
+	_, err := b.Append(ctx, thread, []types.Message{{Role: types.RoleUser, User: "alice", Parts: []types.Content{{Mime: types.MIMEOgg, Data: []byte("OggS")}}}})
+	require.ErrorIs(t, err, brain.ErrUnsupportedContent)
+
+	count, err := db.Message.Query().Where(message.Thread(thread)).Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestBrain_Chat_depthInMessages(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	b, fake := newFakeBrain(t, db, nil, func(definition *brain.Definition) {
+		definition.Depth = 3
+	})
+	const thread = "depth"
+
+	_, err := b.Append(ctx, thread, []types.Message{
+		userMessage("alice", "first"),
+		{Role: types.RoleAssistant, Parts: []types.Content{types.Text("noted")}},
+	})
+	require.NoError(t, err)
+
+	_, err = b.Chat(ctx, thread, types.Message{Role: types.RoleUser, User: "alice", Parts: []types.Content{types.Text("a"), types.Text("b"), types.Text("c")}})
+	require.NoError(t, err)
+
+	// multi-part message takes one slot of depth and is never cut
+	requests := fake.Requests()
+	require.Len(t, requests, 1)
+	history := requests[0].Messages
+	require.Len(t, history, 4)
+	assert.Equal(t, "first", textOf(history[1]))
+	assert.Equal(t, "noted", textOf(history[2]))
+	assert.Equal(t, "abc", textOf(history[3]))
+}
+
+func TestResponse_Reply(t *testing.T) {
+	res := brain.Response{{Output: []types.Message{
+		{Role: types.RoleToolCall, ToolName: "get_time", Parts: []types.Content{{Mime: types.MIMEJson, Data: []byte(`{}`)}}},
+		{Role: types.RoleAssistant, Parts: []types.Content{types.Text("first"), {Mime: types.MIMEPng, Data: []byte("png")}, types.Text("second")}},
+	}}}
+	assert.Equal(t, types.Text("first\nsecond"), res.Reply())
+
+	single := brain.Response{{Output: []types.Message{{Role: types.RoleAssistant, Parts: []types.Content{{Mime: types.MIMEJson, Data: []byte(`{}`)}}}}}}
+	assert.Equal(t, types.MIMEJson, single.Reply().Mime)
+
+	assert.Equal(t, types.Text(""), brain.Response{}.Reply())
+}
+
+func TestBrain_Run_attachmentLabels(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	var seen []types.Content
+	var tools types.DynamicToolbox
+	tools.Add(types.MustTool("upload", "Upload file", func(ctx context.Context, _ struct{}) (types.Content, error) {
+		seen = types.AttachmentsFrom(ctx)
+		return types.Text("uploaded"), nil
+	}))
+	require.NoError(t, tools.Update(ctx, true))
+
+	b, fake := newFakeBrain(t, db, &tools, nil)
+	fake.replies = []openai.ChatCompletionMessage{{
+		Role: openai.ChatMessageRoleAssistant,
+		ToolCalls: []openai.ToolCall{{
+			ID:       "call-1",
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: "upload", Arguments: `{}`},
+		}},
+	}}
+
+	messages := []types.Message{
+		{Role: types.RoleUser, User: "alice", Parts: []types.Content{
+			types.Text("upload them"),
+			{Mime: types.MIMEPng, Data: []byte("png"), Name: "cat.png"},
+			{Mime: types.MIMEJpg, Data: []byte("jpg")},
+		}},
+		{Role: types.RoleAssistant, Parts: []types.Content{types.Text("which one?")}},
+		{Role: types.RoleUser, User: "alice", Parts: []types.Content{{Mime: types.MIMEPng, Data: []byte("png2")}, types.Text("and this")}},
+	}
+	_, err := b.Run(ctx, messages, "")
+	require.NoError(t, err)
+
+	// model sees the same numbering as tools use
+	requests := fake.Requests()
+	require.NotEmpty(t, requests)
+	history := requests[0].Messages
+	require.Len(t, history, 4)
+	assert.Equal(t, "upload them[attachment #1: cat.png, image/png][attachment #2: image/jpg]", textOf(history[1]))
+	assert.Equal(t, "[attachment #3: image/png]and this", textOf(history[3]))
+
+	require.Len(t, seen, 3)
+	assert.Equal(t, "cat.png", seen[0].Name)
+	assert.Equal(t, "jpg", string(seen[1].Data))
+	assert.Equal(t, "png2", string(seen[2].Data))
+
+	// caller's messages are not changed
+	assert.Len(t, messages[0].Parts, 3)
+	assert.Len(t, messages[2].Parts, 2)
+}
+
+func TestBrain_Run_keepsCallerMessages(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	b, fake := newFakeBrain(t, db, nil, func(definition *brain.Definition) {
+		definition.Model = "gpt-3.5-turbo"
+		definition.Vision = &brain.Vision{Model: "gpt-4o"}
+	})
+	fake.replies = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleAssistant, Content: "a cat"}}
+	image := types.Content{Mime: types.MIMEPng, Data: []byte("PNG")}
+	messages := []types.Message{{Role: types.RoleUser, User: "alice", Parts: []types.Content{types.Text("what is it?"), image}}}
+
+	_, err := b.Run(ctx, messages, "")
+	require.NoError(t, err)
+
+	// model sees description, caller still has the image
+	requests := fake.Requests()
+	require.Len(t, requests, 2)
+	assert.Contains(t, textOf(requests[1].Messages[len(requests[1].Messages)-1]), "a cat")
+	assert.Equal(t, image, messages[0].Parts[1])
+
+	_, err = b.Append(ctx, "vision", messages)
+	require.NoError(t, err)
+	assert.Equal(t, image, messages[0].Parts[1])
+}
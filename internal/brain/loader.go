@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"text/template"
 
@@ -11,10 +13,12 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/pikocloud/pikobrain/internal/ent"
+	"github.com/pikocloud/pikobrain/internal/moderation"
 	"github.com/pikocloud/pikobrain/internal/providers/bedrock"
 	"github.com/pikocloud/pikobrain/internal/providers/google"
 	"github.com/pikocloud/pikobrain/internal/providers/ollama"
 	"github.com/pikocloud/pikobrain/internal/providers/openai"
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
 	"github.com/pikocloud/pikobrain/internal/providers/types"
 	"github.com/pikocloud/pikobrain/internal/utils"
 )
@@ -22,26 +26,47 @@ import (
 //go:generate go run github.com/abice/go-enum@v0.6.0  --marshal
 
 // Provider name
-// ENUM(openai,bedrock,ollama,google)
+// ENUM(openai,bedrock,ollama,google,azure)
 type Provider string
 
 var (
-	ErrProviderNotFound = errors.New("provider not found")
+	ErrProviderNotFound   = errors.New("provider not found")
+	ErrUnsupportedContent = errors.New("unsupported content")
+	ErrEmbeddingDisabled  = errors.New("embeddings are not configured")
 )
 
 type Vision struct {
 	Model string `json:"model" yaml:"model"`
 }
 
+type Embedding struct {
+	Model string `json:"model" yaml:"model"`
+}
+
+type Transcription struct {
+	types.TranscribeConfig `yaml:",inline"`
+	URL                    string               `json:"url" yaml:"url"`                 // OpenAI-compatible API URL. If not set, main provider is used
+	Secret                 *utils.Value[string] `json:"secret" yaml:"secret,omitempty"` // API secret. If not set, main provider secret is used
+}
+
 type Definition struct {
 	types.Config  `yaml:",inline"`    // model configuration
-	Parallel      bool                `yaml:"parallel"`                       // allow parallel execution for calls
-	Vision        *Vision             `yaml:"vision,omitempty" json:"vision"` // separate model for vision
+	Parallel      bool                `yaml:"parallel"`                                     // allow parallel execution for calls
+	Vision        *Vision             `yaml:"vision,omitempty" json:"vision"`               // separate model for vision
+	Embedding     *Embedding          `yaml:"embedding,omitempty" json:"embedding"`         // model for embeddings
+	Transcription *Transcription      `yaml:"transcription,omitempty" json:"transcription"` // speech-to-text model for audio
 	MaxIterations int                 `json:"max_iterations" yaml:"maxIterations"`
-	Provider      Provider            `json:"provider" yaml:"provider"` // provider name (openai, bedrock)
-	URL           string              `json:"url" yaml:"url"`           // provider URL
-	Secret        utils.Value[string] `json:"secret" yaml:"secret"`     // provider secret
-	Depth         int                 `yaml:"depth" json:"depth"`       // history depth
+	Provider      Provider            `json:"provider" yaml:"provider"`                   // provider name (openai, bedrock)
+	URL           string              `json:"url" yaml:"url"`                             // provider URL
+	Secret        utils.Value[string] `json:"secret" yaml:"secret"`                       // provider secret
+	Depth         int                 `yaml:"depth" json:"depth"`                         // history depth
+	Azure         *openai.AzureConfig `yaml:"azure,omitempty" json:"azure"`               // Azure OpenAI settings (for azure provider)
+	HTTP          utils.HTTP          `yaml:"http,omitempty" json:"http"`                 // HTTP client settings for providers
+	Ollama        *ollama.Config      `yaml:"ollama,omitempty" json:"ollama"`             // Ollama runtime options (for ollama provider)
+	Bedrock       *bedrock.Config     `yaml:"bedrock,omitempty" json:"bedrock"`           // AWS settings (for bedrock provider)
+	Capabilities  *types.Capabilities `yaml:"capabilities,omitempty" json:"capabilities"` // override detected model capabilities
+	RateLimit     *ratelimit.Config   `yaml:"rateLimit,omitempty" json:"rate_limit"`      // client-side limits for provider
+	Moderation    *moderation.Config  `yaml:"moderation,omitempty" json:"moderation"`     // input and output guardrails
 }
 
 func Default() Definition {
@@ -70,23 +95,48 @@ func New(ctx context.Context, db *ent.Client, toolbox types.Toolbox, definition
 		return nil, fmt.Errorf("get secret: %w", err)
 	}
 
+	var httpClient *http.Client
+	if !definition.HTTP.IsZero() {
+		httpClient, err = definition.HTTP.Client()
+		if err != nil {
+			return nil, fmt.Errorf("create HTTP client: %w", err)
+		}
+	}
+
 	switch definition.Provider {
 	case ProviderOpenai:
-		provider = openai.New(definition.URL, secret)
+		provider = openai.New(definition.URL, secret, httpClient)
+	case ProviderAzure:
+		var azure openai.AzureConfig
+		if definition.Azure != nil {
+			azure = *definition.Azure
+		}
+		if azure.Auth != "" && !azure.Auth.IsValid() {
+			return nil, fmt.Errorf("azure auth: %w: %q", openai.ErrInvalidAzureAuth, azure.Auth)
+		}
+		provider = openai.NewAzure(definition.URL, secret, azure, httpClient)
 	case ProviderBedrock:
-		p, err := bedrock.New(ctx)
+		var cfg bedrock.Config
+		if definition.Bedrock != nil {
+			cfg = *definition.Bedrock
+		}
+		p, err := bedrock.New(ctx, httpClient, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("new bedrock provider: %w", err)
 		}
 		provider = p
 	case ProviderOllama:
-		p, err := ollama.New(definition.URL)
+		var cfg ollama.Config
+		if definition.Ollama != nil {
+			cfg = *definition.Ollama
+		}
+		p, err := ollama.New(definition.URL, httpClient, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("new ollama provider: %w", err)
 		}
 		provider = p
 	case ProviderGoogle:
-		p, err := google.New(ctx, secret)
+		p, err := google.New(ctx, secret, httpClient)
 		if err != nil {
 			return nil, fmt.Errorf("new google provider: %w", err)
 		}
@@ -95,6 +145,85 @@ func New(ctx context.Context, db *ent.Client, toolbox types.Toolbox, definition
 		return nil, fmt.Errorf("provider %q: %w", definition.Provider, ErrProviderNotFound)
 	}
 
+	capabilities := types.CapabilitiesOf(provider, definition.Model)
+	if definition.Capabilities != nil {
+		capabilities = *definition.Capabilities
+	}
+	if definition.ForceJSON && !capabilities.JSON {
+		slog.Warn("model doesn't support forced JSON output, option will be ignored", "model", definition.Model, "provider", definition.Provider)
+	}
+	if definition.Cache && !capabilities.Cache {
+		slog.Warn("model doesn't support explicit prompt caching, option will be ignored", "model", definition.Model, "provider", definition.Provider)
+		definition.Cache = false
+	}
+	if (definition.ReasoningEffort != "" || definition.ReasoningBudget > 0) && !capabilities.Reasoning {
+		slog.Warn("model is not known as reasoning model, reasoning options may be rejected by provider", "model", definition.Model, "provider", definition.Provider)
+	}
+	if _, ok := provider.(types.Embedder); definition.Embedding != nil && !ok {
+		return nil, fmt.Errorf("provider %q doesn't support embeddings", definition.Provider)
+	}
+	if _, ok := provider.(types.Transcriber); definition.Transcription != nil && definition.Transcription.URL == "" && !ok {
+		return nil, fmt.Errorf("provider %q doesn't support transcription, set transcription URL", definition.Provider)
+	}
+
+	if definition.Vision == nil && !capabilities.Vision {
+		slog.Warn("model doesn't support images and vision model not set, images will be rejected", "model", definition.Model, "provider", definition.Provider)
+	}
+
+	providerModerator, _ := provider.(types.Moderator)
+
+	// wrap after detecting optional interfaces of the provider
+	var limiter *ratelimit.Provider
+	if definition.RateLimit != nil {
+		limiter = ratelimit.New(provider, *definition.RateLimit)
+		provider = limiter
+	}
+
+	// resolve after wrapping, so transcription of the main provider shares its limits
+	var transcriber types.Transcriber
+	if tr := definition.Transcription; tr != nil {
+		if tr.URL != "" {
+			token := secret
+			if tr.Secret != nil {
+				token, err = tr.Secret.Get()
+				if err != nil {
+					return nil, fmt.Errorf("get transcription secret: %w", err)
+				}
+			}
+			transcriber = openai.New(tr.URL, token, httpClient)
+		} else {
+			transcriber = provider.(types.Transcriber)
+		}
+	}
+
+	var inputChecks, outputChecks *moderation.Checkers
+	if mod := definition.Moderation; mod != nil {
+		moderator := func(config moderation.CheckerConfig) (types.Moderator, error) {
+			if config.URL != "" {
+				token := secret
+				if config.Secret != nil {
+					token, err = config.Secret.Get()
+					if err != nil {
+						return nil, fmt.Errorf("get moderation secret: %w", err)
+					}
+				}
+				return openai.New(config.URL, token, httpClient), nil
+			}
+			if providerModerator == nil {
+				return nil, fmt.Errorf("provider %q doesn't support moderation, set moderation URL", definition.Provider)
+			}
+			return providerModerator, nil
+		}
+		inputChecks, err = moderation.NewCheckers(moderation.StageInput, mod.Input, provider, moderator)
+		if err != nil {
+			return nil, fmt.Errorf("create input moderation: %w", err)
+		}
+		outputChecks, err = moderation.NewCheckers(moderation.StageOutput, mod.Output, provider, moderator)
+		if err != nil {
+			return nil, fmt.Errorf("create output moderation: %w", err)
+		}
+	}
+
 	t, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(definition.Prompt)
 	if err != nil {
 		return nil, fmt.Errorf("parse prompt: %w", err)
@@ -106,11 +235,18 @@ func New(ctx context.Context, db *ent.Client, toolbox types.Toolbox, definition
 		parallel:   definition.Parallel,
 		iterations: definition.MaxIterations,
 		vision:     definition.Vision,
+		embedding:  definition.Embedding,
 		config:     definition.Config,
 		provider:   provider,
 		prompt:     t,
 		toolbox:    toolbox,
 		definition: definition,
+
+		capabilities: capabilities,
+		transcriber:  transcriber,
+		limiter:      limiter,
+		inputChecks:  inputChecks,
+		outputChecks: outputChecks,
 	}, nil
 }
 
@@ -20,6 +20,8 @@ const (
 	ProviderOllama Provider = "ollama"
 	// ProviderGoogle is a Provider of type google.
 	ProviderGoogle Provider = "google"
+	// ProviderAzure is a Provider of type azure.
+	ProviderAzure Provider = "azure"
 )
 
 var ErrInvalidProvider = errors.New("not a valid Provider")
@@ -41,6 +43,7 @@ var _ProviderValue = map[string]Provider{
 	"bedrock": ProviderBedrock,
 	"ollama":  ProviderOllama,
 	"google":  ProviderGoogle,
+	"azure":   ProviderAzure,
 }
 
 // ParseProvider attempts to convert a string to a Provider.
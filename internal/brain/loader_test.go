@@ -0,0 +1,31 @@
+package brain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/brain"
+	"github.com/pikocloud/pikobrain/internal/providers/openai"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+func TestNew_azureAuth(t *testing.T) {
+	db := newTestDB(t)
+	token := "test"
+	newAzure := func(auth openai.AzureAuth) error {
+		definition := brain.Default()
+		definition.Provider = brain.ProviderAzure
+		definition.URL = "https://example.openai.azure.com"
+		definition.Secret = utils.Value[string]{Value: &token}
+		definition.Azure = &openai.AzureConfig{Auth: auth}
+		_, err := brain.New(context.Background(), db, &types.DynamicToolbox{}, definition)
+		return err
+	}
+
+	require.NoError(t, newAzure(""))
+	require.NoError(t, newAzure(openai.AzureAuthEntra))
+	require.ErrorIs(t, newAzure("token"), openai.ErrInvalidAzureAuth)
+}
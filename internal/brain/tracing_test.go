@@ -0,0 +1,110 @@
+package brain_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tracing"
+)
+
+// exportedSpan is subset of span exported by stdout exporter.
+type exportedSpan struct {
+	Name        string
+	SpanContext struct {
+		TraceID string
+		SpanID  string
+	}
+	Parent struct {
+		SpanID string
+	}
+	Attributes []struct {
+		Key   string
+		Value struct {
+			Value any
+		}
+	}
+	Status struct {
+		Code string
+	}
+}
+
+func (span *exportedSpan) Attribute(key string) any {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value.Value
+		}
+	}
+	return nil
+}
+
+func TestBrain_Run_tracing(t *testing.T) {
+	ctx := context.Background()
+	var output bytes.Buffer
+	shutdown, err := tracing.Setup(ctx, tracing.Config{Exporter: tracing.ExporterStdout, Service: "test", Output: &output})
+	require.NoError(t, err)
+
+	var tools types.DynamicToolbox
+	tools.Add(types.MustTool("lookup", "Lookup something", func(ctx context.Context, _ struct{}) (types.Content, error) {
+		return types.Text("found"), nil
+	}))
+	tools.Add(types.MustTool("broken", "Always fails", func(ctx context.Context, _ struct{}) (types.Content, error) {
+		return types.Content{}, errors.New("tool is broken")
+	}))
+	require.NoError(t, tools.Update(ctx, true))
+
+	b, fake := newFakeBrain(t, newTestDB(t), &tools, nil)
+	fake.replies = []openai.ChatCompletionMessage{{
+		Role: openai.ChatMessageRoleAssistant,
+		ToolCalls: []openai.ToolCall{
+			{ID: "call-1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "lookup", Arguments: `{}`}},
+			{ID: "call-2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "broken", Arguments: `{}`}},
+		},
+	}}
+	_, err = b.Run(ctx, []types.Message{userMessage("alice", "find it")}, "")
+	require.ErrorContains(t, err, "tool is broken")
+
+	// flush batched spans
+	require.NoError(t, shutdown(ctx))
+
+	var spans = make(map[string]*exportedSpan)
+	decoder := json.NewDecoder(&output)
+	for {
+		var span exportedSpan
+		err := decoder.Decode(&span)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		spans[span.Name] = &span
+	}
+
+	run := spans["run"]
+	require.NotNil(t, run, "run span")
+	invoke := spans["invoke "+b.Definition().Model]
+	require.NotNil(t, invoke, "provider span")
+	lookup := spans["tool lookup"]
+	require.NotNil(t, lookup, "tool span")
+	broken := spans["tool broken"]
+	require.NotNil(t, broken, "failed tool span")
+
+	iteration := spans["iteration"]
+	require.NotNil(t, iteration, "iteration span")
+	for _, span := range []*exportedSpan{invoke, lookup, broken} {
+		assert.Equal(t, run.SpanContext.TraceID, span.SpanContext.TraceID, span.Name)
+		assert.Equal(t, iteration.SpanContext.SpanID, span.Parent.SpanID, span.Name)
+	}
+	assert.Equal(t, "lookup", lookup.Attribute("tool.name"))
+	assert.Equal(t, "call-1", lookup.Attribute("tool.call_id"))
+	assert.Equal(t, "Unset", lookup.Status.Code)
+	assert.Equal(t, "call-2", broken.Attribute("tool.call_id"))
+	assert.Equal(t, "Error", broken.Status.Code)
+}
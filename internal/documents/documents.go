@@ -0,0 +1,176 @@
+// Package documents extracts plain text from documents (PDF, HTML, Markdown, CSV) for models
+// which do not support them natively.
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/net/html"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+// DefaultChunkSize is maximum number of characters in one chunk.
+const DefaultChunkSize = 16 * 1024
+
+var ErrUnsupportedDocument = errors.New("unsupported document")
+
+// Extract plain text from document.
+func Extract(content types.Content) (string, error) {
+	switch content.Mime {
+	case types.MIMEPdf:
+		return extractPDF(content.Data)
+	case types.MIMEHtml:
+		return extractHTML(content.Data)
+	case types.MIMEMarkdown, types.MIMECsv, types.MIMEText:
+		return string(content.Data), nil
+	default:
+		return "", fmt.Errorf("%s: %w", content.Mime, ErrUnsupportedDocument)
+	}
+}
+
+// ToText extracts text from the document and splits it into chunks (if needed).
+// Each chunk starts with a header containing file name and chunk number.
+func ToText(content types.Content, chunkSize int) ([]types.Content, error) {
+	text, err := Extract(content)
+	if err != nil {
+		return nil, err
+	}
+	name := content.Name
+	if name == "" {
+		name = "document." + content.Mime.DocumentFormat()
+	}
+
+	chunks := Split(text, chunkSize)
+	var out = make([]types.Content, 0, len(chunks))
+	for i, chunk := range chunks {
+		var header = "File: " + name
+		if len(chunks) > 1 {
+			header += fmt.Sprintf(" (part %d of %d)", i+1, len(chunks))
+		}
+		out = append(out, types.Content{
+			Data: []byte(header + "\n\n" + chunk),
+			Mime: types.MIMEText,
+			Name: content.Name,
+		})
+	}
+	return out, nil
+}
+
+// Split text to chunks with up to size characters. Lines are kept whole if possible.
+func Split(text string, size int) []string {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if utf8.RuneCountInString(text) <= size {
+		return []string{text}
+	}
+
+	var (
+		out     []string
+		current strings.Builder
+		length  int
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			out = append(out, current.String())
+			current.Reset()
+			length = 0
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		n := utf8.RuneCountInString(line)
+		if length+n > size {
+			flush()
+		}
+		// too long line - hard split by characters
+		for n > size {
+			runes := []rune(line)
+			out = append(out, string(runes[:size]))
+			line = string(runes[size:])
+			n -= size
+		}
+		current.WriteString(line)
+		length += n
+	}
+	flush()
+	return out
+}
+
+func extractPDF(data []byte) (text string, err error) {
+	// library may panic on malformed documents
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("open PDF: %w", err)
+	}
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", fmt.Errorf("get PDF text: %w", err)
+	}
+	content, err := io.ReadAll(plain)
+	if err != nil {
+		return "", fmt.Errorf("read PDF text: %w", err)
+	}
+	return string(content), nil
+}
+
+func extractHTML(data []byte) (string, error) {
+	var (
+		out  strings.Builder
+		skip int // inside script or style
+	)
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if errors.Is(tokenizer.Err(), io.EOF) {
+				return strings.TrimSpace(out.String()), nil
+			}
+			return "", fmt.Errorf("parse HTML: %w", tokenizer.Err())
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "script", "style", "noscript", "template":
+				skip++
+			case "p", "div", "br", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "section", "article":
+				out.WriteString("\n")
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "script", "style", "noscript", "template":
+				skip = max(0, skip-1)
+			}
+		case html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if string(name) == "br" {
+				out.WriteString("\n")
+			}
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			text := strings.TrimSpace(string(tokenizer.Text()))
+			if text == "" {
+				continue
+			}
+			if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+				out.WriteString(" ")
+			}
+			out.WriteString(text)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package documents_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/documents"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func TestSplit(t *testing.T) {
+	assert.Equal(t, []string{"hello\nworld"}, documents.Split("hello\nworld", 100))
+	assert.Equal(t, []string{"hello\n", "world"}, documents.Split("hello\nworld", 8))
+	assert.Equal(t, []string{"abc", "def", "g"}, documents.Split("abcdefg", 3))
+}
+
+func TestToText(t *testing.T) {
+	chunks, err := documents.ToText(types.Content{
+		Data: []byte(`<html><head><style>body{}</style><script>alert(1)</script></head><body><p>Hello <b>world</b></p></body></html>`),
+		Mime: types.MIMEHtml,
+		Name: "page.html",
+	}, 100)
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, types.MIMEText, chunks[0].Mime)
+	assert.Equal(t, "File: page.html\n\nHello world", chunks[0].String())
+
+	chunks, err = documents.ToText(types.Content{
+		Data: []byte(strings.Repeat("line\n", 10)),
+		Mime: types.MIMEMarkdown,
+	}, 20)
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.True(t, strings.HasPrefix(chunks[0].String(), "File: document.md (part 1 of 3)\n\n"))
+}
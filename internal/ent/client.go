@@ -15,6 +15,7 @@ import (
 	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/pikocloud/pikobrain/internal/ent/message"
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
 )
 
 // Client is the client that holds all ent builders.
@@ -24,6 +25,8 @@ type Client struct {
 	Schema *migrate.Schema
 	// Message is the client for interacting with the Message builders.
 	Message *MessageClient
+	// Moderation is the client for interacting with the Moderation builders.
+	Moderation *ModerationClient
 }
 
 // NewClient creates a new client configured with the given options.
@@ -36,6 +39,7 @@ func NewClient(opts ...Option) *Client {
 func (c *Client) init() {
 	c.Schema = migrate.NewSchema(c.driver)
 	c.Message = NewMessageClient(c.config)
+	c.Moderation = NewModerationClient(c.config)
 }
 
 type (
@@ -126,9 +130,10 @@ func (c *Client) Tx(ctx context.Context) (*Tx, error) {
 	cfg := c.config
 	cfg.driver = tx
 	return &Tx{
-		ctx:     ctx,
-		config:  cfg,
-		Message: NewMessageClient(cfg),
+		ctx:        ctx,
+		config:     cfg,
+		Message:    NewMessageClient(cfg),
+		Moderation: NewModerationClient(cfg),
 	}, nil
 }
 
@@ -146,9 +151,10 @@ func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
 	cfg := c.config
 	cfg.driver = &txDriver{tx: tx, drv: c.driver}
 	return &Tx{
-		ctx:     ctx,
-		config:  cfg,
-		Message: NewMessageClient(cfg),
+		ctx:        ctx,
+		config:     cfg,
+		Message:    NewMessageClient(cfg),
+		Moderation: NewModerationClient(cfg),
 	}, nil
 }
 
@@ -178,12 +184,14 @@ func (c *Client) Close() error {
 // In order to add hooks to a specific client, call: `client.Node.Use(...)`.
 func (c *Client) Use(hooks ...Hook) {
 	c.Message.Use(hooks...)
+	c.Moderation.Use(hooks...)
 }
 
 // Intercept adds the query interceptors to all the entity clients.
 // In order to add interceptors to a specific client, call: `client.Node.Intercept(...)`.
 func (c *Client) Intercept(interceptors ...Interceptor) {
 	c.Message.Intercept(interceptors...)
+	c.Moderation.Intercept(interceptors...)
 }
 
 // Mutate implements the ent.Mutator interface.
@@ -191,6 +199,8 @@ func (c *Client) Mutate(ctx context.Context, m Mutation) (Value, error) {
 	switch m := m.(type) {
 	case *MessageMutation:
 		return c.Message.mutate(ctx, m)
+	case *ModerationMutation:
+		return c.Moderation.mutate(ctx, m)
 	default:
 		return nil, fmt.Errorf("ent: unknown mutation type %T", m)
 	}
@@ -329,12 +339,145 @@ func (c *MessageClient) mutate(ctx context.Context, m *MessageMutation) (Value,
 	}
 }
 
+// ModerationClient is a client for the Moderation schema.
+type ModerationClient struct {
+	config
+}
+
+// NewModerationClient returns a client for the Moderation from the given config.
+func NewModerationClient(c config) *ModerationClient {
+	return &ModerationClient{config: c}
+}
+
+// Use adds a list of mutation hooks to the hooks stack.
+// A call to `Use(f, g, h)` equals to `entmoderation.Hooks(f(g(h())))`.
+func (c *ModerationClient) Use(hooks ...Hook) {
+	c.hooks.Moderation = append(c.hooks.Moderation, hooks...)
+}
+
+// Intercept adds a list of query interceptors to the interceptors stack.
+// A call to `Intercept(f, g, h)` equals to `entmoderation.Intercept(f(g(h())))`.
+func (c *ModerationClient) Intercept(interceptors ...Interceptor) {
+	c.inters.Moderation = append(c.inters.Moderation, interceptors...)
+}
+
+// Create returns a builder for creating a Moderation entity.
+func (c *ModerationClient) Create() *ModerationCreate {
+	mutation := newModerationMutation(c.config, OpCreate)
+	return &ModerationCreate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// CreateBulk returns a builder for creating a bulk of Moderation entities.
+func (c *ModerationClient) CreateBulk(builders ...*ModerationCreate) *ModerationCreateBulk {
+	return &ModerationCreateBulk{config: c.config, builders: builders}
+}
+
+// MapCreateBulk creates a bulk creation builder from the given slice. For each item in the slice, the function creates
+// a builder and applies setFunc on it.
+func (c *ModerationClient) MapCreateBulk(slice any, setFunc func(*ModerationCreate, int)) *ModerationCreateBulk {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice {
+		return &ModerationCreateBulk{err: fmt.Errorf("calling to ModerationClient.MapCreateBulk with wrong type %T, need slice", slice)}
+	}
+	builders := make([]*ModerationCreate, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		builders[i] = c.Create()
+		setFunc(builders[i], i)
+	}
+	return &ModerationCreateBulk{config: c.config, builders: builders}
+}
+
+// Update returns an update builder for Moderation.
+func (c *ModerationClient) Update() *ModerationUpdate {
+	mutation := newModerationMutation(c.config, OpUpdate)
+	return &ModerationUpdate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOne returns an update builder for the given entity.
+func (c *ModerationClient) UpdateOne(m *Moderation) *ModerationUpdateOne {
+	mutation := newModerationMutation(c.config, OpUpdateOne, withModeration(m))
+	return &ModerationUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOneID returns an update builder for the given id.
+func (c *ModerationClient) UpdateOneID(id int) *ModerationUpdateOne {
+	mutation := newModerationMutation(c.config, OpUpdateOne, withModerationID(id))
+	return &ModerationUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// Delete returns a delete builder for Moderation.
+func (c *ModerationClient) Delete() *ModerationDelete {
+	mutation := newModerationMutation(c.config, OpDelete)
+	return &ModerationDelete{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// DeleteOne returns a builder for deleting the given entity.
+func (c *ModerationClient) DeleteOne(m *Moderation) *ModerationDeleteOne {
+	return c.DeleteOneID(m.ID)
+}
+
+// DeleteOneID returns a builder for deleting the given entity by its id.
+func (c *ModerationClient) DeleteOneID(id int) *ModerationDeleteOne {
+	builder := c.Delete().Where(entmoderation.ID(id))
+	builder.mutation.id = &id
+	builder.mutation.op = OpDeleteOne
+	return &ModerationDeleteOne{builder}
+}
+
+// Query returns a query builder for Moderation.
+func (c *ModerationClient) Query() *ModerationQuery {
+	return &ModerationQuery{
+		config: c.config,
+		ctx:    &QueryContext{Type: TypeModeration},
+		inters: c.Interceptors(),
+	}
+}
+
+// Get returns a Moderation entity by its id.
+func (c *ModerationClient) Get(ctx context.Context, id int) (*Moderation, error) {
+	return c.Query().Where(entmoderation.ID(id)).Only(ctx)
+}
+
+// GetX is like Get, but panics if an error occurs.
+func (c *ModerationClient) GetX(ctx context.Context, id int) *Moderation {
+	obj, err := c.Get(ctx, id)
+	if err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// Hooks returns the client hooks.
+func (c *ModerationClient) Hooks() []Hook {
+	return c.hooks.Moderation
+}
+
+// Interceptors returns the client interceptors.
+func (c *ModerationClient) Interceptors() []Interceptor {
+	return c.inters.Moderation
+}
+
+func (c *ModerationClient) mutate(ctx context.Context, m *ModerationMutation) (Value, error) {
+	switch m.Op() {
+	case OpCreate:
+		return (&ModerationCreate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdate:
+		return (&ModerationUpdate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdateOne:
+		return (&ModerationUpdateOne{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpDelete, OpDeleteOne:
+		return (&ModerationDelete{config: c.config, hooks: c.Hooks(), mutation: m}).Exec(ctx)
+	default:
+		return nil, fmt.Errorf("ent: unknown Moderation mutation op: %q", m.Op())
+	}
+}
+
 // hooks and interceptors per client, for fast access.
 type (
 	hooks struct {
-		Message []ent.Hook
+		Message, Moderation []ent.Hook
 	}
 	inters struct {
-		Message []ent.Interceptor
+		Message, Moderation []ent.Interceptor
 	}
 )
@@ -13,6 +13,8 @@ import (
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"github.com/pikocloud/pikobrain/internal/ent/message"
+
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
 )
 
 // ent aliases to avoid import conflicts in user's code.
@@ -73,7 +75,8 @@ var (
 func checkColumn(table, column string) error {
 	initCheck.Do(func() {
 		columnCheck = sql.NewColumnCheck(map[string]func(string) bool{
-			message.Table: message.ValidColumn,
+			message.Table:       message.ValidColumn,
+			entmoderation.Table: entmoderation.ValidColumn,
 		})
 	})
 	return columnCheck(table, column)
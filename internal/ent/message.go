@@ -32,6 +32,10 @@ type Message struct {
 	Mime types.MIME `json:"mime,omitempty"`
 	// Content holds the value of the "content" field.
 	Content []byte `json:"content,omitempty"`
+	// FileName holds the value of the "file_name" field.
+	FileName string `json:"file_name,omitempty"`
+	// position of content in message, non-zero parts continue previous record
+	Part int `json:"part,omitempty"`
 	// CreatedAt holds the value of the "created_at" field.
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	// UpdatedAt holds the value of the "updated_at" field.
@@ -46,9 +50,9 @@ func (*Message) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case message.FieldContent:
 			values[i] = new([]byte)
-		case message.FieldID:
+		case message.FieldID, message.FieldPart:
 			values[i] = new(sql.NullInt64)
-		case message.FieldThread, message.FieldToolName, message.FieldToolID, message.FieldUser:
+		case message.FieldThread, message.FieldToolName, message.FieldToolID, message.FieldUser, message.FieldFileName:
 			values[i] = new(sql.NullString)
 		case message.FieldCreatedAt, message.FieldUpdatedAt:
 			values[i] = new(sql.NullTime)
@@ -119,6 +123,18 @@ func (m *Message) assignValues(columns []string, values []any) error {
 			} else if value != nil {
 				m.Content = *value
 			}
+		case message.FieldFileName:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field file_name", values[i])
+			} else if value.Valid {
+				m.FileName = value.String
+			}
+		case message.FieldPart:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field part", values[i])
+			} else if value.Valid {
+				m.Part = int(value.Int64)
+			}
 		case message.FieldCreatedAt:
 			if value, ok := values[i].(*sql.NullTime); !ok {
 				return fmt.Errorf("unexpected type %T for field created_at", values[i])
@@ -188,6 +204,12 @@ func (m *Message) String() string {
 	builder.WriteString("content=")
 	builder.WriteString(fmt.Sprintf("%v", m.Content))
 	builder.WriteString(", ")
+	builder.WriteString("file_name=")
+	builder.WriteString(m.FileName)
+	builder.WriteString(", ")
+	builder.WriteString("part=")
+	builder.WriteString(fmt.Sprintf("%v", m.Part))
+	builder.WriteString(", ")
 	builder.WriteString("created_at=")
 	builder.WriteString(m.CreatedAt.Format(time.ANSIC))
 	builder.WriteString(", ")
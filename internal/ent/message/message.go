@@ -28,6 +28,10 @@ const (
 	FieldMime = "mime"
 	// FieldContent holds the string denoting the content field in the database.
 	FieldContent = "content"
+	// FieldFileName holds the string denoting the file_name field in the database.
+	FieldFileName = "file_name"
+	// FieldPart holds the string denoting the part field in the database.
+	FieldPart = "part"
 	// FieldCreatedAt holds the string denoting the created_at field in the database.
 	FieldCreatedAt = "created_at"
 	// FieldUpdatedAt holds the string denoting the updated_at field in the database.
@@ -46,6 +50,8 @@ var Columns = []string{
 	FieldUser,
 	FieldMime,
 	FieldContent,
+	FieldFileName,
+	FieldPart,
 	FieldCreatedAt,
 	FieldUpdatedAt,
 }
@@ -67,6 +73,10 @@ var (
 	DefaultMime types.MIME
 	// MimeValidator is a validator for the "mime" field. It is called by the builders before save.
 	MimeValidator func(string) error
+	// DefaultPart holds the default value on creation for the "part" field.
+	DefaultPart int
+	// PartValidator is a validator for the "part" field. It is called by the builders before save.
+	PartValidator func(int) error
 	// DefaultCreatedAt holds the default value on creation for the "created_at" field.
 	DefaultCreatedAt func() time.Time
 	// DefaultUpdatedAt holds the default value on creation for the "updated_at" field.
@@ -113,6 +123,16 @@ func ByMime(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldMime, opts...).ToFunc()
 }
 
+// ByFileName orders the results by the file_name field.
+func ByFileName(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldFileName, opts...).ToFunc()
+}
+
+// ByPart orders the results by the part field.
+func ByPart(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPart, opts...).ToFunc()
+}
+
 // ByCreatedAt orders the results by the created_at field.
 func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
@@ -95,6 +95,34 @@ func (mc *MessageCreate) SetContent(b []byte) *MessageCreate {
 	return mc
 }
 
+// SetFileName sets the "file_name" field.
+func (mc *MessageCreate) SetFileName(s string) *MessageCreate {
+	mc.mutation.SetFileName(s)
+	return mc
+}
+
+// SetNillableFileName sets the "file_name" field if the given value is not nil.
+func (mc *MessageCreate) SetNillableFileName(s *string) *MessageCreate {
+	if s != nil {
+		mc.SetFileName(*s)
+	}
+	return mc
+}
+
+// SetPart sets the "part" field.
+func (mc *MessageCreate) SetPart(i int) *MessageCreate {
+	mc.mutation.SetPart(i)
+	return mc
+}
+
+// SetNillablePart sets the "part" field if the given value is not nil.
+func (mc *MessageCreate) SetNillablePart(i *int) *MessageCreate {
+	if i != nil {
+		mc.SetPart(*i)
+	}
+	return mc
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (mc *MessageCreate) SetCreatedAt(t time.Time) *MessageCreate {
 	mc.mutation.SetCreatedAt(t)
@@ -162,6 +190,10 @@ func (mc *MessageCreate) defaults() {
 		v := message.DefaultMime
 		mc.mutation.SetMime(v)
 	}
+	if _, ok := mc.mutation.Part(); !ok {
+		v := message.DefaultPart
+		mc.mutation.SetPart(v)
+	}
 	if _, ok := mc.mutation.CreatedAt(); !ok {
 		v := message.DefaultCreatedAt()
 		mc.mutation.SetCreatedAt(v)
@@ -196,6 +228,14 @@ func (mc *MessageCreate) check() error {
 	if _, ok := mc.mutation.Content(); !ok {
 		return &ValidationError{Name: "content", err: errors.New(`ent: missing required field "Message.content"`)}
 	}
+	if _, ok := mc.mutation.Part(); !ok {
+		return &ValidationError{Name: "part", err: errors.New(`ent: missing required field "Message.part"`)}
+	}
+	if v, ok := mc.mutation.Part(); ok {
+		if err := message.PartValidator(v); err != nil {
+			return &ValidationError{Name: "part", err: fmt.Errorf(`ent: validator failed for field "Message.part": %w`, err)}
+		}
+	}
 	if _, ok := mc.mutation.CreatedAt(); !ok {
 		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "Message.created_at"`)}
 	}
@@ -256,6 +296,14 @@ func (mc *MessageCreate) createSpec() (*Message, *sqlgraph.CreateSpec) {
 		_spec.SetField(message.FieldContent, field.TypeBytes, value)
 		_node.Content = value
 	}
+	if value, ok := mc.mutation.FileName(); ok {
+		_spec.SetField(message.FieldFileName, field.TypeString, value)
+		_node.FileName = value
+	}
+	if value, ok := mc.mutation.Part(); ok {
+		_spec.SetField(message.FieldPart, field.TypeInt, value)
+		_node.Part = value
+	}
 	if value, ok := mc.mutation.CreatedAt(); ok {
 		_spec.SetField(message.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
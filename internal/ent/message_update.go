@@ -137,6 +137,47 @@ func (mu *MessageUpdate) SetContent(b []byte) *MessageUpdate {
 	return mu
 }
 
+// SetFileName sets the "file_name" field.
+func (mu *MessageUpdate) SetFileName(s string) *MessageUpdate {
+	mu.mutation.SetFileName(s)
+	return mu
+}
+
+// SetNillableFileName sets the "file_name" field if the given value is not nil.
+func (mu *MessageUpdate) SetNillableFileName(s *string) *MessageUpdate {
+	if s != nil {
+		mu.SetFileName(*s)
+	}
+	return mu
+}
+
+// ClearFileName clears the value of the "file_name" field.
+func (mu *MessageUpdate) ClearFileName() *MessageUpdate {
+	mu.mutation.ClearFileName()
+	return mu
+}
+
+// SetPart sets the "part" field.
+func (mu *MessageUpdate) SetPart(i int) *MessageUpdate {
+	mu.mutation.ResetPart()
+	mu.mutation.SetPart(i)
+	return mu
+}
+
+// SetNillablePart sets the "part" field if the given value is not nil.
+func (mu *MessageUpdate) SetNillablePart(i *int) *MessageUpdate {
+	if i != nil {
+		mu.SetPart(*i)
+	}
+	return mu
+}
+
+// AddPart adds i to the "part" field.
+func (mu *MessageUpdate) AddPart(i int) *MessageUpdate {
+	mu.mutation.AddPart(i)
+	return mu
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (mu *MessageUpdate) SetCreatedAt(t time.Time) *MessageUpdate {
 	mu.mutation.SetCreatedAt(t)
@@ -210,6 +251,11 @@ func (mu *MessageUpdate) check() error {
 			return &ValidationError{Name: "mime", err: fmt.Errorf(`ent: validator failed for field "Message.mime": %w`, err)}
 		}
 	}
+	if v, ok := mu.mutation.Part(); ok {
+		if err := message.PartValidator(v); err != nil {
+			return &ValidationError{Name: "part", err: fmt.Errorf(`ent: validator failed for field "Message.part": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -255,6 +301,18 @@ func (mu *MessageUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := mu.mutation.Content(); ok {
 		_spec.SetField(message.FieldContent, field.TypeBytes, value)
 	}
+	if value, ok := mu.mutation.FileName(); ok {
+		_spec.SetField(message.FieldFileName, field.TypeString, value)
+	}
+	if mu.mutation.FileNameCleared() {
+		_spec.ClearField(message.FieldFileName, field.TypeString)
+	}
+	if value, ok := mu.mutation.Part(); ok {
+		_spec.SetField(message.FieldPart, field.TypeInt, value)
+	}
+	if value, ok := mu.mutation.AddedPart(); ok {
+		_spec.AddField(message.FieldPart, field.TypeInt, value)
+	}
 	if value, ok := mu.mutation.CreatedAt(); ok {
 		_spec.SetField(message.FieldCreatedAt, field.TypeTime, value)
 	}
@@ -389,6 +447,47 @@ func (muo *MessageUpdateOne) SetContent(b []byte) *MessageUpdateOne {
 	return muo
 }
 
+// SetFileName sets the "file_name" field.
+func (muo *MessageUpdateOne) SetFileName(s string) *MessageUpdateOne {
+	muo.mutation.SetFileName(s)
+	return muo
+}
+
+// SetNillableFileName sets the "file_name" field if the given value is not nil.
+func (muo *MessageUpdateOne) SetNillableFileName(s *string) *MessageUpdateOne {
+	if s != nil {
+		muo.SetFileName(*s)
+	}
+	return muo
+}
+
+// ClearFileName clears the value of the "file_name" field.
+func (muo *MessageUpdateOne) ClearFileName() *MessageUpdateOne {
+	muo.mutation.ClearFileName()
+	return muo
+}
+
+// SetPart sets the "part" field.
+func (muo *MessageUpdateOne) SetPart(i int) *MessageUpdateOne {
+	muo.mutation.ResetPart()
+	muo.mutation.SetPart(i)
+	return muo
+}
+
+// SetNillablePart sets the "part" field if the given value is not nil.
+func (muo *MessageUpdateOne) SetNillablePart(i *int) *MessageUpdateOne {
+	if i != nil {
+		muo.SetPart(*i)
+	}
+	return muo
+}
+
+// AddPart adds i to the "part" field.
+func (muo *MessageUpdateOne) AddPart(i int) *MessageUpdateOne {
+	muo.mutation.AddPart(i)
+	return muo
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (muo *MessageUpdateOne) SetCreatedAt(t time.Time) *MessageUpdateOne {
 	muo.mutation.SetCreatedAt(t)
@@ -475,6 +574,11 @@ func (muo *MessageUpdateOne) check() error {
 			return &ValidationError{Name: "mime", err: fmt.Errorf(`ent: validator failed for field "Message.mime": %w`, err)}
 		}
 	}
+	if v, ok := muo.mutation.Part(); ok {
+		if err := message.PartValidator(v); err != nil {
+			return &ValidationError{Name: "part", err: fmt.Errorf(`ent: validator failed for field "Message.part": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -537,6 +641,18 @@ func (muo *MessageUpdateOne) sqlSave(ctx context.Context) (_node *Message, err e
 	if value, ok := muo.mutation.Content(); ok {
 		_spec.SetField(message.FieldContent, field.TypeBytes, value)
 	}
+	if value, ok := muo.mutation.FileName(); ok {
+		_spec.SetField(message.FieldFileName, field.TypeString, value)
+	}
+	if muo.mutation.FileNameCleared() {
+		_spec.ClearField(message.FieldFileName, field.TypeString)
+	}
+	if value, ok := muo.mutation.Part(); ok {
+		_spec.SetField(message.FieldPart, field.TypeInt, value)
+	}
+	if value, ok := muo.mutation.AddedPart(); ok {
+		_spec.AddField(message.FieldPart, field.TypeInt, value)
+	}
 	if value, ok := muo.mutation.CreatedAt(); ok {
 		_spec.SetField(message.FieldCreatedAt, field.TypeTime, value)
 	}
@@ -18,6 +18,8 @@ var (
 		{Name: "user", Type: field.TypeString, Nullable: true},
 		{Name: "mime", Type: field.TypeString, Default: "text/plain"},
 		{Name: "content", Type: field.TypeBytes},
+		{Name: "file_name", Type: field.TypeString, Nullable: true},
+		{Name: "part", Type: field.TypeInt, Default: 0},
 		{Name: "created_at", Type: field.TypeTime},
 		{Name: "updated_at", Type: field.TypeTime},
 	}
@@ -39,9 +41,34 @@ var (
 			},
 		},
 	}
+	// ModerationsColumns holds the columns for the "moderations" table.
+	ModerationsColumns = []*schema.Column{
+		{Name: "id", Type: field.TypeInt, Increment: true},
+		{Name: "thread", Type: field.TypeString, Nullable: true, Size: 2147483647},
+		{Name: "stage", Type: field.TypeString},
+		{Name: "checker", Type: field.TypeString},
+		{Name: "reason", Type: field.TypeString, Size: 2147483647},
+		{Name: "user", Type: field.TypeString, Nullable: true},
+		{Name: "content", Type: field.TypeBytes},
+		{Name: "created_at", Type: field.TypeTime},
+	}
+	// ModerationsTable holds the schema information for the "moderations" table.
+	ModerationsTable = &schema.Table{
+		Name:       "moderations",
+		Columns:    ModerationsColumns,
+		PrimaryKey: []*schema.Column{ModerationsColumns[0]},
+		Indexes: []*schema.Index{
+			{
+				Name:    "moderation_thread",
+				Unique:  false,
+				Columns: []*schema.Column{ModerationsColumns[1]},
+			},
+		},
+	}
 	// Tables holds all the tables in the schema.
 	Tables = []*schema.Table{
 		MessagesTable,
+		ModerationsTable,
 	}
 )
 
@@ -0,0 +1,175 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
+	"github.com/pikocloud/pikobrain/internal/moderation"
+)
+
+// Moderation is the model entity for the Moderation schema.
+type Moderation struct {
+	config `json:"-"`
+	// ID of the ent.
+	ID int `json:"id,omitempty"`
+	// empty for stateless runs
+	Thread string `json:"thread,omitempty"`
+	// Stage holds the value of the "stage" field.
+	Stage moderation.Stage `json:"stage,omitempty"`
+	// Checker holds the value of the "checker" field.
+	Checker moderation.Kind `json:"checker,omitempty"`
+	// Reason holds the value of the "reason" field.
+	Reason string `json:"reason,omitempty"`
+	// User holds the value of the "user" field.
+	User string `json:"user,omitempty"`
+	// blocked text
+	Content []byte `json:"content,omitempty"`
+	// CreatedAt holds the value of the "created_at" field.
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+	selectValues sql.SelectValues
+}
+
+// scanValues returns the types for scanning values from sql.Rows.
+func (*Moderation) scanValues(columns []string) ([]any, error) {
+	values := make([]any, len(columns))
+	for i := range columns {
+		switch columns[i] {
+		case entmoderation.FieldContent:
+			values[i] = new([]byte)
+		case entmoderation.FieldID:
+			values[i] = new(sql.NullInt64)
+		case entmoderation.FieldThread, entmoderation.FieldStage, entmoderation.FieldChecker, entmoderation.FieldReason, entmoderation.FieldUser:
+			values[i] = new(sql.NullString)
+		case entmoderation.FieldCreatedAt:
+			values[i] = new(sql.NullTime)
+		default:
+			values[i] = new(sql.UnknownType)
+		}
+	}
+	return values, nil
+}
+
+// assignValues assigns the values that were returned from sql.Rows (after scanning)
+// to the Moderation fields.
+func (m *Moderation) assignValues(columns []string, values []any) error {
+	if m, n := len(values), len(columns); m < n {
+		return fmt.Errorf("mismatch number of scan values: %d != %d", m, n)
+	}
+	for i := range columns {
+		switch columns[i] {
+		case entmoderation.FieldID:
+			value, ok := values[i].(*sql.NullInt64)
+			if !ok {
+				return fmt.Errorf("unexpected type %T for field id", value)
+			}
+			m.ID = int(value.Int64)
+		case entmoderation.FieldThread:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field thread", values[i])
+			} else if value.Valid {
+				m.Thread = value.String
+			}
+		case entmoderation.FieldStage:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field stage", values[i])
+			} else if value.Valid {
+				m.Stage = moderation.Stage(value.String)
+			}
+		case entmoderation.FieldChecker:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field checker", values[i])
+			} else if value.Valid {
+				m.Checker = moderation.Kind(value.String)
+			}
+		case entmoderation.FieldReason:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field reason", values[i])
+			} else if value.Valid {
+				m.Reason = value.String
+			}
+		case entmoderation.FieldUser:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field user", values[i])
+			} else if value.Valid {
+				m.User = value.String
+			}
+		case entmoderation.FieldContent:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field content", values[i])
+			} else if value != nil {
+				m.Content = *value
+			}
+		case entmoderation.FieldCreatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field created_at", values[i])
+			} else if value.Valid {
+				m.CreatedAt = value.Time
+			}
+		default:
+			m.selectValues.Set(columns[i], values[i])
+		}
+	}
+	return nil
+}
+
+// Value returns the ent.Value that was dynamically selected and assigned to the Moderation.
+// This includes values selected through modifiers, order, etc.
+func (m *Moderation) Value(name string) (ent.Value, error) {
+	return m.selectValues.Get(name)
+}
+
+// Update returns a builder for updating this Moderation.
+// Note that you need to call Moderation.Unwrap() before calling this method if this Moderation
+// was returned from a transaction, and the transaction was committed or rolled back.
+func (m *Moderation) Update() *ModerationUpdateOne {
+	return NewModerationClient(m.config).UpdateOne(m)
+}
+
+// Unwrap unwraps the Moderation entity that was returned from a transaction after it was closed,
+// so that all future queries will be executed through the driver which created the transaction.
+func (m *Moderation) Unwrap() *Moderation {
+	_tx, ok := m.config.driver.(*txDriver)
+	if !ok {
+		panic("ent: Moderation is not a transactional entity")
+	}
+	m.config.driver = _tx.drv
+	return m
+}
+
+// String implements the fmt.Stringer.
+func (m *Moderation) String() string {
+	var builder strings.Builder
+	builder.WriteString("Moderation(")
+	builder.WriteString(fmt.Sprintf("id=%v, ", m.ID))
+	builder.WriteString("thread=")
+	builder.WriteString(m.Thread)
+	builder.WriteString(", ")
+	builder.WriteString("stage=")
+	builder.WriteString(fmt.Sprintf("%v", m.Stage))
+	builder.WriteString(", ")
+	builder.WriteString("checker=")
+	builder.WriteString(fmt.Sprintf("%v", m.Checker))
+	builder.WriteString(", ")
+	builder.WriteString("reason=")
+	builder.WriteString(m.Reason)
+	builder.WriteString(", ")
+	builder.WriteString("user=")
+	builder.WriteString(m.User)
+	builder.WriteString(", ")
+	builder.WriteString("content=")
+	builder.WriteString(fmt.Sprintf("%v", m.Content))
+	builder.WriteString(", ")
+	builder.WriteString("created_at=")
+	builder.WriteString(m.CreatedAt.Format(time.ANSIC))
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+// Moderations is a parsable slice of Moderation.
+type Moderations []*Moderation
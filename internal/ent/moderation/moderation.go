@@ -0,0 +1,101 @@
+// Code generated by ent, DO NOT EDIT.
+
+package entmoderation
+
+import (
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+)
+
+const (
+	// Label holds the string label denoting the moderation type in the database.
+	Label = "moderation"
+	// FieldID holds the string denoting the id field in the database.
+	FieldID = "id"
+	// FieldThread holds the string denoting the thread field in the database.
+	FieldThread = "thread"
+	// FieldStage holds the string denoting the stage field in the database.
+	FieldStage = "stage"
+	// FieldChecker holds the string denoting the checker field in the database.
+	FieldChecker = "checker"
+	// FieldReason holds the string denoting the reason field in the database.
+	FieldReason = "reason"
+	// FieldUser holds the string denoting the user field in the database.
+	FieldUser = "user"
+	// FieldContent holds the string denoting the content field in the database.
+	FieldContent = "content"
+	// FieldCreatedAt holds the string denoting the created_at field in the database.
+	FieldCreatedAt = "created_at"
+	// Table holds the table name of the moderation in the database.
+	Table = "moderations"
+)
+
+// Columns holds all SQL columns for moderation fields.
+var Columns = []string{
+	FieldID,
+	FieldThread,
+	FieldStage,
+	FieldChecker,
+	FieldReason,
+	FieldUser,
+	FieldContent,
+	FieldCreatedAt,
+}
+
+// ValidColumn reports if the column name is valid (part of the table columns).
+func ValidColumn(column string) bool {
+	for i := range Columns {
+		if column == Columns[i] {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// StageValidator is a validator for the "stage" field. It is called by the builders before save.
+	StageValidator func(string) error
+	// CheckerValidator is a validator for the "checker" field. It is called by the builders before save.
+	CheckerValidator func(string) error
+	// DefaultCreatedAt holds the default value on creation for the "created_at" field.
+	DefaultCreatedAt func() time.Time
+)
+
+// OrderOption defines the ordering options for the Moderation queries.
+type OrderOption func(*sql.Selector)
+
+// ByID orders the results by the id field.
+func ByID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldID, opts...).ToFunc()
+}
+
+// ByThread orders the results by the thread field.
+func ByThread(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldThread, opts...).ToFunc()
+}
+
+// ByStage orders the results by the stage field.
+func ByStage(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldStage, opts...).ToFunc()
+}
+
+// ByChecker orders the results by the checker field.
+func ByChecker(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldChecker, opts...).ToFunc()
+}
+
+// ByReason orders the results by the reason field.
+func ByReason(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldReason, opts...).ToFunc()
+}
+
+// ByUser orders the results by the user field.
+func ByUser(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldUser, opts...).ToFunc()
+}
+
+// ByCreatedAt orders the results by the created_at field.
+func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
+}
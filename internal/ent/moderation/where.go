@@ -0,0 +1,571 @@
+// Code generated by ent, DO NOT EDIT.
+
+package entmoderation
+
+import (
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/pikocloud/pikobrain/internal/ent/predicate"
+	"github.com/pikocloud/pikobrain/internal/moderation"
+)
+
+// ID filters vertices based on their ID field.
+func ID(id int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldID, id))
+}
+
+// IDEQ applies the EQ predicate on the ID field.
+func IDEQ(id int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldID, id))
+}
+
+// IDNEQ applies the NEQ predicate on the ID field.
+func IDNEQ(id int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNEQ(FieldID, id))
+}
+
+// IDIn applies the In predicate on the ID field.
+func IDIn(ids ...int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldIn(FieldID, ids...))
+}
+
+// IDNotIn applies the NotIn predicate on the ID field.
+func IDNotIn(ids ...int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotIn(FieldID, ids...))
+}
+
+// IDGT applies the GT predicate on the ID field.
+func IDGT(id int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGT(FieldID, id))
+}
+
+// IDGTE applies the GTE predicate on the ID field.
+func IDGTE(id int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGTE(FieldID, id))
+}
+
+// IDLT applies the LT predicate on the ID field.
+func IDLT(id int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLT(FieldID, id))
+}
+
+// IDLTE applies the LTE predicate on the ID field.
+func IDLTE(id int) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLTE(FieldID, id))
+}
+
+// Thread applies equality check predicate on the "thread" field. It's identical to ThreadEQ.
+func Thread(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldThread, v))
+}
+
+// Stage applies equality check predicate on the "stage" field. It's identical to StageEQ.
+func Stage(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldEQ(FieldStage, vc))
+}
+
+// Checker applies equality check predicate on the "checker" field. It's identical to CheckerEQ.
+func Checker(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldEQ(FieldChecker, vc))
+}
+
+// Reason applies equality check predicate on the "reason" field. It's identical to ReasonEQ.
+func Reason(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldReason, v))
+}
+
+// User applies equality check predicate on the "user" field. It's identical to UserEQ.
+func User(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldUser, v))
+}
+
+// Content applies equality check predicate on the "content" field. It's identical to ContentEQ.
+func Content(v []byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldContent, v))
+}
+
+// CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
+func CreatedAt(v time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// ThreadEQ applies the EQ predicate on the "thread" field.
+func ThreadEQ(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldThread, v))
+}
+
+// ThreadNEQ applies the NEQ predicate on the "thread" field.
+func ThreadNEQ(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNEQ(FieldThread, v))
+}
+
+// ThreadIn applies the In predicate on the "thread" field.
+func ThreadIn(vs ...string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldIn(FieldThread, vs...))
+}
+
+// ThreadNotIn applies the NotIn predicate on the "thread" field.
+func ThreadNotIn(vs ...string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotIn(FieldThread, vs...))
+}
+
+// ThreadGT applies the GT predicate on the "thread" field.
+func ThreadGT(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGT(FieldThread, v))
+}
+
+// ThreadGTE applies the GTE predicate on the "thread" field.
+func ThreadGTE(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGTE(FieldThread, v))
+}
+
+// ThreadLT applies the LT predicate on the "thread" field.
+func ThreadLT(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLT(FieldThread, v))
+}
+
+// ThreadLTE applies the LTE predicate on the "thread" field.
+func ThreadLTE(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLTE(FieldThread, v))
+}
+
+// ThreadContains applies the Contains predicate on the "thread" field.
+func ThreadContains(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldContains(FieldThread, v))
+}
+
+// ThreadHasPrefix applies the HasPrefix predicate on the "thread" field.
+func ThreadHasPrefix(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldHasPrefix(FieldThread, v))
+}
+
+// ThreadHasSuffix applies the HasSuffix predicate on the "thread" field.
+func ThreadHasSuffix(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldHasSuffix(FieldThread, v))
+}
+
+// ThreadIsNil applies the IsNil predicate on the "thread" field.
+func ThreadIsNil() predicate.Moderation {
+	return predicate.Moderation(sql.FieldIsNull(FieldThread))
+}
+
+// ThreadNotNil applies the NotNil predicate on the "thread" field.
+func ThreadNotNil() predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotNull(FieldThread))
+}
+
+// ThreadEqualFold applies the EqualFold predicate on the "thread" field.
+func ThreadEqualFold(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEqualFold(FieldThread, v))
+}
+
+// ThreadContainsFold applies the ContainsFold predicate on the "thread" field.
+func ThreadContainsFold(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldContainsFold(FieldThread, v))
+}
+
+// StageEQ applies the EQ predicate on the "stage" field.
+func StageEQ(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldEQ(FieldStage, vc))
+}
+
+// StageNEQ applies the NEQ predicate on the "stage" field.
+func StageNEQ(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldNEQ(FieldStage, vc))
+}
+
+// StageIn applies the In predicate on the "stage" field.
+func StageIn(vs ...moderation.Stage) predicate.Moderation {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = string(vs[i])
+	}
+	return predicate.Moderation(sql.FieldIn(FieldStage, v...))
+}
+
+// StageNotIn applies the NotIn predicate on the "stage" field.
+func StageNotIn(vs ...moderation.Stage) predicate.Moderation {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = string(vs[i])
+	}
+	return predicate.Moderation(sql.FieldNotIn(FieldStage, v...))
+}
+
+// StageGT applies the GT predicate on the "stage" field.
+func StageGT(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldGT(FieldStage, vc))
+}
+
+// StageGTE applies the GTE predicate on the "stage" field.
+func StageGTE(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldGTE(FieldStage, vc))
+}
+
+// StageLT applies the LT predicate on the "stage" field.
+func StageLT(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldLT(FieldStage, vc))
+}
+
+// StageLTE applies the LTE predicate on the "stage" field.
+func StageLTE(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldLTE(FieldStage, vc))
+}
+
+// StageContains applies the Contains predicate on the "stage" field.
+func StageContains(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldContains(FieldStage, vc))
+}
+
+// StageHasPrefix applies the HasPrefix predicate on the "stage" field.
+func StageHasPrefix(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldHasPrefix(FieldStage, vc))
+}
+
+// StageHasSuffix applies the HasSuffix predicate on the "stage" field.
+func StageHasSuffix(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldHasSuffix(FieldStage, vc))
+}
+
+// StageEqualFold applies the EqualFold predicate on the "stage" field.
+func StageEqualFold(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldEqualFold(FieldStage, vc))
+}
+
+// StageContainsFold applies the ContainsFold predicate on the "stage" field.
+func StageContainsFold(v moderation.Stage) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldContainsFold(FieldStage, vc))
+}
+
+// CheckerEQ applies the EQ predicate on the "checker" field.
+func CheckerEQ(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldEQ(FieldChecker, vc))
+}
+
+// CheckerNEQ applies the NEQ predicate on the "checker" field.
+func CheckerNEQ(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldNEQ(FieldChecker, vc))
+}
+
+// CheckerIn applies the In predicate on the "checker" field.
+func CheckerIn(vs ...moderation.Kind) predicate.Moderation {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = string(vs[i])
+	}
+	return predicate.Moderation(sql.FieldIn(FieldChecker, v...))
+}
+
+// CheckerNotIn applies the NotIn predicate on the "checker" field.
+func CheckerNotIn(vs ...moderation.Kind) predicate.Moderation {
+	v := make([]any, len(vs))
+	for i := range v {
+		v[i] = string(vs[i])
+	}
+	return predicate.Moderation(sql.FieldNotIn(FieldChecker, v...))
+}
+
+// CheckerGT applies the GT predicate on the "checker" field.
+func CheckerGT(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldGT(FieldChecker, vc))
+}
+
+// CheckerGTE applies the GTE predicate on the "checker" field.
+func CheckerGTE(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldGTE(FieldChecker, vc))
+}
+
+// CheckerLT applies the LT predicate on the "checker" field.
+func CheckerLT(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldLT(FieldChecker, vc))
+}
+
+// CheckerLTE applies the LTE predicate on the "checker" field.
+func CheckerLTE(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldLTE(FieldChecker, vc))
+}
+
+// CheckerContains applies the Contains predicate on the "checker" field.
+func CheckerContains(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldContains(FieldChecker, vc))
+}
+
+// CheckerHasPrefix applies the HasPrefix predicate on the "checker" field.
+func CheckerHasPrefix(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldHasPrefix(FieldChecker, vc))
+}
+
+// CheckerHasSuffix applies the HasSuffix predicate on the "checker" field.
+func CheckerHasSuffix(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldHasSuffix(FieldChecker, vc))
+}
+
+// CheckerEqualFold applies the EqualFold predicate on the "checker" field.
+func CheckerEqualFold(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldEqualFold(FieldChecker, vc))
+}
+
+// CheckerContainsFold applies the ContainsFold predicate on the "checker" field.
+func CheckerContainsFold(v moderation.Kind) predicate.Moderation {
+	vc := string(v)
+	return predicate.Moderation(sql.FieldContainsFold(FieldChecker, vc))
+}
+
+// ReasonEQ applies the EQ predicate on the "reason" field.
+func ReasonEQ(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldReason, v))
+}
+
+// ReasonNEQ applies the NEQ predicate on the "reason" field.
+func ReasonNEQ(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNEQ(FieldReason, v))
+}
+
+// ReasonIn applies the In predicate on the "reason" field.
+func ReasonIn(vs ...string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldIn(FieldReason, vs...))
+}
+
+// ReasonNotIn applies the NotIn predicate on the "reason" field.
+func ReasonNotIn(vs ...string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotIn(FieldReason, vs...))
+}
+
+// ReasonGT applies the GT predicate on the "reason" field.
+func ReasonGT(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGT(FieldReason, v))
+}
+
+// ReasonGTE applies the GTE predicate on the "reason" field.
+func ReasonGTE(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGTE(FieldReason, v))
+}
+
+// ReasonLT applies the LT predicate on the "reason" field.
+func ReasonLT(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLT(FieldReason, v))
+}
+
+// ReasonLTE applies the LTE predicate on the "reason" field.
+func ReasonLTE(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLTE(FieldReason, v))
+}
+
+// ReasonContains applies the Contains predicate on the "reason" field.
+func ReasonContains(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldContains(FieldReason, v))
+}
+
+// ReasonHasPrefix applies the HasPrefix predicate on the "reason" field.
+func ReasonHasPrefix(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldHasPrefix(FieldReason, v))
+}
+
+// ReasonHasSuffix applies the HasSuffix predicate on the "reason" field.
+func ReasonHasSuffix(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldHasSuffix(FieldReason, v))
+}
+
+// ReasonEqualFold applies the EqualFold predicate on the "reason" field.
+func ReasonEqualFold(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEqualFold(FieldReason, v))
+}
+
+// ReasonContainsFold applies the ContainsFold predicate on the "reason" field.
+func ReasonContainsFold(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldContainsFold(FieldReason, v))
+}
+
+// UserEQ applies the EQ predicate on the "user" field.
+func UserEQ(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldUser, v))
+}
+
+// UserNEQ applies the NEQ predicate on the "user" field.
+func UserNEQ(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNEQ(FieldUser, v))
+}
+
+// UserIn applies the In predicate on the "user" field.
+func UserIn(vs ...string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldIn(FieldUser, vs...))
+}
+
+// UserNotIn applies the NotIn predicate on the "user" field.
+func UserNotIn(vs ...string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotIn(FieldUser, vs...))
+}
+
+// UserGT applies the GT predicate on the "user" field.
+func UserGT(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGT(FieldUser, v))
+}
+
+// UserGTE applies the GTE predicate on the "user" field.
+func UserGTE(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGTE(FieldUser, v))
+}
+
+// UserLT applies the LT predicate on the "user" field.
+func UserLT(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLT(FieldUser, v))
+}
+
+// UserLTE applies the LTE predicate on the "user" field.
+func UserLTE(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLTE(FieldUser, v))
+}
+
+// UserContains applies the Contains predicate on the "user" field.
+func UserContains(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldContains(FieldUser, v))
+}
+
+// UserHasPrefix applies the HasPrefix predicate on the "user" field.
+func UserHasPrefix(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldHasPrefix(FieldUser, v))
+}
+
+// UserHasSuffix applies the HasSuffix predicate on the "user" field.
+func UserHasSuffix(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldHasSuffix(FieldUser, v))
+}
+
+// UserIsNil applies the IsNil predicate on the "user" field.
+func UserIsNil() predicate.Moderation {
+	return predicate.Moderation(sql.FieldIsNull(FieldUser))
+}
+
+// UserNotNil applies the NotNil predicate on the "user" field.
+func UserNotNil() predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotNull(FieldUser))
+}
+
+// UserEqualFold applies the EqualFold predicate on the "user" field.
+func UserEqualFold(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEqualFold(FieldUser, v))
+}
+
+// UserContainsFold applies the ContainsFold predicate on the "user" field.
+func UserContainsFold(v string) predicate.Moderation {
+	return predicate.Moderation(sql.FieldContainsFold(FieldUser, v))
+}
+
+// ContentEQ applies the EQ predicate on the "content" field.
+func ContentEQ(v []byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldContent, v))
+}
+
+// ContentNEQ applies the NEQ predicate on the "content" field.
+func ContentNEQ(v []byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNEQ(FieldContent, v))
+}
+
+// ContentIn applies the In predicate on the "content" field.
+func ContentIn(vs ...[]byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldIn(FieldContent, vs...))
+}
+
+// ContentNotIn applies the NotIn predicate on the "content" field.
+func ContentNotIn(vs ...[]byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotIn(FieldContent, vs...))
+}
+
+// ContentGT applies the GT predicate on the "content" field.
+func ContentGT(v []byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGT(FieldContent, v))
+}
+
+// ContentGTE applies the GTE predicate on the "content" field.
+func ContentGTE(v []byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGTE(FieldContent, v))
+}
+
+// ContentLT applies the LT predicate on the "content" field.
+func ContentLT(v []byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLT(FieldContent, v))
+}
+
+// ContentLTE applies the LTE predicate on the "content" field.
+func ContentLTE(v []byte) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLTE(FieldContent, v))
+}
+
+// CreatedAtEQ applies the EQ predicate on the "created_at" field.
+func CreatedAtEQ(v time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtNEQ applies the NEQ predicate on the "created_at" field.
+func CreatedAtNEQ(v time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtIn applies the In predicate on the "created_at" field.
+func CreatedAtIn(vs ...time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtNotIn applies the NotIn predicate on the "created_at" field.
+func CreatedAtNotIn(vs ...time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldNotIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtGT applies the GT predicate on the "created_at" field.
+func CreatedAtGT(v time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGT(FieldCreatedAt, v))
+}
+
+// CreatedAtGTE applies the GTE predicate on the "created_at" field.
+func CreatedAtGTE(v time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldGTE(FieldCreatedAt, v))
+}
+
+// CreatedAtLT applies the LT predicate on the "created_at" field.
+func CreatedAtLT(v time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLT(FieldCreatedAt, v))
+}
+
+// CreatedAtLTE applies the LTE predicate on the "created_at" field.
+func CreatedAtLTE(v time.Time) predicate.Moderation {
+	return predicate.Moderation(sql.FieldLTE(FieldCreatedAt, v))
+}
+
+// And groups predicates with the AND operator between them.
+func And(predicates ...predicate.Moderation) predicate.Moderation {
+	return predicate.Moderation(sql.AndPredicates(predicates...))
+}
+
+// Or groups predicates with the OR operator between them.
+func Or(predicates ...predicate.Moderation) predicate.Moderation {
+	return predicate.Moderation(sql.OrPredicates(predicates...))
+}
+
+// Not applies the not operator on the given predicate.
+func Not(p predicate.Moderation) predicate.Moderation {
+	return predicate.Moderation(sql.NotPredicates(p))
+}
@@ -0,0 +1,301 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
+	"github.com/pikocloud/pikobrain/internal/moderation"
+)
+
+// ModerationCreate is the builder for creating a Moderation entity.
+type ModerationCreate struct {
+	config
+	mutation *ModerationMutation
+	hooks    []Hook
+}
+
+// SetThread sets the "thread" field.
+func (mc *ModerationCreate) SetThread(s string) *ModerationCreate {
+	mc.mutation.SetThread(s)
+	return mc
+}
+
+// SetNillableThread sets the "thread" field if the given value is not nil.
+func (mc *ModerationCreate) SetNillableThread(s *string) *ModerationCreate {
+	if s != nil {
+		mc.SetThread(*s)
+	}
+	return mc
+}
+
+// SetStage sets the "stage" field.
+func (mc *ModerationCreate) SetStage(m moderation.Stage) *ModerationCreate {
+	mc.mutation.SetStage(m)
+	return mc
+}
+
+// SetChecker sets the "checker" field.
+func (mc *ModerationCreate) SetChecker(m moderation.Kind) *ModerationCreate {
+	mc.mutation.SetChecker(m)
+	return mc
+}
+
+// SetReason sets the "reason" field.
+func (mc *ModerationCreate) SetReason(s string) *ModerationCreate {
+	mc.mutation.SetReason(s)
+	return mc
+}
+
+// SetUser sets the "user" field.
+func (mc *ModerationCreate) SetUser(s string) *ModerationCreate {
+	mc.mutation.SetUser(s)
+	return mc
+}
+
+// SetNillableUser sets the "user" field if the given value is not nil.
+func (mc *ModerationCreate) SetNillableUser(s *string) *ModerationCreate {
+	if s != nil {
+		mc.SetUser(*s)
+	}
+	return mc
+}
+
+// SetContent sets the "content" field.
+func (mc *ModerationCreate) SetContent(b []byte) *ModerationCreate {
+	mc.mutation.SetContent(b)
+	return mc
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (mc *ModerationCreate) SetCreatedAt(t time.Time) *ModerationCreate {
+	mc.mutation.SetCreatedAt(t)
+	return mc
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (mc *ModerationCreate) SetNillableCreatedAt(t *time.Time) *ModerationCreate {
+	if t != nil {
+		mc.SetCreatedAt(*t)
+	}
+	return mc
+}
+
+// Mutation returns the ModerationMutation object of the builder.
+func (mc *ModerationCreate) Mutation() *ModerationMutation {
+	return mc.mutation
+}
+
+// Save creates the Moderation in the database.
+func (mc *ModerationCreate) Save(ctx context.Context) (*Moderation, error) {
+	mc.defaults()
+	return withHooks(ctx, mc.sqlSave, mc.mutation, mc.hooks)
+}
+
+// SaveX calls Save and panics if Save returns an error.
+func (mc *ModerationCreate) SaveX(ctx context.Context) *Moderation {
+	v, err := mc.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (mc *ModerationCreate) Exec(ctx context.Context) error {
+	_, err := mc.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (mc *ModerationCreate) ExecX(ctx context.Context) {
+	if err := mc.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// defaults sets the default values of the builder before save.
+func (mc *ModerationCreate) defaults() {
+	if _, ok := mc.mutation.CreatedAt(); !ok {
+		v := entmoderation.DefaultCreatedAt()
+		mc.mutation.SetCreatedAt(v)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (mc *ModerationCreate) check() error {
+	if _, ok := mc.mutation.Stage(); !ok {
+		return &ValidationError{Name: "stage", err: errors.New(`ent: missing required field "Moderation.stage"`)}
+	}
+	if v, ok := mc.mutation.Stage(); ok {
+		if err := entmoderation.StageValidator(string(v)); err != nil {
+			return &ValidationError{Name: "stage", err: fmt.Errorf(`ent: validator failed for field "Moderation.stage": %w`, err)}
+		}
+	}
+	if _, ok := mc.mutation.Checker(); !ok {
+		return &ValidationError{Name: "checker", err: errors.New(`ent: missing required field "Moderation.checker"`)}
+	}
+	if v, ok := mc.mutation.Checker(); ok {
+		if err := entmoderation.CheckerValidator(string(v)); err != nil {
+			return &ValidationError{Name: "checker", err: fmt.Errorf(`ent: validator failed for field "Moderation.checker": %w`, err)}
+		}
+	}
+	if _, ok := mc.mutation.Reason(); !ok {
+		return &ValidationError{Name: "reason", err: errors.New(`ent: missing required field "Moderation.reason"`)}
+	}
+	if _, ok := mc.mutation.Content(); !ok {
+		return &ValidationError{Name: "content", err: errors.New(`ent: missing required field "Moderation.content"`)}
+	}
+	if _, ok := mc.mutation.CreatedAt(); !ok {
+		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "Moderation.created_at"`)}
+	}
+	return nil
+}
+
+func (mc *ModerationCreate) sqlSave(ctx context.Context) (*Moderation, error) {
+	if err := mc.check(); err != nil {
+		return nil, err
+	}
+	_node, _spec := mc.createSpec()
+	if err := sqlgraph.CreateNode(ctx, mc.driver, _spec); err != nil {
+		if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return nil, err
+	}
+	id := _spec.ID.Value.(int64)
+	_node.ID = int(id)
+	mc.mutation.id = &_node.ID
+	mc.mutation.done = true
+	return _node, nil
+}
+
+func (mc *ModerationCreate) createSpec() (*Moderation, *sqlgraph.CreateSpec) {
+	var (
+		_node = &Moderation{config: mc.config}
+		_spec = sqlgraph.NewCreateSpec(entmoderation.Table, sqlgraph.NewFieldSpec(entmoderation.FieldID, field.TypeInt))
+	)
+	if value, ok := mc.mutation.Thread(); ok {
+		_spec.SetField(entmoderation.FieldThread, field.TypeString, value)
+		_node.Thread = value
+	}
+	if value, ok := mc.mutation.Stage(); ok {
+		_spec.SetField(entmoderation.FieldStage, field.TypeString, value)
+		_node.Stage = value
+	}
+	if value, ok := mc.mutation.Checker(); ok {
+		_spec.SetField(entmoderation.FieldChecker, field.TypeString, value)
+		_node.Checker = value
+	}
+	if value, ok := mc.mutation.Reason(); ok {
+		_spec.SetField(entmoderation.FieldReason, field.TypeString, value)
+		_node.Reason = value
+	}
+	if value, ok := mc.mutation.User(); ok {
+		_spec.SetField(entmoderation.FieldUser, field.TypeString, value)
+		_node.User = value
+	}
+	if value, ok := mc.mutation.Content(); ok {
+		_spec.SetField(entmoderation.FieldContent, field.TypeBytes, value)
+		_node.Content = value
+	}
+	if value, ok := mc.mutation.CreatedAt(); ok {
+		_spec.SetField(entmoderation.FieldCreatedAt, field.TypeTime, value)
+		_node.CreatedAt = value
+	}
+	return _node, _spec
+}
+
+// ModerationCreateBulk is the builder for creating many Moderation entities in bulk.
+type ModerationCreateBulk struct {
+	config
+	err      error
+	builders []*ModerationCreate
+}
+
+// Save creates the Moderation entities in the database.
+func (mcb *ModerationCreateBulk) Save(ctx context.Context) ([]*Moderation, error) {
+	if mcb.err != nil {
+		return nil, mcb.err
+	}
+	specs := make([]*sqlgraph.CreateSpec, len(mcb.builders))
+	nodes := make([]*Moderation, len(mcb.builders))
+	mutators := make([]Mutator, len(mcb.builders))
+	for i := range mcb.builders {
+		func(i int, root context.Context) {
+			builder := mcb.builders[i]
+			builder.defaults()
+			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
+				mutation, ok := m.(*ModerationMutation)
+				if !ok {
+					return nil, fmt.Errorf("unexpected mutation type %T", m)
+				}
+				if err := builder.check(); err != nil {
+					return nil, err
+				}
+				builder.mutation = mutation
+				var err error
+				nodes[i], specs[i] = builder.createSpec()
+				if i < len(mutators)-1 {
+					_, err = mutators[i+1].Mutate(root, mcb.builders[i+1].mutation)
+				} else {
+					spec := &sqlgraph.BatchCreateSpec{Nodes: specs}
+					// Invoke the actual operation on the latest mutation in the chain.
+					if err = sqlgraph.BatchCreate(ctx, mcb.driver, spec); err != nil {
+						if sqlgraph.IsConstraintError(err) {
+							err = &ConstraintError{msg: err.Error(), wrap: err}
+						}
+					}
+				}
+				if err != nil {
+					return nil, err
+				}
+				mutation.id = &nodes[i].ID
+				if specs[i].ID.Value != nil {
+					id := specs[i].ID.Value.(int64)
+					nodes[i].ID = int(id)
+				}
+				mutation.done = true
+				return nodes[i], nil
+			})
+			for i := len(builder.hooks) - 1; i >= 0; i-- {
+				mut = builder.hooks[i](mut)
+			}
+			mutators[i] = mut
+		}(i, ctx)
+	}
+	if len(mutators) > 0 {
+		if _, err := mutators[0].Mutate(ctx, mcb.builders[0].mutation); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (mcb *ModerationCreateBulk) SaveX(ctx context.Context) []*Moderation {
+	v, err := mcb.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (mcb *ModerationCreateBulk) Exec(ctx context.Context) error {
+	_, err := mcb.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (mcb *ModerationCreateBulk) ExecX(ctx context.Context) {
+	if err := mcb.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
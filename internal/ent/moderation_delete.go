@@ -0,0 +1,89 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	"github.com/pikocloud/pikobrain/internal/ent/predicate"
+
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
+)
+
+// ModerationDelete is the builder for deleting a Moderation entity.
+type ModerationDelete struct {
+	config
+	hooks    []Hook
+	mutation *ModerationMutation
+}
+
+// Where appends a list predicates to the ModerationDelete builder.
+func (md *ModerationDelete) Where(ps ...predicate.Moderation) *ModerationDelete {
+	md.mutation.Where(ps...)
+	return md
+}
+
+// Exec executes the deletion query and returns how many vertices were deleted.
+func (md *ModerationDelete) Exec(ctx context.Context) (int, error) {
+	return withHooks(ctx, md.sqlExec, md.mutation, md.hooks)
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (md *ModerationDelete) ExecX(ctx context.Context) int {
+	n, err := md.Exec(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func (md *ModerationDelete) sqlExec(ctx context.Context) (int, error) {
+	_spec := sqlgraph.NewDeleteSpec(entmoderation.Table, sqlgraph.NewFieldSpec(entmoderation.FieldID, field.TypeInt))
+	if ps := md.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	affected, err := sqlgraph.DeleteNodes(ctx, md.driver, _spec)
+	if err != nil && sqlgraph.IsConstraintError(err) {
+		err = &ConstraintError{msg: err.Error(), wrap: err}
+	}
+	md.mutation.done = true
+	return affected, err
+}
+
+// ModerationDeleteOne is the builder for deleting a single Moderation entity.
+type ModerationDeleteOne struct {
+	md *ModerationDelete
+}
+
+// Where appends a list predicates to the ModerationDelete builder.
+func (mdo *ModerationDeleteOne) Where(ps ...predicate.Moderation) *ModerationDeleteOne {
+	mdo.md.mutation.Where(ps...)
+	return mdo
+}
+
+// Exec executes the deletion query.
+func (mdo *ModerationDeleteOne) Exec(ctx context.Context) error {
+	n, err := mdo.md.Exec(ctx)
+	switch {
+	case err != nil:
+		return err
+	case n == 0:
+		return &NotFoundError{entmoderation.Label}
+	default:
+		return nil
+	}
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (mdo *ModerationDeleteOne) ExecX(ctx context.Context) {
+	if err := mdo.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
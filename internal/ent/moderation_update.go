@@ -0,0 +1,471 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
+	"github.com/pikocloud/pikobrain/internal/ent/predicate"
+	"github.com/pikocloud/pikobrain/internal/moderation"
+)
+
+// ModerationUpdate is the builder for updating Moderation entities.
+type ModerationUpdate struct {
+	config
+	hooks    []Hook
+	mutation *ModerationMutation
+}
+
+// Where appends a list predicates to the ModerationUpdate builder.
+func (mu *ModerationUpdate) Where(ps ...predicate.Moderation) *ModerationUpdate {
+	mu.mutation.Where(ps...)
+	return mu
+}
+
+// SetThread sets the "thread" field.
+func (mu *ModerationUpdate) SetThread(s string) *ModerationUpdate {
+	mu.mutation.SetThread(s)
+	return mu
+}
+
+// SetNillableThread sets the "thread" field if the given value is not nil.
+func (mu *ModerationUpdate) SetNillableThread(s *string) *ModerationUpdate {
+	if s != nil {
+		mu.SetThread(*s)
+	}
+	return mu
+}
+
+// ClearThread clears the value of the "thread" field.
+func (mu *ModerationUpdate) ClearThread() *ModerationUpdate {
+	mu.mutation.ClearThread()
+	return mu
+}
+
+// SetStage sets the "stage" field.
+func (mu *ModerationUpdate) SetStage(m moderation.Stage) *ModerationUpdate {
+	mu.mutation.SetStage(m)
+	return mu
+}
+
+// SetNillableStage sets the "stage" field if the given value is not nil.
+func (mu *ModerationUpdate) SetNillableStage(m *moderation.Stage) *ModerationUpdate {
+	if m != nil {
+		mu.SetStage(*m)
+	}
+	return mu
+}
+
+// SetChecker sets the "checker" field.
+func (mu *ModerationUpdate) SetChecker(m moderation.Kind) *ModerationUpdate {
+	mu.mutation.SetChecker(m)
+	return mu
+}
+
+// SetNillableChecker sets the "checker" field if the given value is not nil.
+func (mu *ModerationUpdate) SetNillableChecker(m *moderation.Kind) *ModerationUpdate {
+	if m != nil {
+		mu.SetChecker(*m)
+	}
+	return mu
+}
+
+// SetReason sets the "reason" field.
+func (mu *ModerationUpdate) SetReason(s string) *ModerationUpdate {
+	mu.mutation.SetReason(s)
+	return mu
+}
+
+// SetNillableReason sets the "reason" field if the given value is not nil.
+func (mu *ModerationUpdate) SetNillableReason(s *string) *ModerationUpdate {
+	if s != nil {
+		mu.SetReason(*s)
+	}
+	return mu
+}
+
+// SetUser sets the "user" field.
+func (mu *ModerationUpdate) SetUser(s string) *ModerationUpdate {
+	mu.mutation.SetUser(s)
+	return mu
+}
+
+// SetNillableUser sets the "user" field if the given value is not nil.
+func (mu *ModerationUpdate) SetNillableUser(s *string) *ModerationUpdate {
+	if s != nil {
+		mu.SetUser(*s)
+	}
+	return mu
+}
+
+// ClearUser clears the value of the "user" field.
+func (mu *ModerationUpdate) ClearUser() *ModerationUpdate {
+	mu.mutation.ClearUser()
+	return mu
+}
+
+// SetContent sets the "content" field.
+func (mu *ModerationUpdate) SetContent(b []byte) *ModerationUpdate {
+	mu.mutation.SetContent(b)
+	return mu
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (mu *ModerationUpdate) SetCreatedAt(t time.Time) *ModerationUpdate {
+	mu.mutation.SetCreatedAt(t)
+	return mu
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (mu *ModerationUpdate) SetNillableCreatedAt(t *time.Time) *ModerationUpdate {
+	if t != nil {
+		mu.SetCreatedAt(*t)
+	}
+	return mu
+}
+
+// Mutation returns the ModerationMutation object of the builder.
+func (mu *ModerationUpdate) Mutation() *ModerationMutation {
+	return mu.mutation
+}
+
+// Save executes the query and returns the number of nodes affected by the update operation.
+func (mu *ModerationUpdate) Save(ctx context.Context) (int, error) {
+	return withHooks(ctx, mu.sqlSave, mu.mutation, mu.hooks)
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (mu *ModerationUpdate) SaveX(ctx context.Context) int {
+	affected, err := mu.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return affected
+}
+
+// Exec executes the query.
+func (mu *ModerationUpdate) Exec(ctx context.Context) error {
+	_, err := mu.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (mu *ModerationUpdate) ExecX(ctx context.Context) {
+	if err := mu.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (mu *ModerationUpdate) check() error {
+	if v, ok := mu.mutation.Stage(); ok {
+		if err := entmoderation.StageValidator(string(v)); err != nil {
+			return &ValidationError{Name: "stage", err: fmt.Errorf(`ent: validator failed for field "Moderation.stage": %w`, err)}
+		}
+	}
+	if v, ok := mu.mutation.Checker(); ok {
+		if err := entmoderation.CheckerValidator(string(v)); err != nil {
+			return &ValidationError{Name: "checker", err: fmt.Errorf(`ent: validator failed for field "Moderation.checker": %w`, err)}
+		}
+	}
+	return nil
+}
+
+func (mu *ModerationUpdate) sqlSave(ctx context.Context) (n int, err error) {
+	if err := mu.check(); err != nil {
+		return n, err
+	}
+	_spec := sqlgraph.NewUpdateSpec(entmoderation.Table, entmoderation.Columns, sqlgraph.NewFieldSpec(entmoderation.FieldID, field.TypeInt))
+	if ps := mu.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	if value, ok := mu.mutation.Thread(); ok {
+		_spec.SetField(entmoderation.FieldThread, field.TypeString, value)
+	}
+	if mu.mutation.ThreadCleared() {
+		_spec.ClearField(entmoderation.FieldThread, field.TypeString)
+	}
+	if value, ok := mu.mutation.Stage(); ok {
+		_spec.SetField(entmoderation.FieldStage, field.TypeString, value)
+	}
+	if value, ok := mu.mutation.Checker(); ok {
+		_spec.SetField(entmoderation.FieldChecker, field.TypeString, value)
+	}
+	if value, ok := mu.mutation.Reason(); ok {
+		_spec.SetField(entmoderation.FieldReason, field.TypeString, value)
+	}
+	if value, ok := mu.mutation.User(); ok {
+		_spec.SetField(entmoderation.FieldUser, field.TypeString, value)
+	}
+	if mu.mutation.UserCleared() {
+		_spec.ClearField(entmoderation.FieldUser, field.TypeString)
+	}
+	if value, ok := mu.mutation.Content(); ok {
+		_spec.SetField(entmoderation.FieldContent, field.TypeBytes, value)
+	}
+	if value, ok := mu.mutation.CreatedAt(); ok {
+		_spec.SetField(entmoderation.FieldCreatedAt, field.TypeTime, value)
+	}
+	if n, err = sqlgraph.UpdateNodes(ctx, mu.driver, _spec); err != nil {
+		if _, ok := err.(*sqlgraph.NotFoundError); ok {
+			err = &NotFoundError{entmoderation.Label}
+		} else if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return 0, err
+	}
+	mu.mutation.done = true
+	return n, nil
+}
+
+// ModerationUpdateOne is the builder for updating a single Moderation entity.
+type ModerationUpdateOne struct {
+	config
+	fields   []string
+	hooks    []Hook
+	mutation *ModerationMutation
+}
+
+// SetThread sets the "thread" field.
+func (muo *ModerationUpdateOne) SetThread(s string) *ModerationUpdateOne {
+	muo.mutation.SetThread(s)
+	return muo
+}
+
+// SetNillableThread sets the "thread" field if the given value is not nil.
+func (muo *ModerationUpdateOne) SetNillableThread(s *string) *ModerationUpdateOne {
+	if s != nil {
+		muo.SetThread(*s)
+	}
+	return muo
+}
+
+// ClearThread clears the value of the "thread" field.
+func (muo *ModerationUpdateOne) ClearThread() *ModerationUpdateOne {
+	muo.mutation.ClearThread()
+	return muo
+}
+
+// SetStage sets the "stage" field.
+func (muo *ModerationUpdateOne) SetStage(m moderation.Stage) *ModerationUpdateOne {
+	muo.mutation.SetStage(m)
+	return muo
+}
+
+// SetNillableStage sets the "stage" field if the given value is not nil.
+func (muo *ModerationUpdateOne) SetNillableStage(m *moderation.Stage) *ModerationUpdateOne {
+	if m != nil {
+		muo.SetStage(*m)
+	}
+	return muo
+}
+
+// SetChecker sets the "checker" field.
+func (muo *ModerationUpdateOne) SetChecker(m moderation.Kind) *ModerationUpdateOne {
+	muo.mutation.SetChecker(m)
+	return muo
+}
+
+// SetNillableChecker sets the "checker" field if the given value is not nil.
+func (muo *ModerationUpdateOne) SetNillableChecker(m *moderation.Kind) *ModerationUpdateOne {
+	if m != nil {
+		muo.SetChecker(*m)
+	}
+	return muo
+}
+
+// SetReason sets the "reason" field.
+func (muo *ModerationUpdateOne) SetReason(s string) *ModerationUpdateOne {
+	muo.mutation.SetReason(s)
+	return muo
+}
+
+// SetNillableReason sets the "reason" field if the given value is not nil.
+func (muo *ModerationUpdateOne) SetNillableReason(s *string) *ModerationUpdateOne {
+	if s != nil {
+		muo.SetReason(*s)
+	}
+	return muo
+}
+
+// SetUser sets the "user" field.
+func (muo *ModerationUpdateOne) SetUser(s string) *ModerationUpdateOne {
+	muo.mutation.SetUser(s)
+	return muo
+}
+
+// SetNillableUser sets the "user" field if the given value is not nil.
+func (muo *ModerationUpdateOne) SetNillableUser(s *string) *ModerationUpdateOne {
+	if s != nil {
+		muo.SetUser(*s)
+	}
+	return muo
+}
+
+// ClearUser clears the value of the "user" field.
+func (muo *ModerationUpdateOne) ClearUser() *ModerationUpdateOne {
+	muo.mutation.ClearUser()
+	return muo
+}
+
+// SetContent sets the "content" field.
+func (muo *ModerationUpdateOne) SetContent(b []byte) *ModerationUpdateOne {
+	muo.mutation.SetContent(b)
+	return muo
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (muo *ModerationUpdateOne) SetCreatedAt(t time.Time) *ModerationUpdateOne {
+	muo.mutation.SetCreatedAt(t)
+	return muo
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (muo *ModerationUpdateOne) SetNillableCreatedAt(t *time.Time) *ModerationUpdateOne {
+	if t != nil {
+		muo.SetCreatedAt(*t)
+	}
+	return muo
+}
+
+// Mutation returns the ModerationMutation object of the builder.
+func (muo *ModerationUpdateOne) Mutation() *ModerationMutation {
+	return muo.mutation
+}
+
+// Where appends a list predicates to the ModerationUpdate builder.
+func (muo *ModerationUpdateOne) Where(ps ...predicate.Moderation) *ModerationUpdateOne {
+	muo.mutation.Where(ps...)
+	return muo
+}
+
+// Select allows selecting one or more fields (columns) of the returned entity.
+// The default is selecting all fields defined in the entity schema.
+func (muo *ModerationUpdateOne) Select(field string, fields ...string) *ModerationUpdateOne {
+	muo.fields = append([]string{field}, fields...)
+	return muo
+}
+
+// Save executes the query and returns the updated Moderation entity.
+func (muo *ModerationUpdateOne) Save(ctx context.Context) (*Moderation, error) {
+	return withHooks(ctx, muo.sqlSave, muo.mutation, muo.hooks)
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (muo *ModerationUpdateOne) SaveX(ctx context.Context) *Moderation {
+	node, err := muo.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return node
+}
+
+// Exec executes the query on the entity.
+func (muo *ModerationUpdateOne) Exec(ctx context.Context) error {
+	_, err := muo.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (muo *ModerationUpdateOne) ExecX(ctx context.Context) {
+	if err := muo.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (muo *ModerationUpdateOne) check() error {
+	if v, ok := muo.mutation.Stage(); ok {
+		if err := entmoderation.StageValidator(string(v)); err != nil {
+			return &ValidationError{Name: "stage", err: fmt.Errorf(`ent: validator failed for field "Moderation.stage": %w`, err)}
+		}
+	}
+	if v, ok := muo.mutation.Checker(); ok {
+		if err := entmoderation.CheckerValidator(string(v)); err != nil {
+			return &ValidationError{Name: "checker", err: fmt.Errorf(`ent: validator failed for field "Moderation.checker": %w`, err)}
+		}
+	}
+	return nil
+}
+
+func (muo *ModerationUpdateOne) sqlSave(ctx context.Context) (_node *Moderation, err error) {
+	if err := muo.check(); err != nil {
+		return _node, err
+	}
+	_spec := sqlgraph.NewUpdateSpec(entmoderation.Table, entmoderation.Columns, sqlgraph.NewFieldSpec(entmoderation.FieldID, field.TypeInt))
+	id, ok := muo.mutation.ID()
+	if !ok {
+		return nil, &ValidationError{Name: "id", err: errors.New(`ent: missing "Moderation.id" for update`)}
+	}
+	_spec.Node.ID.Value = id
+	if fields := muo.fields; len(fields) > 0 {
+		_spec.Node.Columns = make([]string, 0, len(fields))
+		_spec.Node.Columns = append(_spec.Node.Columns, entmoderation.FieldID)
+		for _, f := range fields {
+			if !entmoderation.ValidColumn(f) {
+				return nil, &ValidationError{Name: f, err: fmt.Errorf("ent: invalid field %q for query", f)}
+			}
+			if f != entmoderation.FieldID {
+				_spec.Node.Columns = append(_spec.Node.Columns, f)
+			}
+		}
+	}
+	if ps := muo.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	if value, ok := muo.mutation.Thread(); ok {
+		_spec.SetField(entmoderation.FieldThread, field.TypeString, value)
+	}
+	if muo.mutation.ThreadCleared() {
+		_spec.ClearField(entmoderation.FieldThread, field.TypeString)
+	}
+	if value, ok := muo.mutation.Stage(); ok {
+		_spec.SetField(entmoderation.FieldStage, field.TypeString, value)
+	}
+	if value, ok := muo.mutation.Checker(); ok {
+		_spec.SetField(entmoderation.FieldChecker, field.TypeString, value)
+	}
+	if value, ok := muo.mutation.Reason(); ok {
+		_spec.SetField(entmoderation.FieldReason, field.TypeString, value)
+	}
+	if value, ok := muo.mutation.User(); ok {
+		_spec.SetField(entmoderation.FieldUser, field.TypeString, value)
+	}
+	if muo.mutation.UserCleared() {
+		_spec.ClearField(entmoderation.FieldUser, field.TypeString)
+	}
+	if value, ok := muo.mutation.Content(); ok {
+		_spec.SetField(entmoderation.FieldContent, field.TypeBytes, value)
+	}
+	if value, ok := muo.mutation.CreatedAt(); ok {
+		_spec.SetField(entmoderation.FieldCreatedAt, field.TypeTime, value)
+	}
+	_node = &Moderation{config: muo.config}
+	_spec.Assign = _node.assignValues
+	_spec.ScanValues = _node.scanValues
+	if err = sqlgraph.UpdateNode(ctx, muo.driver, _spec); err != nil {
+		if _, ok := err.(*sqlgraph.NotFoundError); ok {
+			err = &NotFoundError{entmoderation.Label}
+		} else if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return nil, err
+	}
+	muo.mutation.done = true
+	return _node, nil
+}
@@ -12,7 +12,9 @@ import (
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
 	"github.com/pikocloud/pikobrain/internal/ent/message"
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
 	"github.com/pikocloud/pikobrain/internal/ent/predicate"
+	"github.com/pikocloud/pikobrain/internal/moderation"
 	"github.com/pikocloud/pikobrain/internal/providers/types"
 )
 
@@ -25,7 +27,8 @@ const (
 	OpUpdateOne = ent.OpUpdateOne
 
 	// Node types.
-	TypeMessage = "Message"
+	TypeMessage    = "Message"
+	TypeModeration = "Moderation"
 )
 
 // MessageMutation represents an operation that mutates the Message nodes in the graph.
@@ -41,6 +44,9 @@ type MessageMutation struct {
 	user          *string
 	mime          *types.MIME
 	content       *[]byte
+	file_name     *string
+	part          *int
+	addpart       *int
 	created_at    *time.Time
 	updated_at    *time.Time
 	clearedFields map[string]struct{}
@@ -438,6 +444,111 @@ func (m *MessageMutation) ResetContent() {
 	m.content = nil
 }
 
+// SetFileName sets the "file_name" field.
+func (m *MessageMutation) SetFileName(s string) {
+	m.file_name = &s
+}
+
+// FileName returns the value of the "file_name" field in the mutation.
+func (m *MessageMutation) FileName() (r string, exists bool) {
+	v := m.file_name
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldFileName returns the old "file_name" field's value of the Message entity.
+// If the Message object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *MessageMutation) OldFileName(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldFileName is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldFileName requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldFileName: %w", err)
+	}
+	return oldValue.FileName, nil
+}
+
+// ClearFileName clears the value of the "file_name" field.
+func (m *MessageMutation) ClearFileName() {
+	m.file_name = nil
+	m.clearedFields[message.FieldFileName] = struct{}{}
+}
+
+// FileNameCleared returns if the "file_name" field was cleared in this mutation.
+func (m *MessageMutation) FileNameCleared() bool {
+	_, ok := m.clearedFields[message.FieldFileName]
+	return ok
+}
+
+// ResetFileName resets all changes to the "file_name" field.
+func (m *MessageMutation) ResetFileName() {
+	m.file_name = nil
+	delete(m.clearedFields, message.FieldFileName)
+}
+
+// SetPart sets the "part" field.
+func (m *MessageMutation) SetPart(i int) {
+	m.part = &i
+	m.addpart = nil
+}
+
+// Part returns the value of the "part" field in the mutation.
+func (m *MessageMutation) Part() (r int, exists bool) {
+	v := m.part
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPart returns the old "part" field's value of the Message entity.
+// If the Message object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *MessageMutation) OldPart(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPart is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPart requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPart: %w", err)
+	}
+	return oldValue.Part, nil
+}
+
+// AddPart adds i to the "part" field.
+func (m *MessageMutation) AddPart(i int) {
+	if m.addpart != nil {
+		*m.addpart += i
+	} else {
+		m.addpart = &i
+	}
+}
+
+// AddedPart returns the value that was added to the "part" field in this mutation.
+func (m *MessageMutation) AddedPart() (r int, exists bool) {
+	v := m.addpart
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetPart resets all changes to the "part" field.
+func (m *MessageMutation) ResetPart() {
+	m.part = nil
+	m.addpart = nil
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (m *MessageMutation) SetCreatedAt(t time.Time) {
 	m.created_at = &t
@@ -544,7 +655,7 @@ func (m *MessageMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *MessageMutation) Fields() []string {
-	fields := make([]string, 0, 9)
+	fields := make([]string, 0, 11)
 	if m.thread != nil {
 		fields = append(fields, message.FieldThread)
 	}
@@ -566,6 +677,12 @@ func (m *MessageMutation) Fields() []string {
 	if m.content != nil {
 		fields = append(fields, message.FieldContent)
 	}
+	if m.file_name != nil {
+		fields = append(fields, message.FieldFileName)
+	}
+	if m.part != nil {
+		fields = append(fields, message.FieldPart)
+	}
 	if m.created_at != nil {
 		fields = append(fields, message.FieldCreatedAt)
 	}
@@ -594,6 +711,10 @@ func (m *MessageMutation) Field(name string) (ent.Value, bool) {
 		return m.Mime()
 	case message.FieldContent:
 		return m.Content()
+	case message.FieldFileName:
+		return m.FileName()
+	case message.FieldPart:
+		return m.Part()
 	case message.FieldCreatedAt:
 		return m.CreatedAt()
 	case message.FieldUpdatedAt:
@@ -621,6 +742,10 @@ func (m *MessageMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldMime(ctx)
 	case message.FieldContent:
 		return m.OldContent(ctx)
+	case message.FieldFileName:
+		return m.OldFileName(ctx)
+	case message.FieldPart:
+		return m.OldPart(ctx)
 	case message.FieldCreatedAt:
 		return m.OldCreatedAt(ctx)
 	case message.FieldUpdatedAt:
@@ -683,6 +808,20 @@ func (m *MessageMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetContent(v)
 		return nil
+	case message.FieldFileName:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetFileName(v)
+		return nil
+	case message.FieldPart:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPart(v)
+		return nil
 	case message.FieldCreatedAt:
 		v, ok := value.(time.Time)
 		if !ok {
@@ -704,13 +843,21 @@ func (m *MessageMutation) SetField(name string, value ent.Value) error {
 // AddedFields returns all numeric fields that were incremented/decremented during
 // this mutation.
 func (m *MessageMutation) AddedFields() []string {
-	return nil
+	var fields []string
+	if m.addpart != nil {
+		fields = append(fields, message.FieldPart)
+	}
+	return fields
 }
 
 // AddedField returns the numeric value that was incremented/decremented on a field
 // with the given name. The second boolean return value indicates that this field
 // was not set, or was not defined in the schema.
 func (m *MessageMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case message.FieldPart:
+		return m.AddedPart()
+	}
 	return nil, false
 }
 
@@ -719,6 +866,13 @@ func (m *MessageMutation) AddedField(name string) (ent.Value, bool) {
 // type.
 func (m *MessageMutation) AddField(name string, value ent.Value) error {
 	switch name {
+	case message.FieldPart:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddPart(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Message numeric field %s", name)
 }
@@ -736,6 +890,9 @@ func (m *MessageMutation) ClearedFields() []string {
 	if m.FieldCleared(message.FieldUser) {
 		fields = append(fields, message.FieldUser)
 	}
+	if m.FieldCleared(message.FieldFileName) {
+		fields = append(fields, message.FieldFileName)
+	}
 	return fields
 }
 
@@ -759,6 +916,9 @@ func (m *MessageMutation) ClearField(name string) error {
 	case message.FieldUser:
 		m.ClearUser()
 		return nil
+	case message.FieldFileName:
+		m.ClearFileName()
+		return nil
 	}
 	return fmt.Errorf("unknown Message nullable field %s", name)
 }
@@ -788,6 +948,12 @@ func (m *MessageMutation) ResetField(name string) error {
 	case message.FieldContent:
 		m.ResetContent()
 		return nil
+	case message.FieldFileName:
+		m.ResetFileName()
+		return nil
+	case message.FieldPart:
+		m.ResetPart()
+		return nil
 	case message.FieldCreatedAt:
 		m.ResetCreatedAt()
 		return nil
@@ -845,3 +1011,694 @@ func (m *MessageMutation) ClearEdge(name string) error {
 func (m *MessageMutation) ResetEdge(name string) error {
 	return fmt.Errorf("unknown Message edge %s", name)
 }
+
+// ModerationMutation represents an operation that mutates the Moderation nodes in the graph.
+type ModerationMutation struct {
+	config
+	op            Op
+	typ           string
+	id            *int
+	thread        *string
+	stage         *moderation.Stage
+	checker       *moderation.Kind
+	reason        *string
+	user          *string
+	content       *[]byte
+	created_at    *time.Time
+	clearedFields map[string]struct{}
+	done          bool
+	oldValue      func(context.Context) (*Moderation, error)
+	predicates    []predicate.Moderation
+}
+
+var _ ent.Mutation = (*ModerationMutation)(nil)
+
+// moderationOption allows management of the mutation configuration using functional options.
+type moderationOption func(*ModerationMutation)
+
+// newModerationMutation creates new mutation for the Moderation entity.
+func newModerationMutation(c config, op Op, opts ...moderationOption) *ModerationMutation {
+	m := &ModerationMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeModeration,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withModerationID sets the ID field of the mutation.
+func withModerationID(id int) moderationOption {
+	return func(m *ModerationMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *Moderation
+		)
+		m.oldValue = func(ctx context.Context) (*Moderation, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().Moderation.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withModeration sets the old Moderation of the mutation.
+func withModeration(node *Moderation) moderationOption {
+	return func(m *ModerationMutation) {
+		m.oldValue = func(context.Context) (*Moderation, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m ModerationMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m ModerationMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("ent: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *ModerationMutation) ID() (id int, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *ModerationMutation) IDs(ctx context.Context) ([]int, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []int{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().Moderation.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetThread sets the "thread" field.
+func (m *ModerationMutation) SetThread(s string) {
+	m.thread = &s
+}
+
+// Thread returns the value of the "thread" field in the mutation.
+func (m *ModerationMutation) Thread() (r string, exists bool) {
+	v := m.thread
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldThread returns the old "thread" field's value of the Moderation entity.
+// If the Moderation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ModerationMutation) OldThread(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldThread is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldThread requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldThread: %w", err)
+	}
+	return oldValue.Thread, nil
+}
+
+// ClearThread clears the value of the "thread" field.
+func (m *ModerationMutation) ClearThread() {
+	m.thread = nil
+	m.clearedFields[entmoderation.FieldThread] = struct{}{}
+}
+
+// ThreadCleared returns if the "thread" field was cleared in this mutation.
+func (m *ModerationMutation) ThreadCleared() bool {
+	_, ok := m.clearedFields[entmoderation.FieldThread]
+	return ok
+}
+
+// ResetThread resets all changes to the "thread" field.
+func (m *ModerationMutation) ResetThread() {
+	m.thread = nil
+	delete(m.clearedFields, entmoderation.FieldThread)
+}
+
+// SetStage sets the "stage" field.
+func (m *ModerationMutation) SetStage(value moderation.Stage) {
+	m.stage = &value
+}
+
+// Stage returns the value of the "stage" field in the mutation.
+func (m *ModerationMutation) Stage() (r moderation.Stage, exists bool) {
+	v := m.stage
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStage returns the old "stage" field's value of the Moderation entity.
+// If the Moderation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ModerationMutation) OldStage(ctx context.Context) (v moderation.Stage, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStage is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStage requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStage: %w", err)
+	}
+	return oldValue.Stage, nil
+}
+
+// ResetStage resets all changes to the "stage" field.
+func (m *ModerationMutation) ResetStage() {
+	m.stage = nil
+}
+
+// SetChecker sets the "checker" field.
+func (m *ModerationMutation) SetChecker(value moderation.Kind) {
+	m.checker = &value
+}
+
+// Checker returns the value of the "checker" field in the mutation.
+func (m *ModerationMutation) Checker() (r moderation.Kind, exists bool) {
+	v := m.checker
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldChecker returns the old "checker" field's value of the Moderation entity.
+// If the Moderation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ModerationMutation) OldChecker(ctx context.Context) (v moderation.Kind, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldChecker is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldChecker requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldChecker: %w", err)
+	}
+	return oldValue.Checker, nil
+}
+
+// ResetChecker resets all changes to the "checker" field.
+func (m *ModerationMutation) ResetChecker() {
+	m.checker = nil
+}
+
+// SetReason sets the "reason" field.
+func (m *ModerationMutation) SetReason(s string) {
+	m.reason = &s
+}
+
+// Reason returns the value of the "reason" field in the mutation.
+func (m *ModerationMutation) Reason() (r string, exists bool) {
+	v := m.reason
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldReason returns the old "reason" field's value of the Moderation entity.
+// If the Moderation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ModerationMutation) OldReason(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldReason is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldReason requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldReason: %w", err)
+	}
+	return oldValue.Reason, nil
+}
+
+// ResetReason resets all changes to the "reason" field.
+func (m *ModerationMutation) ResetReason() {
+	m.reason = nil
+}
+
+// SetUser sets the "user" field.
+func (m *ModerationMutation) SetUser(s string) {
+	m.user = &s
+}
+
+// User returns the value of the "user" field in the mutation.
+func (m *ModerationMutation) User() (r string, exists bool) {
+	v := m.user
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldUser returns the old "user" field's value of the Moderation entity.
+// If the Moderation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ModerationMutation) OldUser(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldUser is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldUser requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldUser: %w", err)
+	}
+	return oldValue.User, nil
+}
+
+// ClearUser clears the value of the "user" field.
+func (m *ModerationMutation) ClearUser() {
+	m.user = nil
+	m.clearedFields[entmoderation.FieldUser] = struct{}{}
+}
+
+// UserCleared returns if the "user" field was cleared in this mutation.
+func (m *ModerationMutation) UserCleared() bool {
+	_, ok := m.clearedFields[entmoderation.FieldUser]
+	return ok
+}
+
+// ResetUser resets all changes to the "user" field.
+func (m *ModerationMutation) ResetUser() {
+	m.user = nil
+	delete(m.clearedFields, entmoderation.FieldUser)
+}
+
+// SetContent sets the "content" field.
+func (m *ModerationMutation) SetContent(b []byte) {
+	m.content = &b
+}
+
+// Content returns the value of the "content" field in the mutation.
+func (m *ModerationMutation) Content() (r []byte, exists bool) {
+	v := m.content
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldContent returns the old "content" field's value of the Moderation entity.
+// If the Moderation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ModerationMutation) OldContent(ctx context.Context) (v []byte, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldContent is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldContent requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldContent: %w", err)
+	}
+	return oldValue.Content, nil
+}
+
+// ResetContent resets all changes to the "content" field.
+func (m *ModerationMutation) ResetContent() {
+	m.content = nil
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *ModerationMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *ModerationMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the Moderation entity.
+// If the Moderation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ModerationMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *ModerationMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// Where appends a list predicates to the ModerationMutation builder.
+func (m *ModerationMutation) Where(ps ...predicate.Moderation) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the ModerationMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *ModerationMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.Moderation, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *ModerationMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *ModerationMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (Moderation).
+func (m *ModerationMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *ModerationMutation) Fields() []string {
+	fields := make([]string, 0, 7)
+	if m.thread != nil {
+		fields = append(fields, entmoderation.FieldThread)
+	}
+	if m.stage != nil {
+		fields = append(fields, entmoderation.FieldStage)
+	}
+	if m.checker != nil {
+		fields = append(fields, entmoderation.FieldChecker)
+	}
+	if m.reason != nil {
+		fields = append(fields, entmoderation.FieldReason)
+	}
+	if m.user != nil {
+		fields = append(fields, entmoderation.FieldUser)
+	}
+	if m.content != nil {
+		fields = append(fields, entmoderation.FieldContent)
+	}
+	if m.created_at != nil {
+		fields = append(fields, entmoderation.FieldCreatedAt)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *ModerationMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case entmoderation.FieldThread:
+		return m.Thread()
+	case entmoderation.FieldStage:
+		return m.Stage()
+	case entmoderation.FieldChecker:
+		return m.Checker()
+	case entmoderation.FieldReason:
+		return m.Reason()
+	case entmoderation.FieldUser:
+		return m.User()
+	case entmoderation.FieldContent:
+		return m.Content()
+	case entmoderation.FieldCreatedAt:
+		return m.CreatedAt()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *ModerationMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case entmoderation.FieldThread:
+		return m.OldThread(ctx)
+	case entmoderation.FieldStage:
+		return m.OldStage(ctx)
+	case entmoderation.FieldChecker:
+		return m.OldChecker(ctx)
+	case entmoderation.FieldReason:
+		return m.OldReason(ctx)
+	case entmoderation.FieldUser:
+		return m.OldUser(ctx)
+	case entmoderation.FieldContent:
+		return m.OldContent(ctx)
+	case entmoderation.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	}
+	return nil, fmt.Errorf("unknown Moderation field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *ModerationMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case entmoderation.FieldThread:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetThread(v)
+		return nil
+	case entmoderation.FieldStage:
+		v, ok := value.(moderation.Stage)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStage(v)
+		return nil
+	case entmoderation.FieldChecker:
+		v, ok := value.(moderation.Kind)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetChecker(v)
+		return nil
+	case entmoderation.FieldReason:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetReason(v)
+		return nil
+	case entmoderation.FieldUser:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUser(v)
+		return nil
+	case entmoderation.FieldContent:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContent(v)
+		return nil
+	case entmoderation.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Moderation field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *ModerationMutation) AddedFields() []string {
+	return nil
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *ModerationMutation) AddedField(name string) (ent.Value, bool) {
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *ModerationMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	}
+	return fmt.Errorf("unknown Moderation numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *ModerationMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(entmoderation.FieldThread) {
+		fields = append(fields, entmoderation.FieldThread)
+	}
+	if m.FieldCleared(entmoderation.FieldUser) {
+		fields = append(fields, entmoderation.FieldUser)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *ModerationMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *ModerationMutation) ClearField(name string) error {
+	switch name {
+	case entmoderation.FieldThread:
+		m.ClearThread()
+		return nil
+	case entmoderation.FieldUser:
+		m.ClearUser()
+		return nil
+	}
+	return fmt.Errorf("unknown Moderation nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *ModerationMutation) ResetField(name string) error {
+	switch name {
+	case entmoderation.FieldThread:
+		m.ResetThread()
+		return nil
+	case entmoderation.FieldStage:
+		m.ResetStage()
+		return nil
+	case entmoderation.FieldChecker:
+		m.ResetChecker()
+		return nil
+	case entmoderation.FieldReason:
+		m.ResetReason()
+		return nil
+	case entmoderation.FieldUser:
+		m.ResetUser()
+		return nil
+	case entmoderation.FieldContent:
+		m.ResetContent()
+		return nil
+	case entmoderation.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	}
+	return fmt.Errorf("unknown Moderation field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *ModerationMutation) AddedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *ModerationMutation) AddedIDs(name string) []ent.Value {
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *ModerationMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *ModerationMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *ModerationMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *ModerationMutation) EdgeCleared(name string) bool {
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *ModerationMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown Moderation unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *ModerationMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown Moderation edge %s", name)
+}
@@ -8,3 +8,6 @@ import (
 
 // Message is the predicate function for message builders.
 type Message func(*sql.Selector)
+
+// Moderation is the predicate function for entmoderation builders.
+type Moderation func(*sql.Selector)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/pikocloud/pikobrain/internal/ent/message"
+	entmoderation "github.com/pikocloud/pikobrain/internal/ent/moderation"
 	"github.com/pikocloud/pikobrain/internal/ent/schema"
 	"github.com/pikocloud/pikobrain/internal/providers/types"
 )
@@ -26,14 +27,34 @@ func init() {
 	message.DefaultMime = types.MIME(messageDescMime.Default.(string))
 	// message.MimeValidator is a validator for the "mime" field. It is called by the builders before save.
 	message.MimeValidator = messageDescMime.Validators[0].(func(string) error)
+	// messageDescPart is the schema descriptor for part field.
+	messageDescPart := messageFields[8].Descriptor()
+	// message.DefaultPart holds the default value on creation for the part field.
+	message.DefaultPart = messageDescPart.Default.(int)
+	// message.PartValidator is a validator for the "part" field. It is called by the builders before save.
+	message.PartValidator = messageDescPart.Validators[0].(func(int) error)
 	// messageDescCreatedAt is the schema descriptor for created_at field.
-	messageDescCreatedAt := messageFields[7].Descriptor()
+	messageDescCreatedAt := messageFields[9].Descriptor()
 	// message.DefaultCreatedAt holds the default value on creation for the created_at field.
 	message.DefaultCreatedAt = messageDescCreatedAt.Default.(func() time.Time)
 	// messageDescUpdatedAt is the schema descriptor for updated_at field.
-	messageDescUpdatedAt := messageFields[8].Descriptor()
+	messageDescUpdatedAt := messageFields[10].Descriptor()
 	// message.DefaultUpdatedAt holds the default value on creation for the updated_at field.
 	message.DefaultUpdatedAt = messageDescUpdatedAt.Default.(func() time.Time)
 	// message.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	message.UpdateDefaultUpdatedAt = messageDescUpdatedAt.UpdateDefault.(func() time.Time)
+	entmoderationFields := schema.Moderation{}.Fields()
+	_ = entmoderationFields
+	// entmoderationDescStage is the schema descriptor for stage field.
+	entmoderationDescStage := entmoderationFields[1].Descriptor()
+	// entmoderation.StageValidator is a validator for the "stage" field. It is called by the builders before save.
+	entmoderation.StageValidator = entmoderationDescStage.Validators[0].(func(string) error)
+	// entmoderationDescChecker is the schema descriptor for checker field.
+	entmoderationDescChecker := entmoderationFields[2].Descriptor()
+	// entmoderation.CheckerValidator is a validator for the "checker" field. It is called by the builders before save.
+	entmoderation.CheckerValidator = entmoderationDescChecker.Validators[0].(func(string) error)
+	// entmoderationDescCreatedAt is the schema descriptor for created_at field.
+	entmoderationDescCreatedAt := entmoderationFields[6].Descriptor()
+	// entmoderation.DefaultCreatedAt holds the default value on creation for the created_at field.
+	entmoderation.DefaultCreatedAt = entmoderationDescCreatedAt.Default.(func() time.Time)
 }
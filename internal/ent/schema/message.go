@@ -25,6 +25,8 @@ func (Message) Fields() []ent.Field {
 		field.String("user").Optional(),
 		field.String("mime").GoType(types.MIME("")).Default(string(types.MIMEText)).NotEmpty(),
 		field.Bytes("content"),
+		field.String("file_name").Optional(),
+		field.Int("part").Default(0).NonNegative().Comment("position of content in message, non-zero parts continue previous record"),
 		field.Time("created_at").Default(time.Now),
 		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
 	}
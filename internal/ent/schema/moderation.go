@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+
+	"github.com/pikocloud/pikobrain/internal/moderation"
+)
+
+// Moderation holds the schema definition for the Moderation entity (content blocked by moderation).
+type Moderation struct {
+	ent.Schema
+}
+
+// Fields of the Moderation.
+func (Moderation) Fields() []ent.Field {
+	return []ent.Field{
+		field.Text("thread").Optional().Comment("empty for stateless runs"),
+		field.String("stage").GoType(moderation.Stage("")).NotEmpty(),
+		field.String("checker").GoType(moderation.Kind("")).NotEmpty(),
+		field.Text("reason"),
+		field.String("user").Optional(),
+		field.Bytes("content").Comment("blocked text"),
+		field.Time("created_at").Default(time.Now),
+	}
+}
+
+// Edges of the Moderation.
+func (Moderation) Edges() []ent.Edge {
+	return nil
+}
+
+func (Moderation) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("thread"),
+	}
+}
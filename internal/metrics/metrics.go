@@ -0,0 +1,171 @@
+// Package metrics contains Prometheus metrics of the service.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
+)
+
+const namespace = "pikobrain"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests by route and status code",
+	}, []string{"route", "code"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by route and status code",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14), // 10ms - ~80s
+	}, []string{"route", "code"})
+
+	invokes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_invocations_total",
+		Help:      "Total number of provider invocations by provider, model and outcome (success or error)",
+	}, []string{"provider", "model", "outcome"})
+
+	invokeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_invocation_duration_seconds",
+		Help:      "Duration of provider invocations by provider and model",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 11), // 100ms - ~100s
+	}, []string{"provider", "model"})
+
+	tokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_total",
+		Help:      "Total number of used tokens by provider, model and direction (input, output or cached - input read from prompt cache)",
+	}, []string{"provider", "model", "direction"})
+
+	toolCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Total number of tool calls by tool name and outcome (success or error)",
+	}, []string{"tool", "outcome"})
+
+	toolDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Duration of tool calls by tool name",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12), // 10ms - ~20s
+	}, []string{"tool"})
+
+	toolRefreshFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_refresh_failures_total",
+		Help:      "Total number of failed tool providers refreshes",
+	})
+
+	moderationBlocks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "moderation_blocks_total",
+		Help:      "Total number of contents blocked by moderation by stage (input or output) and checker",
+	}, []string{"stage", "checker"})
+
+	activeRuns = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_runs",
+		Help:      "Number of runs in progress",
+	})
+)
+
+// Handler exposes metrics in Prometheus format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Instrument HTTP router. Route label is registration pattern (not actual path) to keep cardinality low.
+func Instrument(router *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, route := router.Handler(request)
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{"route": route}
+		promhttp.InstrumentHandlerDuration(httpDuration.MustCurryWith(labels),
+			promhttp.InstrumentHandlerCounter(httpRequests.MustCurryWith(labels), router)).ServeHTTP(writer, request)
+	})
+}
+
+// Invoke records provider invocation.
+func Invoke(provider, model string, duration time.Duration, inputTokens, outputTokens, cachedTokens int, err error) {
+	invokes.WithLabelValues(provider, model, outcome(err)).Inc()
+	invokeDuration.WithLabelValues(provider, model).Observe(duration.Seconds())
+	if err != nil {
+		return
+	}
+	tokens.WithLabelValues(provider, model, "input").Add(float64(inputTokens))
+	tokens.WithLabelValues(provider, model, "output").Add(float64(outputTokens))
+	tokens.WithLabelValues(provider, model, "cached").Add(float64(cachedTokens))
+}
+
+// ToolCall records tool call.
+func ToolCall(tool string, duration time.Duration, err error) {
+	toolCalls.WithLabelValues(tool, outcome(err)).Inc()
+	toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// ToolRefresh records result of tools update. Each failed provider counted separately.
+func ToolRefresh(err error) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+		toolRefreshFailures.Add(float64(len(joined.Unwrap())))
+		return
+	}
+	toolRefreshFailures.Inc()
+}
+
+// RateLimit exposes stats of provider rate limiter.
+func RateLimit(stats func() ratelimit.Stats) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_queue",
+		Help:      "Number of requests waiting for provider rate limit",
+	}, func() float64 {
+		return float64(stats().Queue)
+	})
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_delayed_total",
+		Help:      "Total number of requests delayed by provider rate limit",
+	}, func() float64 {
+		return float64(stats().Delayed)
+	})
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_wait_seconds_total",
+		Help:      "Total time spent waiting for provider rate limit",
+	}, func() float64 {
+		return stats().Wait.Seconds()
+	})
+}
+
+// Blocked records content blocked by moderation.
+func Blocked(stage, checker string) {
+	moderationBlocks.WithLabelValues(stage, checker).Inc()
+}
+
+// RunStarted marks run as active. Returned function should be called once run finished.
+func RunStarted() func() {
+	activeRuns.Inc()
+	return activeRuns.Dec
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
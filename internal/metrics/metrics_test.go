@@ -0,0 +1,75 @@
+package metrics_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/metrics"
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
+)
+
+func TestHandler(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("POST /{thread}", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusAccepted)
+	})
+	router.Handle("GET /metrics", metrics.Handler())
+	srv := httptest.NewServer(metrics.Instrument(router))
+	defer srv.Close()
+
+	res, err := http.Post(srv.URL+"/some-thread", "text/plain", nil)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	metrics.Invoke("openai", "gpt-4o", time.Second, 10, 2, 4, nil)
+	metrics.Invoke("openai", "gpt-4o", time.Second, 10, 2, 4, errors.New("failed"))
+	metrics.ToolCall("search", 50*time.Millisecond, nil)
+	metrics.ToolRefresh(errors.Join(errors.New("first"), errors.New("second")))
+	metrics.Blocked("input", "keywords")
+	done := metrics.RunStarted()
+	metrics.RateLimit(func() ratelimit.Stats { return ratelimit.Stats{Queue: 3, Delayed: 5, Wait: 2 * time.Second} })
+
+	res, err = http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	out := string(data)
+
+	for _, line := range []string{
+		`pikobrain_http_requests_total{code="202",route="POST /{thread}"} 1`,
+		`pikobrain_http_request_duration_seconds_count{code="202",route="POST /{thread}"} 1`,
+		`pikobrain_provider_invocations_total{model="gpt-4o",outcome="success",provider="openai"} 1`,
+		`pikobrain_provider_invocations_total{model="gpt-4o",outcome="error",provider="openai"} 1`,
+		`pikobrain_provider_invocation_duration_seconds_count{model="gpt-4o",provider="openai"} 2`,
+		`pikobrain_tokens_total{direction="input",model="gpt-4o",provider="openai"} 10`,
+		`pikobrain_tokens_total{direction="output",model="gpt-4o",provider="openai"} 2`,
+		`pikobrain_tokens_total{direction="cached",model="gpt-4o",provider="openai"} 4`,
+		`pikobrain_tool_calls_total{outcome="success",tool="search"} 1`,
+		`pikobrain_tool_call_duration_seconds_count{tool="search"} 1`,
+		`pikobrain_tool_refresh_failures_total 2`,
+		`pikobrain_moderation_blocks_total{checker="keywords",stage="input"} 1`,
+		`pikobrain_active_runs 1`,
+		`pikobrain_rate_limit_queue 3`,
+		`pikobrain_rate_limit_delayed_total 5`,
+		`pikobrain_rate_limit_wait_seconds_total 2`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+
+	done()
+	res, err = http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "pikobrain_active_runs 0\n")
+}
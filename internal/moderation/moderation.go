@@ -0,0 +1,239 @@
+// Package moderation checks user input and model output by configurable checkers.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+//go:generate go run github.com/abice/go-enum@v0.6.0 --marshal
+
+// Kind of checker.
+// ENUM(keywords,regex,openai,model)
+type Kind string
+
+// Stage of moderation.
+// ENUM(input,output)
+type Stage string
+
+// ErrBlocked returned (wrapped by [BlockedError]) if content is rejected by moderation.
+var ErrBlocked = errors.New("blocked by moderation")
+
+// DefaultPrompt for classifier model. Model should answer SAFE or UNSAFE with reason.
+const DefaultPrompt = `You are a content moderator. Classify the message below.
+Answer with the single word SAFE if the message is acceptable.
+Otherwise answer UNSAFE followed by a short reason on the same line (ex: "UNSAFE: harassment").`
+
+type Config struct {
+	Input  []CheckerConfig `json:"input,omitempty" yaml:"input,omitempty"`   // checks of user messages before the first model call
+	Output []CheckerConfig `json:"output,omitempty" yaml:"output,omitempty"` // checks of model reply
+}
+
+type CheckerConfig struct {
+	Type      Kind                 `json:"type" yaml:"type"`
+	Keywords  []string             `json:"keywords,omitempty" yaml:"keywords,omitempty"`    // case-insensitive words or phrases (keywords)
+	Patterns  []string             `json:"patterns,omitempty" yaml:"patterns,omitempty"`    // regular expressions (regex)
+	Model     string               `json:"model,omitempty" yaml:"model,omitempty"`          // moderation model (openai) or classifier model of main provider (model)
+	Prompt    string               `json:"prompt,omitempty" yaml:"prompt,omitempty"`        // classifier instruction (model), default is DefaultPrompt
+	MaxTokens int                  `json:"max_tokens,omitempty" yaml:"maxTokens,omitempty"` // classifier max tokens (model), default 50
+	URL       string               `json:"url,omitempty" yaml:"url,omitempty"`              // OpenAI-compatible API URL (openai). If not set, main provider is used
+	Secret    *utils.Value[string] `json:"secret,omitempty" yaml:"secret,omitempty"`        // API secret (openai). If not set, main provider secret is used
+}
+
+// Checker returns non-empty reason if text must be blocked.
+type Checker interface {
+	Check(ctx context.Context, text string) (reason string, err error)
+}
+
+// BlockedError describes which checker blocked content and why.
+type BlockedError struct {
+	Stage   Stage
+	Checker Kind
+	Reason  string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s %v (%s): %s", e.Stage, ErrBlocked, e.Checker, e.Reason)
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrBlocked
+}
+
+// NewChecker creates checker by config. Provider is used by classifier model, moderator - by openai checker.
+func NewChecker(config CheckerConfig, provider types.Provider, moderator types.Moderator) (Checker, error) {
+	switch config.Type {
+	case KindKeywords:
+		if len(config.Keywords) == 0 {
+			return nil, fmt.Errorf("no keywords")
+		}
+		var words = make([]string, 0, len(config.Keywords))
+		for _, word := range config.Keywords {
+			words = append(words, strings.ToLower(word))
+		}
+		return Keywords(words), nil
+	case KindRegex:
+		if len(config.Patterns) == 0 {
+			return nil, fmt.Errorf("no patterns")
+		}
+		var patterns = make(Regex, 0, len(config.Patterns))
+		for _, pattern := range config.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("compile pattern %q: %w", pattern, err)
+			}
+			patterns = append(patterns, re)
+		}
+		return patterns, nil
+	case KindOpenai:
+		if moderator == nil {
+			return nil, fmt.Errorf("moderation API is not available, set URL")
+		}
+		return &OpenAI{moderator: moderator, model: config.Model}, nil
+	case KindModel:
+		if config.Model == "" {
+			return nil, fmt.Errorf("classifier model is not set")
+		}
+		prompt := config.Prompt
+		if prompt == "" {
+			prompt = DefaultPrompt
+		}
+		maxTokens := config.MaxTokens
+		if maxTokens <= 0 {
+			maxTokens = 50
+		}
+		return &Classifier{provider: provider, config: types.Config{Model: config.Model, Prompt: prompt, MaxTokens: maxTokens}}, nil
+	default:
+		return nil, fmt.Errorf("unknown checker type %q", config.Type)
+	}
+}
+
+// Keywords blocks text which contains any of (lower-cased) words or phrases.
+type Keywords []string
+
+func (k Keywords) Check(_ context.Context, text string) (string, error) {
+	text = strings.ToLower(text)
+	for _, word := range k {
+		if strings.Contains(text, word) {
+			return fmt.Sprintf("contains %q", word), nil
+		}
+	}
+	return "", nil
+}
+
+// Regex blocks text which matches any of patterns.
+type Regex []*regexp.Regexp
+
+func (r Regex) Check(_ context.Context, text string) (string, error) {
+	for _, re := range r {
+		if re.MatchString(text) {
+			return fmt.Sprintf("matches %q", re.String()), nil
+		}
+	}
+	return "", nil
+}
+
+// OpenAI moderation endpoint (or compatible).
+type OpenAI struct {
+	moderator types.Moderator
+	model     string
+}
+
+func (o *OpenAI) Check(ctx context.Context, text string) (string, error) {
+	categories, err := o.moderator.Moderate(ctx, o.model, text)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(categories, ", "), nil
+}
+
+// Classifier asks another model to classify text. Answer which doesn't start from SAFE blocks text.
+type Classifier struct {
+	provider types.Provider
+	config   types.Config
+}
+
+func (c *Classifier) Check(ctx context.Context, text string) (string, error) {
+	res, err := c.provider.Invoke(ctx, c.config, []types.Message{{
+		Role:  types.RoleUser,
+		Parts: []types.Content{types.Text(text)},
+	}}, nil)
+	if err != nil {
+		return "", fmt.Errorf("invoke classifier: %w", err)
+	}
+	var answer string
+	for _, msg := range res.Output {
+		if msg.Role == types.RoleAssistant {
+			answer = strings.TrimSpace(msg.Text())
+			break
+		}
+	}
+	verdict, reason, _ := strings.Cut(answer, "\n")
+	if strings.HasPrefix(strings.ToUpper(verdict), "SAFE") {
+		return "", nil
+	}
+	if strings.HasPrefix(strings.ToUpper(verdict), "UNSAFE") {
+		reason = strings.TrimLeft(verdict[len("UNSAFE"):], " :-")
+	}
+	if reason == "" {
+		reason = "classified as unsafe"
+	}
+	return reason, nil
+}
+
+// Checkers of single stage. Checkers are called in order, the first flagged result blocks content.
+type Checkers struct {
+	stage    Stage
+	kinds    []Kind
+	checkers []Checker
+}
+
+// NewCheckers creates checkers for stage. Moderator is resolved for openai checkers only. See [NewChecker] for details.
+func NewCheckers(stage Stage, configs []CheckerConfig, provider types.Provider, moderator func(config CheckerConfig) (types.Moderator, error)) (*Checkers, error) {
+	var out = &Checkers{stage: stage}
+	for i, config := range configs {
+		var api types.Moderator
+		if config.Type == KindOpenai {
+			v, err := moderator(config)
+			if err != nil {
+				return nil, fmt.Errorf("%s checker #%d (%s): %w", stage, i, config.Type, err)
+			}
+			api = v
+		}
+		checker, err := NewChecker(config, provider, api)
+		if err != nil {
+			return nil, fmt.Errorf("%s checker #%d (%s): %w", stage, i, config.Type, err)
+		}
+		out.kinds = append(out.kinds, config.Type)
+		out.checkers = append(out.checkers, checker)
+	}
+	return out, nil
+}
+
+// Empty returns true if there are no checkers.
+func (c *Checkers) Empty() bool {
+	return c == nil || len(c.checkers) == 0
+}
+
+// Check text by all checkers. Returns [BlockedError] if text is blocked.
+func (c *Checkers) Check(ctx context.Context, text string) error {
+	if c.Empty() || strings.TrimSpace(text) == "" {
+		return nil
+	}
+	for i, checker := range c.checkers {
+		reason, err := checker.Check(ctx, text)
+		if err != nil {
+			return fmt.Errorf("%s moderation by %s: %w", c.stage, c.kinds[i], err)
+		}
+		if reason != "" {
+			return &BlockedError{Stage: c.stage, Checker: c.kinds[i], Reason: reason}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package moderation
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// KindKeywords is a Kind of type keywords.
+	KindKeywords Kind = "keywords"
+	// KindRegex is a Kind of type regex.
+	KindRegex Kind = "regex"
+	// KindOpenai is a Kind of type openai.
+	KindOpenai Kind = "openai"
+	// KindModel is a Kind of type model.
+	KindModel Kind = "model"
+)
+
+var ErrInvalidKind = errors.New("not a valid Kind")
+
+// String implements the Stringer interface.
+func (x Kind) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Kind) IsValid() bool {
+	_, err := ParseKind(string(x))
+	return err == nil
+}
+
+var _KindValue = map[string]Kind{
+	"keywords": KindKeywords,
+	"regex":    KindRegex,
+	"openai":   KindOpenai,
+	"model":    KindModel,
+}
+
+// ParseKind attempts to convert a string to a Kind.
+func ParseKind(name string) (Kind, error) {
+	if x, ok := _KindValue[name]; ok {
+		return x, nil
+	}
+	return Kind(""), fmt.Errorf("%s is %w", name, ErrInvalidKind)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Kind) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Kind) UnmarshalText(text []byte) error {
+	tmp, err := ParseKind(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// StageInput is a Stage of type input.
+	StageInput Stage = "input"
+	// StageOutput is a Stage of type output.
+	StageOutput Stage = "output"
+)
+
+var ErrInvalidStage = errors.New("not a valid Stage")
+
+// String implements the Stringer interface.
+func (x Stage) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Stage) IsValid() bool {
+	_, err := ParseStage(string(x))
+	return err == nil
+}
+
+var _StageValue = map[string]Stage{
+	"input":  StageInput,
+	"output": StageOutput,
+}
+
+// ParseStage attempts to convert a string to a Stage.
+func ParseStage(name string) (Stage, error) {
+	if x, ok := _StageValue[name]; ok {
+		return x, nil
+	}
+	return Stage(""), fmt.Errorf("%s is %w", name, ErrInvalidStage)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Stage) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Stage) UnmarshalText(text []byte) error {
+	tmp, err := ParseStage(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
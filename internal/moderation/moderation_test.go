@@ -0,0 +1,65 @@
+package moderation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/moderation"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+type answer string
+
+func (a answer) Invoke(context.Context, types.Config, []types.Message, []types.ToolDefinition) (*types.Invoke, error) {
+	return &types.Invoke{Output: []types.Message{{Role: types.RoleAssistant, Parts: []types.Content{types.Text(string(a))}}}}, nil
+}
+
+func TestCheckers_Check(t *testing.T) {
+	ctx := context.Background()
+	checkers, err := moderation.NewCheckers(moderation.StageInput, []moderation.CheckerConfig{
+		{Type: moderation.KindKeywords, Keywords: []string{"Forbidden Word"}},
+		{Type: moderation.KindRegex, Patterns: []string{`\b\d{16}\b`}},
+	}, nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, checkers.Check(ctx, "hello world"))
+
+	err = checkers.Check(ctx, "this is a FORBIDDEN word")
+	require.ErrorIs(t, err, moderation.ErrBlocked)
+	var blocked *moderation.BlockedError
+	require.True(t, errors.As(err, &blocked))
+	assert.Equal(t, moderation.StageInput, blocked.Stage)
+	assert.Equal(t, moderation.KindKeywords, blocked.Checker)
+
+	err = checkers.Check(ctx, "card 1234567812345678")
+	require.True(t, errors.As(err, &blocked))
+	assert.Equal(t, moderation.KindRegex, blocked.Checker)
+}
+
+func TestClassifier(t *testing.T) {
+	ctx := context.Background()
+	config := moderation.CheckerConfig{Type: moderation.KindModel, Model: "classifier"}
+
+	safe, err := moderation.NewChecker(config, answer("SAFE"), nil)
+	require.NoError(t, err)
+	reason, err := safe.Check(ctx, "hello")
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	unsafe, err := moderation.NewChecker(config, answer("UNSAFE: harassment"), nil)
+	require.NoError(t, err)
+	reason, err = unsafe.Check(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "harassment", reason)
+}
+
+func TestNewChecker_Invalid(t *testing.T) {
+	_, err := moderation.NewChecker(moderation.CheckerConfig{Type: moderation.KindRegex, Patterns: []string{"("}}, nil, nil)
+	require.Error(t, err)
+	_, err = moderation.NewChecker(moderation.CheckerConfig{Type: moderation.KindOpenai}, nil, nil)
+	require.Error(t, err)
+}
@@ -5,30 +5,79 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
 	types2 "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/pikocloud/pikobrain/internal/providers/types"
 )
 
 var ErrUnknownBlockType = errors.New("unknown block type")
 
-func New(ctx context.Context) (*Bedrock, error) {
-	sdkConfig, err := config.LoadDefaultConfig(ctx)
+// Config for AWS Bedrock. All fields are optional, by default AWS config chain is used (environment variables, shared config, ...).
+type Config struct {
+	Region     string     `json:"region,omitempty" yaml:"region,omitempty"`          // AWS region
+	Profile    string     `json:"profile,omitempty" yaml:"profile,omitempty"`        // named profile from shared config
+	RoleARN    string     `json:"role_arn,omitempty" yaml:"roleARN,omitempty"`       // role to assume (ex: cross-account access)
+	ExternalID string     `json:"external_id,omitempty" yaml:"externalID,omitempty"` // external ID for role assumption
+	Endpoint   string     `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`      // custom endpoint URL (ex: local stub)
+	Guardrail  *Guardrail `json:"guardrail,omitempty" yaml:"guardrail,omitempty"`    // Bedrock Guardrails
+}
+
+// Guardrail configuration for Bedrock.
+type Guardrail struct {
+	ID      string `json:"id" yaml:"id"`           // guardrail identifier
+	Version string `json:"version" yaml:"version"` // guardrail version (ex: DRAFT or 1)
+	Trace   bool   `json:"trace" yaml:"trace"`     // enable guardrail trace
+}
+
+// New Bedrock provider based on AWS config chain and provided config. If client is nil, default HTTP client is used.
+func New(ctx context.Context, client *http.Client, cfg Config) (*Bedrock, error) {
+	var opts []func(*config.LoadOptions) error
+	if client != nil {
+		opts = append(opts, config.WithHTTPClient(client))
+	}
+	if cfg.Region != "" {
+		opts = append(opts, config.WithRegion(cfg.Region))
+	}
+	if cfg.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(cfg.Profile))
+	}
+	sdkConfig, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("load default AWS config: %w", err)
 	}
-	bedrockClient := bedrockruntime.NewFromConfig(sdkConfig)
 
-	return &Bedrock{client: bedrockClient}, nil
+	if cfg.RoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(sdkConfig), cfg.RoleARN, func(options *stscreds.AssumeRoleOptions) {
+			if cfg.ExternalID != "" {
+				options.ExternalID = aws.String(cfg.ExternalID)
+			}
+		})
+		sdkConfig.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	bedrockClient := bedrockruntime.NewFromConfig(sdkConfig, func(options *bedrockruntime.Options) {
+		if cfg.Endpoint != "" {
+			options.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	})
+
+	return &Bedrock{client: bedrockClient, guardrail: cfg.Guardrail}, nil
 }
 
 type Bedrock struct {
-	client *bedrockruntime.Client
+	client    *bedrockruntime.Client
+	guardrail *Guardrail
 }
 
 func (bed *Bedrock) Invoke(ctx context.Context, config types.Config, messages []types.Message, tools []types.ToolDefinition) (*types.Invoke, error) {
@@ -40,54 +89,93 @@ func (bed *Bedrock) Invoke(ctx context.Context, config types.Config, messages []
 		},
 	}
 
+	if bed.guardrail != nil {
+		var trace = types2.GuardrailTraceDisabled
+		if bed.guardrail.Trace {
+			trace = types2.GuardrailTraceEnabled
+		}
+		input.GuardrailConfig = &types2.GuardrailConfiguration{
+			GuardrailIdentifier: aws.String(bed.guardrail.ID),
+			GuardrailVersion:    aws.String(bed.guardrail.Version),
+			Trace:               trace,
+		}
+	}
+
+	if config.ReasoningBudget > 0 {
+		// extended thinking (Claude) is not part of Converse API yet
+		input.AdditionalModelRequestFields = document.NewLazyDocument(map[string]any{
+			"thinking": map[string]any{
+				"type":          "enabled",
+				"budget_tokens": config.ReasoningBudget,
+			},
+		})
+	}
+
 	if config.Prompt != "" {
 		input.System = []types2.SystemContentBlock{
 			&types2.SystemContentBlockMemberText{Value: config.Prompt},
 		}
+		if config.Cache {
+			input.System = append(input.System, &types2.SystemContentBlockMemberCachePoint{
+				Value: types2.CachePointBlock{Type: types2.CachePointTypeDefault},
+			})
+		}
 	}
 	// convert input
 	var mappedMessages []types2.Message
 	for _, msg := range messages {
 		var role types2.ConversationRole
 		switch msg.Role {
-		case types.RoleAssistant, types.RoleToolCall:
+		case types.RoleAssistant, types.RoleToolCall, types.RoleReasoning:
 			role = types2.ConversationRoleAssistant
 		case types.RoleUser, types.RoleToolResult:
 			role = types2.ConversationRoleUser
 		}
 
-		var ct types2.ContentBlock
+		var ct []types2.ContentBlock
 
 		switch msg.Role {
 
 		case types.RoleUser, types.RoleAssistant:
-			value, err := mapUserBlock(msg.Content)
-			if err != nil {
-				return nil, fmt.Errorf("map data content: %w", err)
+			for _, part := range msg.Parts {
+				value, err := mapUserBlock(part)
+				if err != nil {
+					return nil, fmt.Errorf("map data content: %w", err)
+				}
+				ct = append(ct, value)
 			}
-			ct = value
 		case types.RoleToolCall:
 			var pd any
-			if err := json.Unmarshal(msg.Content.Data, &pd); err != nil {
+			if err := json.Unmarshal(msg.Content().Data, &pd); err != nil {
 				return nil, fmt.Errorf("unmarshal tool use content: %w", err)
 			}
-			ct = &types2.ContentBlockMemberToolUse{
+			ct = append(ct, &types2.ContentBlockMemberToolUse{
 				Value: types2.ToolUseBlock{
 					Input:     document.NewLazyDocument(pd),
 					Name:      aws.String(msg.ToolName),
 					ToolUseId: aws.String(msg.ToolID),
 				},
-			}
+			})
+		case types.RoleReasoning:
+			// thinking must be returned together with tool use while continuing the turn
+			ct = append(ct, &types2.ContentBlockMemberReasoningContent{
+				Value: &types2.ReasoningContentBlockMemberReasoningText{
+					Value: types2.ReasoningTextBlock{
+						Text:      aws.String(msg.Text()),
+						Signature: aws.String(msg.Signature),
+					},
+				},
+			})
 		case types.RoleToolResult:
-			value, err := mapToolResult(msg.ToolID, msg.Content)
+			value, err := mapToolResult(msg.ToolID, msg.Content())
 			if err != nil {
 				return nil, fmt.Errorf("map result content: %w", err)
 			}
-			ct = value
+			ct = append(ct, value)
 		}
 
 		mappedMessages = append(mappedMessages, types2.Message{
-			Content: []types2.ContentBlock{ct},
+			Content: ct,
 			Role:    role,
 		})
 
@@ -142,6 +230,12 @@ func (bed *Bedrock) Invoke(ctx context.Context, config types.Config, messages []
 		})
 	}
 
+	if config.Cache && len(input.ToolConfig.Tools) > 0 {
+		input.ToolConfig.Tools = append(input.ToolConfig.Tools, &types2.ToolMemberCachePoint{
+			Value: types2.CachePointBlock{Type: types2.CachePointTypeDefault},
+		})
+	}
+
 	// call
 	resp, err := bed.client.Converse(ctx, input)
 	if err != nil {
@@ -153,11 +247,15 @@ func (bed *Bedrock) Invoke(ctx context.Context, config types.Config, messages []
 	if err != nil {
 		return nil, fmt.Errorf("map output: %w", err)
 	}
+	// input tokens do not include cache (same as in Anthropic API)
+	cacheRead := int(aws.ToInt32(resp.Usage.CacheReadInputTokens))
+	cacheWrite := int(aws.ToInt32(resp.Usage.CacheWriteInputTokens))
 	return &types.Invoke{
 		Output:      output,
 		TotalToken:  int(*resp.Usage.TotalTokens),
-		InputToken:  int(*resp.Usage.InputTokens),
+		InputToken:  int(*resp.Usage.InputTokens) + cacheRead + cacheWrite,
 		OutputToken: int(*resp.Usage.OutputTokens),
+		CachedToken: cacheRead,
 	}, nil
 }
 
@@ -167,7 +265,16 @@ func mapOutput(resp *bedrockruntime.ConverseOutput) ([]types.Message, error) {
 		if v == nil {
 			return nil, nil
 		}
-		var ct = make([]types.Message, 0, len(v.Value.Content))
+		var (
+			ct    = make([]types.Message, 0, len(v.Value.Content))
+			parts []types.Content // consecutive text and images are single assistant message
+		)
+		flush := func() {
+			if len(parts) > 0 {
+				ct = append(ct, types.Message{Role: types.RoleAssistant, Parts: parts})
+				parts = nil
+			}
+		}
 		for _, block := range v.Value.Content {
 			switch item := block.(type) {
 			case *types2.ContentBlockMemberToolUse:
@@ -175,30 +282,37 @@ func mapOutput(resp *bedrockruntime.ConverseOutput) ([]types.Message, error) {
 				if err != nil {
 					return nil, fmt.Errorf("unmarshal input for tool %q: %w", *item.Value.Name, err)
 				}
+				flush()
 				ct = append(ct, types.Message{
 					Role:     types.RoleToolCall,
 					ToolName: *item.Value.Name,
 					ToolID:   *item.Value.ToolUseId,
-					Content: types.Content{
+					Parts: []types.Content{{
 						Data: payload,
 						Mime: types.MIMEJson,
-					},
+					}},
 				})
-			case *types2.ContentBlockMemberText:
+			case *types2.ContentBlockMemberReasoningContent:
+				text, ok := item.Value.(*types2.ReasoningContentBlockMemberReasoningText)
+				if !ok {
+					continue // redacted reasoning can not be shown
+				}
+				flush()
 				ct = append(ct, types.Message{
-					Role:    types.RoleAssistant,
-					Content: types.Text(item.Value),
+					Role:      types.RoleReasoning,
+					Parts:     []types.Content{types.Text(aws.ToString(text.Value.Text))},
+					Signature: aws.ToString(text.Value.Signature),
 				})
+			case *types2.ContentBlockMemberText:
+				parts = append(parts, types.Text(item.Value))
 			case *types2.ContentBlockMemberImage:
-				ct = append(ct, types.Message{
-					Role: types.RoleAssistant,
-					Content: types.Content{
-						Data: item.Value.Source.(*types2.ImageSourceMemberBytes).Value,
-						Mime: types.MIME("image/" + item.Value.Format),
-					},
+				parts = append(parts, types.Content{
+					Data: item.Value.Source.(*types2.ImageSourceMemberBytes).Value,
+					Mime: types.MIME("image/" + item.Value.Format),
 				})
 			}
 		}
+		flush()
 		return ct, nil
 	default:
 		return nil, fmt.Errorf("%T: %w", v, ErrUnknownBlockType)
@@ -220,6 +334,12 @@ func mapToolResult(id string, content types.Content) (types2.ContentBlock, error
 
 func mapUserBlock(content types.Content) (types2.ContentBlock, error) {
 	switch {
+	case content.Mime.IsDocument():
+		return &types2.ContentBlockMemberDocument{Value: types2.DocumentBlock{
+			Format: types2.DocumentFormat(content.Mime.DocumentFormat()),
+			Name:   aws.String(documentName(content.Name)),
+			Source: &types2.DocumentSourceMemberBytes{Value: content.Data},
+		}}, nil
 	case content.Mime.IsText():
 		return &types2.ContentBlockMemberText{Value: string(content.Data)}, nil
 	case content.Mime.IsImage():
@@ -233,6 +353,25 @@ func mapUserBlock(content types.Content) (types2.ContentBlock, error) {
 	return nil, fmt.Errorf("unknown mime type: %s", content.Mime)
 }
 
+// document name may contain only alphanumeric characters, single spaces, hyphens, parentheses, and square brackets.
+func documentName(fileName string) string {
+	fileName = strings.TrimSuffix(fileName, path.Ext(fileName))
+	var out strings.Builder
+	for _, r := range fileName {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-()[]", r):
+			out.WriteRune(r)
+		case !strings.HasSuffix(out.String(), " "):
+			out.WriteRune(' ')
+		}
+	}
+	name := strings.TrimSpace(out.String())
+	if name == "" {
+		return "document"
+	}
+	return name
+}
+
 func mapResult(content types.Content) (types2.ToolResultContentBlock, error) {
 	switch {
 	case content.Mime == types.MIMEJson:
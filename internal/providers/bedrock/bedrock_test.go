@@ -0,0 +1,164 @@
+package bedrock_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/bedrock"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+const assumeRoleResponse = `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASSUMED</AccessKeyId>
+      <SecretAccessKey>assumed-secret</SecretAccessKey>
+      <SessionToken>assumed-token</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/bedrock/test</Arn>
+      <AssumedRoleId>ID:test</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+  <ResponseMetadata><RequestId>1</RequestId></ResponseMetadata>
+</AssumeRoleResponse>`
+
+// stubAWS records STS and Bedrock Converse requests.
+type stubAWS struct {
+	lock       sync.Mutex
+	assumeRole url.Values
+	converse   map[string]any
+	auth       string
+	path       string
+}
+
+func (s *stubAWS) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if request.URL.Path == "/" {
+		s.assumeRole, _ = url.ParseQuery(string(data))
+		writer.Header().Set("Content-Type", "text/xml")
+		_, _ = io.WriteString(writer, assumeRoleResponse)
+		return
+	}
+	s.path = request.URL.Path
+	s.auth = request.Header.Get("Authorization")
+	s.converse = nil
+	_ = json.Unmarshal(data, &s.converse)
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = io.WriteString(writer, `{"output": {"message": {"role": "assistant", "content": [{"text": "ok"}]}},
+		"stopReason": "end_turn", "usage": {"inputTokens": 1, "outputTokens": 1, "totalTokens": 2}, "metrics": {"latencyMs": 1}}`)
+}
+
+// isolatedAWS environment: no shared config, static credentials and stub endpoint for STS.
+func isolatedAWS(t *testing.T, stub *httptest.Server) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_DEFAULT_PROFILE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_ROLE_ARN", "")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	t.Setenv("AWS_ENDPOINT_URL_STS", stub.URL)
+}
+
+type stubTool struct{}
+
+func (stubTool) Name() string        { return "echo" }
+func (stubTool) Description() string { return "Echo input" }
+func (stubTool) Input() *jsonschema.Schema {
+	return &jsonschema.Schema{Type: "object", Properties: jsonschema.NewProperties()}
+}
+
+func invoke(t *testing.T, cfg bedrock.Config) {
+	t.Helper()
+	provider, err := bedrock.New(context.Background(), nil, cfg)
+	require.NoError(t, err)
+	out, err := provider.Invoke(context.Background(), types.Config{Model: "anthropic.claude-3-haiku", MaxTokens: 10}, []types.Message{
+		{Role: types.RoleUser, Parts: []types.Content{types.Text("hello")}},
+	}, []types.ToolDefinition{stubTool{}})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out.Output[0].Text())
+}
+
+func TestNew_config(t *testing.T) {
+	stub := &stubAWS{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	t.Run("region and endpoint", func(t *testing.T) {
+		isolatedAWS(t, srv)
+		invoke(t, bedrock.Config{Region: "eu-central-1", Endpoint: srv.URL})
+
+		assert.Equal(t, "/model/anthropic.claude-3-haiku/converse", stub.path)
+		assert.Contains(t, stub.auth, "Credential=ENV/")
+		assert.Contains(t, stub.auth, "/eu-central-1/bedrock/aws4_request")
+		assert.NotContains(t, stub.converse, "guardrailConfig")
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		isolatedAWS(t, srv)
+		t.Setenv("AWS_ACCESS_KEY_ID", "")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+		t.Setenv("AWS_REGION", "")
+		require.NoError(t, os.WriteFile(os.Getenv("AWS_CONFIG_FILE"), []byte("[profile brain]\nregion = ap-south-1\n"), 0600))
+		require.NoError(t, os.WriteFile(os.Getenv("AWS_SHARED_CREDENTIALS_FILE"), []byte("[brain]\naws_access_key_id = PROFILE\naws_secret_access_key = profile-secret\n"), 0600))
+		invoke(t, bedrock.Config{Profile: "brain", Endpoint: srv.URL})
+
+		assert.Contains(t, stub.auth, "Credential=PROFILE/")
+		assert.Contains(t, stub.auth, "/ap-south-1/bedrock/aws4_request")
+
+		_, err := bedrock.New(context.Background(), nil, bedrock.Config{Profile: "missing"})
+		require.Error(t, err)
+	})
+
+	t.Run("assume role", func(t *testing.T) {
+		isolatedAWS(t, srv)
+		invoke(t, bedrock.Config{RoleARN: "arn:aws:iam::123456789012:role/bedrock", ExternalID: "tenant-42", Endpoint: srv.URL})
+
+		require.NotNil(t, stub.assumeRole)
+		assert.Equal(t, "AssumeRole", stub.assumeRole.Get("Action"))
+		assert.Equal(t, "arn:aws:iam::123456789012:role/bedrock", stub.assumeRole.Get("RoleArn"))
+		assert.Equal(t, "tenant-42", stub.assumeRole.Get("ExternalId"))
+		// bedrock is called with assumed credentials
+		assert.Contains(t, stub.auth, "Credential=ASSUMED/")
+	})
+
+	t.Run("guardrail", func(t *testing.T) {
+		isolatedAWS(t, srv)
+		invoke(t, bedrock.Config{Endpoint: srv.URL, Guardrail: &bedrock.Guardrail{ID: "gr-1", Version: "DRAFT", Trace: true}})
+
+		guardrail, ok := stub.converse["guardrailConfig"].(map[string]any)
+		require.True(t, ok, "guardrail config is sent")
+		assert.Equal(t, "gr-1", guardrail["guardrailIdentifier"])
+		assert.Equal(t, "DRAFT", guardrail["guardrailVersion"])
+		assert.Equal(t, "enabled", strings.ToLower(guardrail["trace"].(string)))
+	})
+}
@@ -0,0 +1,40 @@
+package bedrock
+
+import (
+	"strings"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Capable = &Bedrock{}
+
+// known models. Forced JSON is not supported by Bedrock at all.
+// Unknown models are assumed to support vision and tools, so content is not dropped silently.
+var registry = types.CapabilityRegistry{
+	{Prefix: "anthropic.claude-3-7", Capabilities: types.Capabilities{Vision: true, Tools: true, Documents: true, Reasoning: true, Cache: true, MaxContext: 200000}},
+	{Prefix: "anthropic.claude-sonnet-4", Capabilities: types.Capabilities{Vision: true, Tools: true, Documents: true, Reasoning: true, Cache: true, MaxContext: 200000}},
+	{Prefix: "anthropic.claude-opus-4", Capabilities: types.Capabilities{Vision: true, Tools: true, Documents: true, Reasoning: true, Cache: true, MaxContext: 200000}},
+	{Prefix: "anthropic.claude-3-5-haiku", Capabilities: types.Capabilities{Tools: true, Documents: true, Cache: true, MaxContext: 200000}},
+	{Prefix: "anthropic.claude-3", Capabilities: types.Capabilities{Vision: true, Tools: true, Documents: true, MaxContext: 200000}},
+	{Prefix: "anthropic.claude", Capabilities: types.Capabilities{MaxContext: 100000}},
+	{Prefix: "mistral.mistral-large", Capabilities: types.Capabilities{Tools: true, MaxContext: 32000}},
+	{Prefix: "mistral.mistral-small", Capabilities: types.Capabilities{Tools: true, MaxContext: 32000}},
+	{Prefix: "mistral.", Capabilities: types.Capabilities{MaxContext: 32000}},
+	{Prefix: "cohere.command-r", Capabilities: types.Capabilities{Tools: true, MaxContext: 128000}},
+	{Prefix: "cohere.command", Capabilities: types.Capabilities{MaxContext: 4000}},
+	{Prefix: "meta.llama3-1", Capabilities: types.Capabilities{Tools: true, MaxContext: 128000}},
+	{Prefix: "meta.llama", Capabilities: types.Capabilities{MaxContext: 8000}},
+	{Prefix: "amazon.titan-text", Capabilities: types.Capabilities{MaxContext: 8000}},
+	{Prefix: "amazon.nova-micro", Capabilities: types.Capabilities{Tools: true, Cache: true, MaxContext: 128000}},
+	{Prefix: "amazon.nova", Capabilities: types.Capabilities{Vision: true, Tools: true, Documents: true, Cache: true, MaxContext: 300000}},
+}
+
+var crossRegionPrefixes = []string{"us.", "eu.", "apac."}
+
+func (bed *Bedrock) Capabilities(model string) types.Capabilities {
+	// cross-region inference profiles are prefixed by region group
+	for _, prefix := range crossRegionPrefixes {
+		model = strings.TrimPrefix(model, prefix)
+	}
+	return registry.Find(model, types.Capabilities{Vision: true, Tools: true})
+}
@@ -0,0 +1,32 @@
+package bedrock_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pikocloud/pikobrain/internal/providers/bedrock"
+)
+
+func TestBedrock_Capabilities(t *testing.T) {
+	var provider bedrock.Bedrock
+
+	caps := provider.Capabilities("us.anthropic.claude-3-5-sonnet-20240620-v1:0")
+	assert.True(t, caps.Vision)
+	assert.True(t, caps.Tools)
+	assert.Equal(t, 200000, caps.MaxContext)
+
+	caps = provider.Capabilities("amazon.nova-pro-v1:0")
+	assert.True(t, caps.Vision)
+	assert.True(t, caps.Tools)
+
+	caps = provider.Capabilities("amazon.nova-micro-v1:0")
+	assert.False(t, caps.Vision)
+	assert.True(t, caps.Tools)
+
+	// unknown models keep tools and images
+	caps = provider.Capabilities("writer.palmyra-x5-v1:0")
+	assert.True(t, caps.Vision)
+	assert.True(t, caps.Tools)
+	assert.False(t, caps.JSON)
+}
@@ -0,0 +1,80 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Embedder = &Bedrock{}
+
+// Embed input texts. Supported models are Amazon Titan Embeddings and Cohere Embed.
+func (bed *Bedrock) Embed(ctx context.Context, model string, input []string) (*types.Embeddings, error) {
+	if strings.HasPrefix(model, "cohere.") {
+		return bed.embedCohere(ctx, model, input)
+	}
+	return bed.embedTitan(ctx, model, input)
+}
+
+// Titan accepts only single text per request.
+func (bed *Bedrock) embedTitan(ctx context.Context, model string, input []string) (*types.Embeddings, error) {
+	var out = &types.Embeddings{
+		Vectors: make([][]float32, 0, len(input)),
+	}
+	for i, text := range input {
+		var res struct {
+			Embedding           []float32 `json:"embedding"`
+			InputTextTokenCount int       `json:"inputTextTokenCount"`
+		}
+		if err := bed.invokeJSON(ctx, model, map[string]any{"inputText": text}, &res); err != nil {
+			return nil, fmt.Errorf("embed text #%d: %w", i, err)
+		}
+		out.Vectors = append(out.Vectors, res.Embedding)
+		out.InputToken += res.InputTextTokenCount
+		out.TotalToken += res.InputTextTokenCount
+	}
+	return out, nil
+}
+
+// Cohere doesn't report token usage in response.
+func (bed *Bedrock) embedCohere(ctx context.Context, model string, input []string) (*types.Embeddings, error) {
+	var res struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	err := bed.invokeJSON(ctx, model, map[string]any{
+		"texts":      input,
+		"input_type": "search_document",
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Embeddings{
+		Vectors: res.Embeddings,
+	}, nil
+}
+
+func (bed *Bedrock) invokeJSON(ctx context.Context, model string, request any, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	res, err := bed.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(model),
+		Body:        body,
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("invoke model: %w", err)
+	}
+	if err := json.Unmarshal(res.Body, response); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package google
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	cacheTTL    = 10 * time.Minute // lifetime of cached content in Gemini
+	cacheMargin = time.Minute      // cached content is recreated before expiration to avoid using expired one
+)
+
+type cacheEntry struct {
+	name    string // empty if content can not be cached (ex: below minimal size)
+	expires time.Time
+}
+
+// contentCache keeps Gemini cached contents for stable prefixes (system instruction and tools).
+type contentCache struct {
+	lock    sync.Mutex
+	entries map[string]cacheEntry
+	group   singleflight.Group // concurrent requests with the same prefix create content once
+	now     func() time.Time   // clock, time.Now if not set
+}
+
+// cachedContent returns name of cached content for system instruction and tools. Content is created if needed.
+// Empty name returned if content can not be cached, so request should be sent without cache.
+func (srv *Google) cachedContent(ctx context.Context, model string, system *genai.Content, tools []*genai.Tool) string {
+	key, err := cacheKey(model, system, tools)
+	if err != nil {
+		slog.Warn("failed to calculate cache key", "model", model, "error", err)
+		return ""
+	}
+	return srv.cache.get(ctx, key, func(ctx context.Context) (string, error) {
+		cc, err := srv.client.CreateCachedContent(ctx, &genai.CachedContent{
+			Model:             model,
+			SystemInstruction: system,
+			Tools:             tools,
+			Expiration:        genai.ExpireTimeOrTTL{TTL: cacheTTL},
+		})
+		if err != nil {
+			return "", err
+		}
+		slog.Debug("cached content created", "model", model, "name", cc.Name)
+		return cc.Name, nil
+	})
+}
+
+// get name of cached content by key. Content is created by the function without holding the lock.
+// Failures are remembered till TTL (content will not be cached anyway), except context errors.
+func (cache *contentCache) get(ctx context.Context, key string, create func(ctx context.Context) (string, error)) string {
+	if name, ok := cache.lookup(key); ok {
+		return name
+	}
+	name, _, _ := cache.group.Do(key, func() (any, error) {
+		if name, ok := cache.lookup(key); ok { // created by concurrent request
+			return name, nil
+		}
+		name, err := create(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				slog.Debug("cached content is not created", "error", err)
+				return "", nil
+			}
+			// too small content or model without caching support - do not retry till TTL
+			slog.Warn("failed to create cached content, request will be sent without cache", "error", err)
+		}
+		cache.store(key, name)
+		return name, nil
+	})
+	return name.(string)
+}
+
+// lookup not expiring entry and remove expired ones.
+func (cache *contentCache) lookup(key string) (string, bool) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	now := cache.clock()
+	for k, entry := range cache.entries {
+		if now.After(entry.expires) {
+			delete(cache.entries, k)
+		}
+	}
+	entry, ok := cache.entries[key]
+	if !ok || entry.expires.Sub(now) <= cacheMargin {
+		return "", false
+	}
+	return entry.name, true
+}
+
+func (cache *contentCache) store(key string, name string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if cache.entries == nil {
+		cache.entries = make(map[string]cacheEntry)
+	}
+	cache.entries[key] = cacheEntry{name: name, expires: cache.clock().Add(cacheTTL)}
+}
+
+func (cache *contentCache) clock() time.Time {
+	if cache.now != nil {
+		return cache.now()
+	}
+	return time.Now()
+}
+
+func cacheKey(model string, system *genai.Content, tools []*genai.Tool) (string, error) {
+	data, err := json.Marshal([]any{model, system, tools})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
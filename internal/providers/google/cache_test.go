@@ -0,0 +1,119 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKey(t *testing.T) {
+	system := &genai.Content{Parts: []genai.Part{genai.Text("You are the helpful assistant")}}
+	tools := []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "get_weather"}}}}
+
+	key, err := cacheKey("gemini-1.5-flash-002", system, tools)
+	require.NoError(t, err)
+	same, err := cacheKey("gemini-1.5-flash-002", system, tools)
+	require.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	otherModel, err := cacheKey("gemini-1.5-pro-002", system, tools)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, otherModel)
+
+	otherTools, err := cacheKey("gemini-1.5-flash-002", system, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, otherTools)
+}
+
+func TestContentCache_expiration(t *testing.T) {
+	now := time.Now()
+	cache := &contentCache{now: func() time.Time { return now }}
+	var created int
+	create := func(ctx context.Context) (string, error) {
+		created++
+		return fmt.Sprint("cachedContents/", created), nil
+	}
+	ctx := context.Background()
+
+	assert.Equal(t, "cachedContents/1", cache.get(ctx, "key", create))
+	now = now.Add(cacheTTL - cacheMargin - time.Second)
+	assert.Equal(t, "cachedContents/1", cache.get(ctx, "key", create))
+	assert.Equal(t, 1, created)
+
+	// recreated before expiration
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, "cachedContents/2", cache.get(ctx, "key", create))
+	assert.Equal(t, 2, created)
+
+	// expired entries are removed
+	now = now.Add(cacheTTL + time.Second)
+	assert.Equal(t, "cachedContents/3", cache.get(ctx, "other", create))
+	assert.Len(t, cache.entries, 1)
+}
+
+func TestContentCache_errors(t *testing.T) {
+	cache := &contentCache{}
+	var calls int
+	failed := func(ctx context.Context) (string, error) {
+		calls++
+		return "", errors.New("content is too small")
+	}
+	assert.Empty(t, cache.get(context.Background(), "small", failed))
+	assert.Empty(t, cache.get(context.Background(), "small", failed))
+	assert.Equal(t, 1, calls, "failure should be remembered")
+
+	// canceled request should not disable cache
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceled := func(ctx context.Context) (string, error) {
+		return "", ctx.Err()
+	}
+	assert.Empty(t, cache.get(ctx, "key", canceled))
+	assert.Equal(t, "cachedContents/ok", cache.get(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "cachedContents/ok", nil
+	}))
+}
+
+func TestContentCache_concurrent(t *testing.T) {
+	cache := &contentCache{}
+	var (
+		created atomic.Int32
+		release = make(chan struct{})
+	)
+	slow := func(ctx context.Context) (string, error) {
+		created.Add(1)
+		<-release
+		return "cachedContents/slow", nil
+	}
+
+	var wg sync.WaitGroup
+	var names = make([]string, 5)
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			names[i] = cache.get(context.Background(), "slow", slow)
+		}()
+	}
+
+	// other keys are not blocked while content is created
+	require.Eventually(t, func() bool { return created.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, "cachedContents/fast", cache.get(context.Background(), "fast", func(ctx context.Context) (string, error) {
+		return "cachedContents/fast", nil
+	}))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), created.Load())
+	for _, name := range names {
+		assert.Equal(t, "cachedContents/slow", name)
+	}
+}
@@ -0,0 +1,21 @@
+package google
+
+import (
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Capable = &Google{}
+
+// known models. Unknown models are assumed to support everything (except explicit caching).
+var registry = types.CapabilityRegistry{
+	{Prefix: "gemini-1.5-pro", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, Documents: true, Cache: true, MaxContext: 2097152}},
+	{Prefix: "gemini-1.5-flash", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, Documents: true, Cache: true, MaxContext: 1048576}},
+	{Prefix: "gemini-1.0-pro-vision", Capabilities: types.Capabilities{Vision: true, MaxContext: 12288}},
+	{Prefix: "gemini-pro-vision", Capabilities: types.Capabilities{Vision: true, MaxContext: 12288}},
+	{Prefix: "gemini-1.0-pro", Capabilities: types.Capabilities{Tools: true, MaxContext: 30720}},
+	{Prefix: "gemini-pro", Capabilities: types.Capabilities{Tools: true, MaxContext: 30720}},
+}
+
+func (srv *Google) Capabilities(model string) types.Capabilities {
+	return registry.Find(model, types.AllCapabilities)
+}
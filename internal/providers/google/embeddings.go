@@ -0,0 +1,32 @@
+package google
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Embedder = &Google{}
+
+// Embed input texts. Google doesn't report token usage for embeddings.
+func (srv *Google) Embed(ctx context.Context, model string, input []string) (*types.Embeddings, error) {
+	em := srv.client.EmbeddingModel(model)
+	batch := em.NewBatch()
+	for _, text := range input {
+		batch.AddContent(genai.Text(text))
+	}
+	res, err := em.BatchEmbedContents(ctx, batch)
+	if err != nil {
+		return nil, fmt.Errorf("batch embed contents: %w", err)
+	}
+	var vectors = make([][]float32, 0, len(res.Embeddings))
+	for _, item := range res.Embeddings {
+		vectors = append(vectors, item.Values)
+	}
+	return &types.Embeddings{
+		Vectors: vectors,
+	}, nil
+}
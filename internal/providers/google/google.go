@@ -1,29 +1,42 @@
 package google
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"slices"
 
 	"github.com/google/generative-ai-go/genai"
 	"github.com/invopop/jsonschema"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
 	"google.golang.org/api/option"
 
 	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/utils"
 )
 
-func New(ctx context.Context, token string) (*Google, error) {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(token))
+// New Google (Gemini) provider. If httpClient is nil, default HTTP client is used.
+func New(ctx context.Context, token string, httpClient *http.Client) (*Google, error) {
+	var opt = option.WithAPIKey(token)
+	if httpClient != nil {
+		// API key is ignored by SDK if custom HTTP client is set
+		opt = option.WithHTTPClient(utils.WithHeaders(httpClient, http.Header{
+			"X-Goog-Api-Key": {token},
+		}))
+	}
+	client, err := genai.NewClient(ctx, opt)
 	if err != nil {
 		return nil, fmt.Errorf("create client: %w", err)
 	}
-	return &Google{client}, nil
+	return &Google{client: client}, nil
 }
 
 type Google struct {
 	client *genai.Client
+	cache  contentCache
 }
 
 func (srv *Google) Invoke(ctx context.Context, config types.Config, messages []types.Message, tools []types.ToolDefinition) (*types.Invoke, error) {
@@ -36,8 +49,9 @@ func (srv *Google) Invoke(ctx context.Context, config types.Config, messages []t
 	if config.ForceJSON {
 		model.GenerationConfig.ResponseMIMEType = "application/json"
 	}
+	var system *genai.Content
 	if config.Prompt != "" {
-		model.SystemInstruction = &genai.Content{
+		system = &genai.Content{
 			Parts: []genai.Part{
 				genai.Text(config.Prompt),
 			},
@@ -53,36 +67,35 @@ func (srv *Google) Invoke(ctx context.Context, config types.Config, messages []t
 		})
 	}
 
+	var genTools []*genai.Tool
 	if len(funcDefs) > 0 {
-		model.Tools = append(model.Tools, &genai.Tool{
+		genTools = append(genTools, &genai.Tool{
 			FunctionDeclarations: funcDefs,
 		})
 	}
 
+	// cached content already contains system instruction and tools, API rejects them in request
+	if config.Cache && (system != nil || len(genTools) > 0) {
+		if name := srv.cachedContent(ctx, config.Model, system, genTools); name != "" {
+			model.CachedContentName = name
+			system, genTools = nil, nil
+		}
+	}
+	model.SystemInstruction = system
+	model.Tools = genTools
+
 	chat := model.StartChat()
 
 	if len(messages) == 0 {
 		return nil, fmt.Errorf("no messages")
 	}
 
-	for _, msg := range messages {
-		part, err := mapContent(msg)
-		if err != nil {
-			return nil, fmt.Errorf("map content: %w", err)
-		}
-		var role string
-		switch msg.Role {
-		case types.RoleUser:
-			role = "user"
-		case types.RoleAssistant:
-			role = "model"
-		}
-
-		chat.History = append(chat.History, &genai.Content{
-			Parts: []genai.Part{part},
-			Role:  role,
-		})
+	history, err := mapMessages(messages)
+	if err != nil {
+		return nil, fmt.Errorf("map messages: %w", err)
 	}
+	chat.History = history
+
 	last := chat.History[len(chat.History)-1]
 	chat.History = chat.History[:len(chat.History)-1]
 
@@ -93,8 +106,9 @@ func (srv *Google) Invoke(ctx context.Context, config types.Config, messages []t
 
 	var out = &types.Invoke{
 		InputToken:  int(result.UsageMetadata.PromptTokenCount),
-		OutputToken: int(result.UsageMetadata.CandidatesTokenCount + result.UsageMetadata.CachedContentTokenCount),
+		OutputToken: int(result.UsageMetadata.CandidatesTokenCount),
 		TotalToken:  int(result.UsageMetadata.TotalTokenCount),
+		CachedToken: int(result.UsageMetadata.CachedContentTokenCount),
 	}
 
 	if len(result.Candidates) == 0 {
@@ -108,7 +122,16 @@ func (srv *Google) Invoke(ctx context.Context, config types.Config, messages []t
 		return out, nil
 	}
 
-	var output []types.Message
+	var (
+		output []types.Message
+		parts  []types.Content // consecutive text and blobs are single assistant message
+	)
+	flush := func() {
+		if len(parts) > 0 {
+			output = append(output, types.Message{Role: types.RoleAssistant, Parts: parts})
+			parts = nil
+		}
+	}
 	for _, part := range candidate.Content.Parts {
 		switch v := part.(type) {
 		case genai.FunctionCall:
@@ -116,88 +139,134 @@ func (srv *Google) Invoke(ctx context.Context, config types.Config, messages []t
 			if err != nil {
 				return nil, fmt.Errorf("marshal call part: %w", err)
 			}
+			flush()
+			// Gemini doesn't provide call ID, so we have to generate unique one
 			output = append(output, types.Message{
+				ToolID:   utils.RandomID("call_"),
 				ToolName: v.Name,
 				Role:     types.RoleToolCall,
-				Content: types.Content{
+				Parts: []types.Content{{
 					Data: data,
 					Mime: types.MIMEJson,
-				},
+				}},
 			})
 		case genai.Text:
-			output = append(output, types.Message{
-				Role: types.RoleAssistant,
-				Content: types.Content{
-					Data: []byte(v),
-					Mime: types.MIMEText,
-				},
-			})
+			parts = append(parts, types.Text(string(v)))
 		case genai.Blob:
 			tp, err := types.ParseMIME(v.MIMEType)
 			if err != nil {
 				return nil, fmt.Errorf("parse mime type: %w", err)
 			}
-			output = append(output, types.Message{
-				Role: types.RoleAssistant,
-				Content: types.Content{
-					Data: v.Data,
-					Mime: tp,
-				},
+			parts = append(parts, types.Content{
+				Data: v.Data,
+				Mime: tp,
 			})
 		}
 	}
+	flush()
 	out.Output = output
 	return out, nil
 }
 
-func mapContent(msg types.Message) (genai.Part, error) {
-	switch {
-	case msg.Role == types.RoleToolCall:
+// Maps messages to contents. Consecutive messages with the same role are merged to single content.
+// Tool calls are sent by model, tool results are sent by user (same as SDK does).
+func mapMessages(messages []types.Message) ([]*genai.Content, error) {
+	var out []*genai.Content
+	for _, msg := range messages {
+		parts, err := mapParts(msg)
+		if err != nil {
+			return nil, fmt.Errorf("map content: %w", err)
+		}
+		role := mapRole(msg.Role)
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Parts = append(out[n-1].Parts, parts...)
+			continue
+		}
+		out = append(out, &genai.Content{
+			Parts: parts,
+			Role:  role,
+		})
+	}
+	return out, nil
+}
+
+func mapRole(role types.Role) string {
+	switch role {
+	case types.RoleAssistant, types.RoleToolCall:
+		return "model"
+	default:
+		return "user"
+	}
+}
+
+func mapParts(msg types.Message) ([]genai.Part, error) {
+	content := msg.Content()
+	switch msg.Role {
+	case types.RoleToolCall:
 		var out map[string]any
-		if err := json.Unmarshal(msg.Content.Data, &out); err != nil {
+		if err := json.Unmarshal(content.Data, &out); err != nil {
 			return nil, fmt.Errorf("unmarshal data: %w", err)
 		}
-		return genai.FunctionCall{
+		return []genai.Part{genai.FunctionCall{
 			Name: msg.ToolName,
 			Args: out,
-		}, nil
-	case msg.Role == types.RoleToolResult:
+		}}, nil
+	case types.RoleToolResult:
 		var out map[string]any
 		// workaround to support most types
 		switch {
-		case msg.Content.Mime == types.MIMEText:
-			out = map[string]any{"content": string(msg.Content.Data)}
-		case msg.Content.Mime == types.MIMEJson:
-			var src = string(msg.Content.Data)
-			if len(msg.Content.Data) > 0 && msg.Content.Data[0] != '{' {
-				src = "{\"content\": " + src + "}"
+		case content.Mime == types.MIMEText:
+			out = map[string]any{"content": string(content.Data)}
+		case content.Mime == types.MIMEJson:
+			var src = bytes.TrimSpace(content.Data)
+			if len(src) > 0 && src[0] != '{' {
+				src = slices.Concat([]byte(`{"content": `), src, []byte("}"))
 			}
-			if err := json.Unmarshal(msg.Content.Data, &out); err != nil {
+			if err := json.Unmarshal(src, &out); err != nil {
 				return nil, fmt.Errorf("unmarshal data: %w", err)
 			}
+		case content.Mime.IsText():
+			out = map[string]any{"content": string(content.Data)}
 		default:
-			return nil, fmt.Errorf("unsupported mime type for function call: %s", msg.Content.Mime)
+			return nil, fmt.Errorf("unsupported mime type for function call: %s", content.Mime)
 		}
-		return genai.FunctionResponse{
+		return []genai.Part{genai.FunctionResponse{
 			Name:     msg.ToolName,
 			Response: out,
-		}, nil
-	case msg.Content.Mime.IsImage():
-		return genai.Blob{
-			MIMEType: msg.Content.Mime.String(),
-			Data:     msg.Content.Data,
-		}, nil
-	case msg.Content.Mime.IsText():
-		return genai.Text(msg.Content.Data), nil
-	default:
-		return nil, fmt.Errorf("unknown mime: %s", msg.Content.Mime)
+		}}, nil
 	}
+
+	var out = make([]genai.Part, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		switch {
+		case part.Mime.IsImage(), part.Mime == types.MIMEPdf: // other documents are plain text
+			out = append(out, genai.Blob{
+				MIMEType: part.Mime.String(),
+				Data:     part.Data,
+			})
+		case part.Mime.IsText():
+			out = append(out, genai.Text(part.Data))
+		default:
+			return nil, fmt.Errorf("unknown mime: %s", part.Mime)
+		}
+	}
+	return out, nil
 }
 
+// Converts JSON schema to Gemini schema, which supports only subset of OpenAPI 3.0.
+// Unsupported constructions are downgraded:
+//   - allOf merged into single schema;
+//   - anyOf/oneOf replaced by the first non-null variant (or merged properties if all variants are objects), null variant makes schema nullable;
+//     enums of the same property in different variants (ex: discriminator) are combined;
+//   - arrays without items (or with unsupported items) are arrays of strings;
+//   - required fields refer only to existing properties.
 func schemaConverter(input *jsonschema.Schema) *genai.Schema {
 	if input == nil {
 		return nil
 	}
+
+	input, nullable := simplifyVariants(types.MergeAllOf(input))
+
 	if input.Type == "object" && input.Properties.Len() == 0 {
 		return nil
 	}
@@ -205,32 +274,114 @@ func schemaConverter(input *jsonschema.Schema) *genai.Schema {
 		Type:        schemaType(input.Type),
 		Format:      input.Format,
 		Description: input.Description,
-		Enum:        schemaEnum(input.Enum),
-		Required:    slices.Clone(input.Required),
+		Nullable:    nullable,
 	}
 
-	if out.Type == genai.TypeObject {
-		out.Properties = make(map[string]*genai.Schema)
-	} else if out.Type == genai.TypeNumber {
+	switch out.Type {
+	case genai.TypeNumber:
 		out.Format = "double" // Google limitations
+	case genai.TypeInteger:
+		if out.Format != "int32" && out.Format != "int64" {
+			out.Format = ""
+		}
+	case genai.TypeString:
+		out.Format = "" // no format supported for string
+		out.Enum = schemaEnum(input.Enum)
+	case genai.TypeArray:
+		out.Items = schemaConverter(input.Items)
+		if out.Items == nil {
+			out.Items = &genai.Schema{Type: genai.TypeString}
+		}
+	case genai.TypeObject:
+		out.Properties = make(map[string]*genai.Schema, input.Properties.Len())
+		for item := input.Properties.Oldest(); item != nil; item = item.Next() {
+			prop := schemaConverter(item.Value)
+			if prop == nil {
+				slog.Debug("property is not supported by Gemini and skipped", "property", item.Key)
+				continue
+			}
+			out.Properties[item.Key] = prop
+		}
+		for _, name := range input.Required {
+			if _, ok := out.Properties[name]; ok {
+				out.Required = append(out.Required, name)
+			}
+		}
 	}
 
-	if out.Type == genai.TypeString {
-		out.Format = "" // no format supported for string
+	return out
+}
+
+// picks single variant from anyOf/oneOf since Gemini doesn't support them.
+func simplifyVariants(input *jsonschema.Schema) (*jsonschema.Schema, bool) {
+	variants := slices.Concat(input.AnyOf, input.OneOf)
+	if len(variants) == 0 {
+		return input, false
 	}
 
-	if n := input.Properties.Len(); n > 0 {
-		if out.Properties == nil {
-			out.Properties = make(map[string]*genai.Schema, n)
+	var (
+		nullable bool
+		options  []*jsonschema.Schema
+	)
+	for _, v := range variants {
+		if v == nil {
+			continue
 		}
-		for item := input.Properties.Oldest(); item != nil; item = item.Next() {
-			out.Properties[item.Key] = schemaConverter(item.Value)
+		if v.Type == "null" {
+			nullable = true
+			continue
 		}
+		options = append(options, types.MergeAllOf(v))
 	}
-	if input.Items != nil {
-		out.Items = schemaConverter(input.Items)
+
+	out := *input
+	out.AnyOf = nil
+	out.OneOf = nil
+
+	switch {
+	case len(options) == 0:
+		// keep as-is
+	case len(options) == 1 || !allObjects(options):
+		selected, subNullable := simplifyVariants(options[0])
+		nullable = nullable || subNullable
+		merged := *selected
+		merged.Description = utils.Concat(". ", input.Description, selected.Description)
+		out = merged
+	default:
+		// merge all properties, but nothing is required since it depends on variant
+		out.Type = "object"
+		out.Properties = orderedmap.New[string, *jsonschema.Schema]()
+		out.Required = nil
+		for _, opt := range options {
+			for item := opt.Properties.Oldest(); item != nil; item = item.Next() {
+				existing, exists := out.Properties.Get(item.Key)
+				if !exists {
+					out.Properties.Set(item.Key, item.Value)
+					continue
+				}
+				if len(existing.Enum) > 0 && len(item.Value.Enum) > 0 {
+					combined := *existing
+					combined.Enum = slices.Clone(existing.Enum)
+					for _, value := range item.Value.Enum {
+						if !slices.Contains(combined.Enum, value) {
+							combined.Enum = append(combined.Enum, value)
+						}
+					}
+					out.Properties.Set(item.Key, &combined)
+				}
+			}
+		}
 	}
-	return out
+	return &out, nullable
+}
+
+func allObjects(list []*jsonschema.Schema) bool {
+	for _, v := range list {
+		if v.Type != "object" || len(v.AnyOf)+len(v.OneOf) > 0 {
+			return false
+		}
+	}
+	return true
 }
 
 func schemaType(input string) genai.Type {
@@ -241,8 +392,10 @@ func schemaType(input string) genai.Type {
 		return genai.TypeArray
 	case "boolean":
 		return genai.TypeBoolean
-	case "number", "integer":
+	case "number":
 		return genai.TypeNumber
+	case "integer":
+		return genai.TypeInteger
 	case "string":
 		return genai.TypeString
 	default:
@@ -251,6 +404,9 @@ func schemaType(input string) genai.Type {
 }
 
 func schemaEnum(input []any) []string {
+	if len(input) == 0 {
+		return nil
+	}
 	var out = make([]string, 0, len(input))
 	for _, item := range input {
 		out = append(out, fmt.Sprint(item))
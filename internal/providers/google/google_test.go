@@ -0,0 +1,170 @@
+package google
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/invopop/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func parseSchema(t *testing.T, src string) *jsonschema.Schema {
+	t.Helper()
+	var schema jsonschema.Schema
+	require.NoError(t, json.Unmarshal([]byte(src), &schema))
+	return &schema
+}
+
+func TestSchemaConverter(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		expect *genai.Schema
+	}{
+		{
+			name:   "empty object",
+			input:  `{"type": "object"}`,
+			expect: nil,
+		},
+		{
+			name:  "scalars",
+			input: `{"type": "object", "required": ["id", "missing"], "properties": {"id": {"type": "integer", "format": "int64"}, "price": {"type": "number", "format": "float"}, "count": {"type": "integer", "format": "uint8"}, "email": {"type": "string", "format": "email"}, "kind": {"type": "string", "enum": ["a", 1]}}}`,
+			expect: &genai.Schema{Type: genai.TypeObject, Required: []string{"id"}, Properties: map[string]*genai.Schema{
+				"id":    {Type: genai.TypeInteger, Format: "int64"},
+				"price": {Type: genai.TypeNumber, Format: "double"},
+				"count": {Type: genai.TypeInteger},
+				"email": {Type: genai.TypeString},
+				"kind":  {Type: genai.TypeString, Enum: []string{"a", "1"}},
+			}},
+		},
+		{
+			name:   "array without items",
+			input:  `{"type": "array", "description": "Tags"}`,
+			expect: &genai.Schema{Type: genai.TypeArray, Description: "Tags", Items: &genai.Schema{Type: genai.TypeString}},
+		},
+		{
+			name:  "empty nested object is skipped",
+			input: `{"type": "object", "required": ["meta"], "properties": {"meta": {"type": "object"}, "name": {"type": "string"}}}`,
+			expect: &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{
+				"name": {Type: genai.TypeString},
+			}},
+		},
+		{
+			name:   "nullable",
+			input:  `{"anyOf": [{"type": "null"}, {"type": "string", "description": "Name"}], "description": "Optional"}`,
+			expect: &genai.Schema{Type: genai.TypeString, Description: "Optional. Name", Nullable: true},
+		},
+		{
+			name:   "first variant of mixed types",
+			input:  `{"oneOf": [{"type": "integer"}, {"type": "string"}]}`,
+			expect: &genai.Schema{Type: genai.TypeInteger},
+		},
+		{
+			name:  "allOf merge",
+			input: `{"description": "Pet", "allOf": [{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}, {"properties": {"age": {"type": "integer"}}, "required": ["age"]}]}`,
+			expect: &genai.Schema{Type: genai.TypeObject, Description: "Pet", Required: []string{"name", "age"}, Properties: map[string]*genai.Schema{
+				"name": {Type: genai.TypeString},
+				"age":  {Type: genai.TypeInteger},
+			}},
+		},
+		{
+			name: "discriminated variants",
+			input: `{"oneOf": [
+				{"allOf": [{"type": "object", "required": ["petType"], "properties": {"petType": {"type": "string", "enum": ["cat"]}}}, {"properties": {"meow": {"type": "boolean"}}}]},
+				{"type": "object", "required": ["petType", "bark"], "properties": {"petType": {"type": "string", "enum": ["dog", "cat"]}, "bark": {"type": "string"}}}
+			]}`,
+			expect: &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{
+				"petType": {Type: genai.TypeString, Enum: []string{"cat", "dog"}},
+				"meow":    {Type: genai.TypeBoolean},
+				"bark":    {Type: genai.TypeString},
+			}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expect, schemaConverter(parseSchema(t, c.input)))
+		})
+	}
+}
+
+func TestSimplifyVariants(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expect   string
+		nullable bool
+	}{
+		{
+			name:   "no variants",
+			input:  `{"type": "string"}`,
+			expect: `{"type": "string"}`,
+		},
+		{
+			name:     "only null",
+			input:    `{"description": "Nothing", "anyOf": [{"type": "null"}]}`,
+			expect:   `{"description": "Nothing"}`,
+			nullable: true,
+		},
+		{
+			name:     "nested nullable",
+			input:    `{"oneOf": [{"anyOf": [{"type": "null"}, {"type": "boolean"}]}, {"type": "string"}]}`,
+			expect:   `{"type": "boolean"}`,
+			nullable: true,
+		},
+		{
+			name:   "objects are merged without required",
+			input:  `{"description": "Shape", "anyOf": [{"type": "object", "required": ["r"], "properties": {"r": {"type": "number"}}}, {"type": "object", "required": ["w"], "properties": {"w": {"type": "number"}}}]}`,
+			expect: `{"type": "object", "description": "Shape", "properties": {"r": {"type": "number"}, "w": {"type": "number"}}}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out, nullable := simplifyVariants(parseSchema(t, c.input))
+			assert.Equal(t, c.nullable, nullable)
+			data, err := json.Marshal(out)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expect, string(data))
+		})
+	}
+}
+
+func TestMapMessages(t *testing.T) {
+	messages := []types.Message{
+		{Role: types.RoleUser, Parts: []types.Content{types.Text("weather?"), {Mime: types.MIMEPng, Data: []byte("png")}}},
+		{Role: types.RoleUser, Parts: []types.Content{{Mime: types.MIMEPdf, Data: []byte("%PDF")}}},
+		{Role: types.RoleToolCall, ToolID: "1", ToolName: "get_weather", Parts: []types.Content{{Mime: types.MIMEJson, Data: []byte(`{"city": "Paris"}`)}}},
+		{Role: types.RoleToolCall, ToolID: "2", ToolName: "get_time", Parts: []types.Content{{Mime: types.MIMEJson, Data: []byte(`{}`)}}},
+		{Role: types.RoleToolResult, ToolID: "1", ToolName: "get_weather", Parts: []types.Content{{Mime: types.MIMEJson, Data: []byte(`{"temp": 20}`)}}},
+		{Role: types.RoleToolResult, ToolID: "2", ToolName: "get_time", Parts: []types.Content{{Mime: types.MIMEJson, Data: []byte(`"12:00"`)}}},
+		{Role: types.RoleAssistant, Parts: []types.Content{types.Text("20C at 12:00")}},
+		{Role: types.RoleUser, Parts: []types.Content{{Mime: types.MIMEMarkdown, Data: []byte("# thanks")}}},
+	}
+	out, err := mapMessages(messages)
+	require.NoError(t, err)
+
+	expect := []*genai.Content{
+		{Role: "user", Parts: []genai.Part{
+			genai.Text("weather?"),
+			genai.Blob{MIMEType: "image/png", Data: []byte("png")},
+			genai.Blob{MIMEType: "application/pdf", Data: []byte("%PDF")},
+		}},
+		{Role: "model", Parts: []genai.Part{
+			genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "Paris"}},
+			genai.FunctionCall{Name: "get_time", Args: map[string]any{}},
+		}},
+		{Role: "user", Parts: []genai.Part{
+			genai.FunctionResponse{Name: "get_weather", Response: map[string]any{"temp": float64(20)}},
+			genai.FunctionResponse{Name: "get_time", Response: map[string]any{"content": "12:00"}},
+		}},
+		{Role: "model", Parts: []genai.Part{genai.Text("20C at 12:00")}},
+		{Role: "user", Parts: []genai.Part{genai.Text("# thanks")}},
+	}
+	assert.Equal(t, expect, out)
+
+	_, err = mapMessages([]types.Message{{Role: types.RoleToolResult, ToolName: "file", Parts: []types.Content{{Mime: types.MIMEPng, Data: []byte("png")}}}})
+	require.Error(t, err)
+}
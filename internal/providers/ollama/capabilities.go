@@ -0,0 +1,36 @@
+package ollama
+
+import (
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Capable = &Ollama{}
+
+// known model families from https://ollama.com/library. JSON format is supported by all models.
+// Unknown models (ex: custom or newer models) are assumed to support everything, so tools are not dropped silently.
+var registry = types.CapabilityRegistry{
+	{Prefix: "llava", Capabilities: types.Capabilities{Vision: true, JSON: true}},
+	{Prefix: "bakllava", Capabilities: types.Capabilities{Vision: true, JSON: true}},
+	{Prefix: "moondream", Capabilities: types.Capabilities{Vision: true, JSON: true}},
+	{Prefix: "minicpm-v", Capabilities: types.Capabilities{Vision: true, JSON: true}},
+	{Prefix: "llama3.2-vision", Capabilities: types.Capabilities{Vision: true, JSON: true}},
+	{Prefix: "llama3.1", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "llama3.2", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "mistral", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "mixtral", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "qwen2", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "command-r", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "firefunction-v2", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "hermes3", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+	{Prefix: "deepseek-r1", Capabilities: types.Capabilities{JSON: true, Reasoning: true}},
+	{Prefix: "qwq", Capabilities: types.Capabilities{Tools: true, JSON: true, Reasoning: true}},
+	{Prefix: "nemotron", Capabilities: types.Capabilities{Tools: true, JSON: true}},
+}
+
+func (olm *Ollama) Capabilities(model string) types.Capabilities {
+	caps := registry.Find(model, types.AllCapabilities)
+	if olm.config.NumCtx > 0 {
+		caps.MaxContext = olm.config.NumCtx
+	}
+	return caps
+}
@@ -0,0 +1,19 @@
+package ollama_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pikocloud/pikobrain/internal/providers/ollama"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func TestOllama_Capabilities(t *testing.T) {
+	var provider ollama.Ollama
+
+	assert.Equal(t, types.Capabilities{Tools: true, JSON: true}, provider.Capabilities("llama3.1:8b"))
+	assert.Equal(t, types.Capabilities{Vision: true, JSON: true}, provider.Capabilities("llava:13b"))
+	// unknown models keep tools and images
+	assert.Equal(t, types.AllCapabilities, provider.Capabilities("gemma3:27b"))
+}
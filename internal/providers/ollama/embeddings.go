@@ -0,0 +1,31 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ollama/ollama/api"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Embedder = &Ollama{}
+
+func (olm *Ollama) Embed(ctx context.Context, model string, input []string) (*types.Embeddings, error) {
+	var req = api.EmbedRequest{
+		Model: model,
+		Input: input,
+	}
+	if olm.config.KeepAlive != nil {
+		req.KeepAlive = &api.Duration{Duration: *olm.config.KeepAlive}
+	}
+	res, err := olm.client.Embed(ctx, &req)
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+	return &types.Embeddings{
+		Vectors:    res.Embeddings,
+		InputToken: res.PromptEvalCount,
+		TotalToken: res.PromptEvalCount,
+	}, nil
+}
@@ -6,29 +6,49 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/ollama/ollama/api"
 
 	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/utils"
 )
 
-func New(u string) (*Ollama, error) {
+// Config for Ollama runtime.
+type Config struct {
+	NumCtx    int            `json:"num_ctx,omitempty" yaml:"numCtx,omitempty"`       // context window size in tokens (Ollama default is small and silently truncates history)
+	KeepAlive *time.Duration `json:"keep_alive,omitempty" yaml:"keepAlive,omitempty"` // how long model stays loaded after request; negative means forever
+	Options   map[string]any `json:"options,omitempty" yaml:"options,omitempty"`      // extra model options (temperature, top_k, ...) as-is
+}
+
+// New Ollama provider. If client is nil, default HTTP client is used.
+func New(u string, client *http.Client, config Config) (*Ollama, error) {
 	link, err := url.Parse(u)
 	if err != nil {
 		return nil, fmt.Errorf("parse URL %s: %w", u, err)
 	}
+	if client == nil {
+		client = http.DefaultClient
+	}
 
-	return &Ollama{client: api.NewClient(link, http.DefaultClient)}, nil
+	return &Ollama{client: api.NewClient(link, client), config: config}, nil
 }
 
 type Ollama struct {
 	client *api.Client
+	config Config
 }
 
 func (olm *Ollama) Invoke(ctx context.Context, config types.Config, messages []types.Message, tools []types.ToolDefinition) (*types.Invoke, error) {
 	var req = api.ChatRequest{
-		Model:  config.Model,
-		Stream: new(bool),
+		Model:   config.Model,
+		Stream:  new(bool),
+		Options: olm.options(config),
+	}
+	if olm.config.KeepAlive != nil {
+		req.KeepAlive = &api.Duration{Duration: *olm.config.KeepAlive}
 	}
 	if config.ForceJSON {
 		req.Format = "json"
@@ -40,13 +60,11 @@ func (olm *Ollama) Invoke(ctx context.Context, config types.Config, messages []t
 		})
 	}
 
-	for _, msg := range messages {
-		mapped, err := mapMessage(msg)
-		if err != nil {
-			return nil, fmt.Errorf("map message: %w", err)
-		}
-		req.Messages = append(req.Messages, mapped)
+	mapped, err := mapMessages(messages)
+	if err != nil {
+		return nil, fmt.Errorf("map messages: %w", err)
 	}
+	req.Messages = append(req.Messages, mapped...)
 
 	for _, tool := range tools {
 		var params = api.ToolFunction{
@@ -69,7 +87,7 @@ func (olm *Ollama) Invoke(ctx context.Context, config types.Config, messages []t
 	}
 
 	var responses []api.ChatResponse
-	err := olm.client.Chat(ctx, &req, func(response api.ChatResponse) error {
+	err = olm.client.Chat(ctx, &req, func(response api.ChatResponse) error {
 		responses = append(responses, response)
 		return nil
 	})
@@ -87,20 +105,26 @@ func (olm *Ollama) Invoke(ctx context.Context, config types.Config, messages []t
 		outToken += response.EvalCount
 		totToken += inpToken + outToken // no difference here
 
+		// thinking models (DeepSeek R1, QwQ) put reasoning inside <think> tags
+		reasoning, answer := types.SplitThinking(response.Message.Content)
+		if msg, ok := types.Reasoning(reasoning); ok {
+			output = append(output, msg)
+		}
+
+		var parts []types.Content
+		if answer != "" {
+			parts = append(parts, types.Text(answer))
+		}
 		for _, image := range response.Message.Images {
-			output = append(output, types.Message{
-				Role: types.RoleAssistant,
-				Content: types.Content{
-					Data: image,
-					Mime: types.MIMEJpg,
-				},
+			parts = append(parts, types.Content{
+				Data: image,
+				Mime: types.MIMEJpg,
 			})
 		}
-
-		if response.Message.Content != "" {
+		if len(parts) > 0 {
 			output = append(output, types.Message{
-				Role:    types.RoleAssistant,
-				Content: types.Text(response.Message.Content),
+				Role:  types.RoleAssistant,
+				Parts: parts,
 			})
 		}
 
@@ -110,14 +134,15 @@ func (olm *Ollama) Invoke(ctx context.Context, config types.Config, messages []t
 			if err != nil {
 				return nil, fmt.Errorf("marshal tool call arguments: %w", err)
 			}
+			// Ollama doesn't provide call ID, so we have to generate unique one
 			output = append(output, types.Message{
-				ToolID:   toolCall.Function.Name,
+				ToolID:   utils.RandomID("call_"),
 				ToolName: toolCall.Function.Name,
 				Role:     types.RoleToolCall,
-				Content: types.Content{
+				Parts: []types.Content{{
 					Data: in,
 					Mime: types.MIMEJson,
-				},
+				}},
 			})
 		}
 	}
@@ -130,27 +155,98 @@ func (olm *Ollama) Invoke(ctx context.Context, config types.Config, messages []t
 	}, nil
 }
 
-func mapMessage(msg types.Message) (api.Message, error) {
+func (olm *Ollama) options(config types.Config) map[string]any {
+	var opts = make(map[string]any, len(olm.config.Options)+2)
+	for k, v := range olm.config.Options {
+		opts[k] = v
+	}
+	if olm.config.NumCtx > 0 {
+		opts["num_ctx"] = olm.config.NumCtx
+	}
+	if config.MaxTokens > 0 {
+		opts["num_predict"] = config.MaxTokens
+	}
+	return opts
+}
+
+// Ollama doesn't support tool call IDs, so calls and results are paired by position:
+// consecutive calls are merged into single assistant message, and following results are ordered same way as calls.
+func mapMessages(messages []types.Message) ([]api.Message, error) {
+	var out = make([]api.Message, 0, len(messages))
+	var calls []string // IDs of last tool calls batch
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		switch msg.Role {
+		case types.RoleToolCall:
+			var batch = api.Message{Role: "assistant"}
+			calls = calls[:0]
+			for ; i < len(messages) && messages[i].Role == types.RoleToolCall; i++ {
+				call, err := mapToolCall(messages[i])
+				if err != nil {
+					return nil, err
+				}
+				batch.ToolCalls = append(batch.ToolCalls, call)
+				calls = append(calls, messages[i].ToolID)
+			}
+			i--
+			out = append(out, batch)
+		case types.RoleToolResult:
+			var results []types.Message
+			for ; i < len(messages) && messages[i].Role == types.RoleToolResult; i++ {
+				results = append(results, messages[i])
+			}
+			i--
+			slices.SortStableFunc(results, func(a, b types.Message) int {
+				return callIndex(calls, a.ToolID) - callIndex(calls, b.ToolID)
+			})
+			// Ollama matches results with calls by position, so missing results are replaced by placeholder
+			for idx, id := range calls {
+				if !slices.ContainsFunc(results, func(res types.Message) bool { return res.ToolID == id }) {
+					results = slices.Insert(results, min(idx, len(results)), types.Message{
+						Role:   types.RoleToolResult,
+						ToolID: id,
+						Parts:  []types.Content{types.Text("no result")},
+					})
+				}
+			}
+			for _, res := range results {
+				out = append(out, mapMessage(res))
+			}
+		default:
+			out = append(out, mapMessage(msg))
+		}
+	}
+	return out, nil
+}
+
+// position of call in batch. Unknown calls are placed to the end.
+func callIndex(calls []string, id string) int {
+	if idx := slices.Index(calls, id); idx >= 0 {
+		return idx
+	}
+	return len(calls)
+}
+
+func mapToolCall(msg types.Message) (api.ToolCall, error) {
+	var arg api.ToolCallFunctionArguments
+	if err := json.Unmarshal(msg.Content().Data, &arg); err != nil {
+		return api.ToolCall{}, fmt.Errorf("unmarshal tool call arguments: %w", err)
+	}
+	return api.ToolCall{
+		Function: api.ToolCallFunction{
+			Name:      msg.ToolName,
+			Arguments: arg,
+		},
+	}, nil
+}
+
+func mapMessage(msg types.Message) api.Message {
 	var role = "user"
 	switch msg.Role {
 	case types.RoleUser:
 		role = "user"
 	case types.RoleAssistant:
 		role = "assistant"
-	case types.RoleToolCall:
-		var arg api.ToolCallFunctionArguments
-		if err := json.Unmarshal(msg.Content.Data, &arg); err != nil {
-			return api.Message{}, fmt.Errorf("unmarshal tool call arguments: %w", err)
-		}
-		return api.Message{
-			Role: "assistant",
-			ToolCalls: []api.ToolCall{{
-				Function: api.ToolCallFunction{
-					Name:      msg.ToolName,
-					Arguments: arg,
-				},
-			}},
-		}, nil
 	case types.RoleToolResult:
 		role = "tool"
 	}
@@ -158,11 +254,16 @@ func mapMessage(msg types.Message) (api.Message, error) {
 	res := api.Message{
 		Role: role,
 	}
-	if msg.Content.Mime.IsImage() {
-		res.Images = append(res.Images, api.ImageData(msg.Content.Data))
-	} else if msg.Content.Data != nil {
-		res.Content = string(msg.Content.Data)
+	// Ollama accepts only one text and list of images per message
+	var texts []string
+	for _, part := range msg.Parts {
+		if part.Mime.IsImage() {
+			res.Images = append(res.Images, api.ImageData(part.Data))
+		} else if part.Data != nil {
+			texts = append(texts, string(part.Data))
+		}
 	}
+	res.Content = strings.Join(texts, "\n")
 
-	return res, nil
+	return res
 }
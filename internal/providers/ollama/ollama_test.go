@@ -0,0 +1,111 @@
+package ollama
+
+import (
+	"testing"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func toolCall(id, name, args string) types.Message {
+	return types.Message{Role: types.RoleToolCall, ToolID: id, ToolName: name, Parts: []types.Content{{Mime: types.MIMEJson, Data: []byte(args)}}}
+}
+
+func toolResult(id, name, text string) types.Message {
+	return types.Message{Role: types.RoleToolResult, ToolID: id, ToolName: name, Parts: []types.Content{types.Text(text)}}
+}
+
+func TestMapMessages(t *testing.T) {
+	cases := []struct {
+		name     string
+		messages []types.Message
+		calls    []string // names of calls in the single batch
+		results  []string // content of tool messages in order
+	}{
+		{
+			name: "results in order of calls",
+			messages: []types.Message{
+				toolCall("1", "weather", `{"city": "Paris"}`),
+				toolCall("2", "time", `{}`),
+				toolCall("3", "news", `{"limit": 1}`),
+				toolResult("3", "news", "nothing"),
+				toolResult("1", "weather", "sunny"),
+				toolResult("2", "time", "12:00"),
+			},
+			calls:   []string{"weather", "time", "news"},
+			results: []string{"sunny", "12:00", "nothing"},
+		},
+		{
+			name: "missing result",
+			messages: []types.Message{
+				toolCall("1", "weather", `{"city": "Paris"}`),
+				toolCall("2", "time", `{}`),
+				toolCall("3", "news", `{}`),
+				toolResult("3", "news", "nothing"),
+				toolResult("1", "weather", "sunny"),
+			},
+			calls:   []string{"weather", "time", "news"},
+			results: []string{"sunny", "no result", "nothing"},
+		},
+		{
+			name: "unknown result is the last",
+			messages: []types.Message{
+				toolCall("1", "weather", `{}`),
+				toolResult("x", "other", "unexpected"),
+				toolResult("1", "weather", "sunny"),
+			},
+			calls:   []string{"weather"},
+			results: []string{"sunny", "unexpected"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			input := append([]types.Message{{Role: types.RoleUser, Parts: []types.Content{types.Text("hello")}}}, c.messages...)
+			out, err := mapMessages(input)
+			require.NoError(t, err)
+			require.Len(t, out, 2+len(c.results))
+
+			assert.Equal(t, api.Message{Role: "user", Content: "hello"}, out[0])
+			batch := out[1]
+			assert.Equal(t, "assistant", batch.Role)
+			var calls []string
+			for _, call := range batch.ToolCalls {
+				calls = append(calls, call.Function.Name)
+			}
+			assert.Equal(t, c.calls, calls)
+
+			var results []string
+			for _, msg := range out[2:] {
+				assert.Equal(t, "tool", msg.Role)
+				results = append(results, msg.Content)
+			}
+			assert.Equal(t, c.results, results)
+		})
+	}
+}
+
+func TestMapMessages_batches(t *testing.T) {
+	out, err := mapMessages([]types.Message{
+		{Role: types.RoleUser, Parts: []types.Content{types.Text("look"), {Mime: types.MIMEPng, Data: []byte("png")}}},
+		toolCall("1", "weather", `{"city": "Paris"}`),
+		toolResult("1", "weather", "sunny"),
+		toolCall("2", "time", `{}`),
+		toolResult("2", "time", "12:00"),
+		{Role: types.RoleAssistant, Parts: []types.Content{types.Text("sunny at 12:00")}},
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 6)
+	assert.Equal(t, []api.ImageData{api.ImageData("png")}, out[0].Images)
+	assert.Equal(t, "look", out[0].Content)
+	assert.Equal(t, "Paris", out[1].ToolCalls[0].Function.Arguments["city"])
+	assert.Equal(t, "sunny", out[2].Content)
+	assert.Equal(t, "time", out[3].ToolCalls[0].Function.Name)
+	assert.Equal(t, "12:00", out[4].Content)
+	assert.Equal(t, api.Message{Role: "assistant", Content: "sunny at 12:00"}, out[5])
+
+	_, err = mapMessages([]types.Message{toolCall("1", "broken", `not json`)})
+	require.Error(t, err)
+}
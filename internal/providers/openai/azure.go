@@ -0,0 +1,48 @@
+package openai
+
+import (
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+//go:generate go run github.com/abice/go-enum@v0.6.0  --marshal
+
+const DefaultAzureAPIVersion = "2024-06-01"
+
+// AzureAuth defines how token should be passed to Azure.
+// ENUM(key,entra)
+type AzureAuth string
+
+type AzureConfig struct {
+	Deployment string    `json:"deployment" yaml:"deployment"`  // deployment name, if not set - model name will be used
+	APIVersion string    `json:"api_version" yaml:"apiVersion"` // API version, default is DefaultAzureAPIVersion
+	Auth       AzureAuth `json:"auth" yaml:"auth"`              // key (api-key header) or entra (Microsoft Entra ID bearer token), default is key
+}
+
+// NewAzure creates provider for Azure OpenAI. URL is the resource endpoint (ex: https://my-resource.openai.azure.com).
+// Messages mapping is the same as for OpenAI. If client is nil, default HTTP client is used.
+func NewAzure(url string, token string, config AzureConfig, client *http.Client) *OpenAI {
+	cfg := openai.DefaultAzureConfig(token, url)
+	if client != nil {
+		cfg.HTTPClient = client
+	}
+	if config.APIVersion == "" {
+		config.APIVersion = DefaultAzureAPIVersion
+	}
+	cfg.APIVersion = config.APIVersion
+
+	if config.Auth == AzureAuthEntra {
+		cfg.APIType = openai.APITypeAzureAD
+	}
+
+	if config.Deployment != "" {
+		cfg.AzureModelMapperFunc = func(string) string {
+			return config.Deployment
+		}
+	}
+
+	return &OpenAI{
+		client: openai.NewClientWithConfig(cfg),
+	}
+}
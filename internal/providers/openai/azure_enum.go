@@ -0,0 +1,61 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package openai
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// AzureAuthKey is a AzureAuth of type key.
+	AzureAuthKey AzureAuth = "key"
+	// AzureAuthEntra is a AzureAuth of type entra.
+	AzureAuthEntra AzureAuth = "entra"
+)
+
+var ErrInvalidAzureAuth = errors.New("not a valid AzureAuth")
+
+// String implements the Stringer interface.
+func (x AzureAuth) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x AzureAuth) IsValid() bool {
+	_, err := ParseAzureAuth(string(x))
+	return err == nil
+}
+
+var _AzureAuthValue = map[string]AzureAuth{
+	"key":   AzureAuthKey,
+	"entra": AzureAuthEntra,
+}
+
+// ParseAzureAuth attempts to convert a string to a AzureAuth.
+func ParseAzureAuth(name string) (AzureAuth, error) {
+	if x, ok := _AzureAuthValue[name]; ok {
+		return x, nil
+	}
+	return AzureAuth(""), fmt.Errorf("%s is %w", name, ErrInvalidAzureAuth)
+}
+
+// MarshalText implements the text marshaller method.
+func (x AzureAuth) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *AzureAuth) UnmarshalText(text []byte) error {
+	tmp, err := ParseAzureAuth(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -0,0 +1,26 @@
+package openai
+
+import (
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Capable = &OpenAI{}
+
+// known models. Unknown models (ex: OpenAI-compatible servers) are assumed to support everything.
+var registry = types.CapabilityRegistry{
+	{Prefix: "gpt-4o", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, MaxContext: 128000}},
+	{Prefix: "gpt-4-turbo", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, MaxContext: 128000}},
+	{Prefix: "gpt-4.1", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, MaxContext: 1047576}},
+	{Prefix: "gpt-4.5", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, MaxContext: 128000}},
+	{Prefix: "gpt-4", Capabilities: types.Capabilities{Tools: true, MaxContext: 8192}},
+	{Prefix: "gpt-3.5-turbo", Capabilities: types.Capabilities{Tools: true, JSON: true, MaxContext: 16385}},
+	{Prefix: "o1-mini", Capabilities: types.Capabilities{Reasoning: true, MaxContext: 128000}},
+	{Prefix: "o1-preview", Capabilities: types.Capabilities{Reasoning: true, MaxContext: 128000}},
+	{Prefix: "o1", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, Reasoning: true, MaxContext: 200000}},
+	{Prefix: "o3", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, Reasoning: true, MaxContext: 200000}},
+	{Prefix: "o4", Capabilities: types.Capabilities{Vision: true, Tools: true, JSON: true, Reasoning: true, MaxContext: 200000}},
+}
+
+func (provider *OpenAI) Capabilities(model string) types.Capabilities {
+	return registry.Find(model, types.AllCapabilities)
+}
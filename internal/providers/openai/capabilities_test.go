@@ -0,0 +1,43 @@
+package openai_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pikocloud/pikobrain/internal/providers/openai"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func TestOpenAI_Capabilities(t *testing.T) {
+	provider := openai.New("http://localhost", "", nil)
+
+	cases := []struct {
+		model      string
+		vision     bool
+		tools      bool
+		maxContext int
+	}{
+		{model: "gpt-4", tools: true, maxContext: 8192},
+		{model: "gpt-4-0613", tools: true, maxContext: 8192},
+		{model: "gpt-4-turbo-2024-04-09", vision: true, tools: true, maxContext: 128000},
+		{model: "gpt-4o-mini", vision: true, tools: true, maxContext: 128000},
+		{model: "gpt-4.1", vision: true, tools: true, maxContext: 1047576},
+		{model: "gpt-4.1-mini", vision: true, tools: true, maxContext: 1047576},
+		{model: "gpt-4.5-preview", vision: true, tools: true, maxContext: 128000},
+		{model: "o1-mini", maxContext: 128000},
+		{model: "o1-preview-2024-09-12", maxContext: 128000},
+		{model: "o1-2024-12-17", vision: true, tools: true, maxContext: 200000},
+	}
+	for _, c := range cases {
+		t.Run(c.model, func(t *testing.T) {
+			caps := provider.Capabilities(c.model)
+			assert.Equal(t, c.vision, caps.Vision)
+			assert.Equal(t, c.tools, caps.Tools)
+			assert.Equal(t, c.maxContext, caps.MaxContext)
+		})
+	}
+
+	// OpenAI-compatible servers
+	assert.Equal(t, types.AllCapabilities, provider.Capabilities("llama-3.3-70b"))
+}
@@ -0,0 +1,36 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Embedder = &OpenAI{}
+
+func (provider *OpenAI) Embed(ctx context.Context, model string, input []string) (*types.Embeddings, error) {
+	res, err := provider.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: input,
+		Model: openai.EmbeddingModel(model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create embeddings: %w", err)
+	}
+
+	var vectors = make([][]float32, len(input))
+	for _, item := range res.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+
+	return &types.Embeddings{
+		Vectors:    vectors,
+		InputToken: res.Usage.PromptTokens,
+		TotalToken: res.Usage.TotalTokens,
+	}, nil
+}
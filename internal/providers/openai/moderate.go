@@ -0,0 +1,50 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Moderator = &OpenAI{}
+
+// Moderate text by OpenAI moderation model. Empty model means API default.
+func (provider *OpenAI) Moderate(ctx context.Context, model string, text string) ([]string, error) {
+	res, err := provider.client.Moderations(ctx, openai.ModerationRequest{
+		Input: text,
+		Model: model,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create moderation: %w", err)
+	}
+	var flagged []string
+	for _, result := range res.Results {
+		if !result.Flagged {
+			continue
+		}
+		// categories are struct of booleans, JSON names are the same as in API docs (ex: self-harm/intent)
+		raw, err := json.Marshal(result.Categories)
+		if err != nil {
+			return nil, fmt.Errorf("marshal categories: %w", err)
+		}
+		var categories map[string]bool
+		if err := json.Unmarshal(raw, &categories); err != nil {
+			return nil, fmt.Errorf("unmarshal categories: %w", err)
+		}
+		for name, ok := range categories {
+			if ok {
+				flagged = append(flagged, name)
+			}
+		}
+		if len(flagged) == 0 {
+			flagged = append(flagged, "flagged")
+		}
+	}
+	sort.Strings(flagged)
+	return flagged, nil
+}
@@ -3,6 +3,8 @@ package openai
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/sashabaranov/go-openai"
 
@@ -11,9 +13,13 @@ import (
 
 var _ types.Provider = &OpenAI{}
 
-func New(url string, token string) *OpenAI {
+// New OpenAI (or compatible) provider. If client is nil, default HTTP client is used.
+func New(url string, token string, client *http.Client) *OpenAI {
 	cfg := openai.DefaultConfig(token)
 	cfg.BaseURL = url
+	if client != nil {
+		cfg.HTTPClient = client
+	}
 	return &OpenAI{
 		client: openai.NewClientWithConfig(cfg),
 	}
@@ -67,11 +73,17 @@ func (provider *OpenAI) Invoke(ctx context.Context, config types.Config, message
 	}
 
 	req := openai.ChatCompletionRequest{
-		Model:          config.Model,
-		Messages:       input,
-		MaxTokens:      config.MaxTokens,
-		ResponseFormat: format,
-		Tools:          openTools,
+		Model:           config.Model,
+		Messages:        input,
+		MaxTokens:       config.MaxTokens,
+		ResponseFormat:  format,
+		Tools:           openTools,
+		ReasoningEffort: config.ReasoningEffort,
+	}
+	if provider.Capabilities(config.Model).Reasoning {
+		// reasoning models reject max_tokens since reasoning tokens are counted in output
+		req.MaxTokens = 0
+		req.MaxCompletionTokens = config.MaxTokens
 	}
 
 	res, err := provider.client.CreateChatCompletion(ctx, req)
@@ -81,6 +93,13 @@ func (provider *OpenAI) Invoke(ctx context.Context, config types.Config, message
 
 	var output = make([]types.Message, 0, len(res.Choices))
 	for _, choice := range res.Choices {
+		// reasoning goes first: as separate field (DeepSeek API, vLLM) or inside <think> tags
+		reasoning, parts := parseMessage(choice.Message)
+		if msg, ok := types.Reasoning(reasoning); ok {
+			msg.User = choice.Message.Name
+			output = append(output, msg)
+		}
+
 		// add function calls
 		// function call can generate multiple calls (parallel)
 		// but response should be per-message, therefore we are flattening them.
@@ -90,28 +109,35 @@ func (provider *OpenAI) Invoke(ctx context.Context, config types.Config, message
 				ToolName: call.Function.Name,
 				Role:     types.RoleToolCall,
 				User:     choice.Message.Name,
-				Content: types.Content{
+				Parts: []types.Content{{
 					Data: []byte(call.Function.Arguments),
 					Mime: types.MIMEJson,
-				},
+				}},
 			})
 		}
 
-		// add direct messages
-		for _, assistantContent := range parseMessage(choice.Message) {
+		// add direct message
+		if len(parts) > 0 {
 			output = append(output, types.Message{
-				Role:    types.RoleAssistant,
-				Content: assistantContent,
-				User:    choice.Message.Name,
+				Role:  types.RoleAssistant,
+				Parts: parts,
+				User:  choice.Message.Name,
 			})
 		}
 	}
 
+	// caching is automatic for long prompts
+	var cached int
+	if details := res.Usage.PromptTokensDetails; details != nil {
+		cached = details.CachedTokens
+	}
+
 	return &types.Invoke{
 		Output:      output,
 		InputToken:  res.Usage.PromptTokens,
 		OutputToken: res.Usage.CompletionTokens,
 		TotalToken:  res.Usage.TotalTokens,
+		CachedToken: cached,
 	}, nil
 }
 
@@ -126,7 +152,7 @@ func mapMessage(message types.Message) openai.ChatCompletionMessage {
 	case types.RoleToolCall:
 		return openai.ChatCompletionMessage{
 			Role:         openai.ChatMessageRoleAssistant,
-			MultiContent: []openai.ChatMessagePart{mapContent(message.Content)},
+			MultiContent: mapParts(message.Parts),
 			Name:         message.ToolName,
 			ToolCalls: []openai.ToolCall{
 				{
@@ -134,7 +160,7 @@ func mapMessage(message types.Message) openai.ChatCompletionMessage {
 					Type: openai.ToolTypeFunction,
 					Function: openai.FunctionCall{
 						Name:      message.ToolName,
-						Arguments: string(message.Content.Data),
+						Arguments: string(message.Content().Data),
 					},
 				},
 			},
@@ -147,11 +173,19 @@ func mapMessage(message types.Message) openai.ChatCompletionMessage {
 	return openai.ChatCompletionMessage{
 		ToolCallID:   message.ToolID,
 		Role:         role,
-		MultiContent: []openai.ChatMessagePart{mapContent(message.Content)},
+		MultiContent: mapParts(message.Parts),
 		Name:         message.User,
 	}
 }
 
+func mapParts(parts []types.Content) []openai.ChatMessagePart {
+	var out = make([]openai.ChatMessagePart, 0, len(parts))
+	for _, part := range parts {
+		out = append(out, mapContent(part))
+	}
+	return out
+}
+
 func mapContent(data types.Content) openai.ChatMessagePart {
 	switch {
 	case data.Mime.IsImage():
@@ -171,13 +205,16 @@ func mapContent(data types.Content) openai.ChatMessagePart {
 	}
 }
 
-func parseMessage(message openai.ChatCompletionMessage) []types.Content {
-	var out []types.Content
+func parseMessage(message openai.ChatCompletionMessage) (reasoning string, out []types.Content) {
+	reasoning = message.ReasoningContent
 	if message.Content != "" {
-		out = append(out, types.Content{
-			Data: []byte(message.Content),
-			Mime: types.MIMEText,
-		})
+		thinking, answer := types.SplitThinking(message.Content)
+		if thinking != "" {
+			reasoning = strings.TrimSpace(reasoning + "\n" + thinking)
+		}
+		if answer != "" {
+			out = append(out, types.Text(answer))
+		}
 	}
 
 	for _, m := range message.MultiContent {
@@ -193,5 +230,5 @@ func parseMessage(message openai.ChatCompletionMessage) []types.Content {
 		}
 	}
 
-	return out
+	return reasoning, out
 }
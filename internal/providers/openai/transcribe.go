@@ -0,0 +1,29 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+var _ types.Transcriber = &OpenAI{}
+
+// Transcribe audio by Whisper (or compatible) model.
+func (provider *OpenAI) Transcribe(ctx context.Context, config types.TranscribeConfig, audio types.Content) (string, error) {
+	res, err := provider.client.CreateTranscription(ctx, openai.AudioRequest{
+		Model:    config.Model,
+		FilePath: "audio." + audio.Mime.AudioFormat(), // used by API for format detection
+		Reader:   bytes.NewReader(audio.Data),
+		Prompt:   config.Prompt,
+		Language: config.Language,
+		Format:   openai.AudioResponseFormatJSON,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create transcription: %w", err)
+	}
+	return res.Text, nil
+}
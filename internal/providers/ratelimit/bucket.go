@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// bucket of tokens refilled at constant rate. Tokens are taken in advance (balance can go negative, the next
+// takers wait till it's restored) and unused tokens can be returned, which is not possible with rate.Limiter.
+type bucket struct {
+	lock   sync.Mutex
+	size   float64
+	rate   float64 // tokens per second
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newBucket(perMinute int) *bucket {
+	return &bucket{
+		size:   float64(perMinute),
+		rate:   float64(perMinute) / time.Minute.Seconds(),
+		tokens: float64(perMinute),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// take n tokens and return how long to wait till they are available.
+func (b *bucket) take(n int) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.refill()
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// put back n unused tokens.
+func (b *bucket) put(n int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.refill()
+	b.tokens = min(b.size, b.tokens+float64(n))
+}
+
+func (b *bucket) refill() {
+	now := b.now()
+	b.tokens = min(b.size, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// sleep for duration. Fails immediately if context deadline is earlier.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < duration {
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,161 @@
+// Package ratelimit limits requests and tokens per minute to provider on client side.
+// Requests which exceed limits are queued (within context deadline) instead of failing.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+// ErrRateLimited returned if request can not be executed within context deadline due to limits.
+var ErrRateLimited = errors.New("rate limited")
+
+type Config struct {
+	RequestsPerMinute int `json:"requests_per_minute" yaml:"requestsPerMinute"` // max requests per minute, 0 means unlimited
+	TokensPerMinute   int `json:"tokens_per_minute" yaml:"tokensPerMinute"`     // max tokens (input and output) per minute, 0 means unlimited
+}
+
+// Stats of limiter.
+type Stats struct {
+	Queue    int64         `json:"queue"`    // number of requests waiting right now
+	Requests int64         `json:"requests"` // total number of passed requests
+	Delayed  int64         `json:"delayed"`  // number of requests which had to wait
+	Wait     time.Duration `json:"wait"`     // total wait time (nanoseconds in JSON)
+}
+
+// New wraps provider by token bucket limiters. Limits are shared by completions, embeddings and transcriptions.
+func New(provider types.Provider, config Config) *Provider {
+	p := &Provider{Provider: provider}
+	if config.RequestsPerMinute > 0 {
+		p.requests = newBucket(config.RequestsPerMinute)
+	}
+	if config.TokensPerMinute > 0 {
+		p.tokens = newBucket(config.TokensPerMinute)
+	}
+	return p
+}
+
+type Provider struct {
+	types.Provider
+	requests *bucket
+	tokens   *bucket
+
+	queue    atomic.Int64
+	total    atomic.Int64
+	delayed  atomic.Int64
+	waitTime atomic.Int64
+}
+
+func (p *Provider) Invoke(ctx context.Context, config types.Config, messages []types.Message, tools []types.ToolDefinition) (*types.Invoke, error) {
+	// actual usage is unknown before call, so reserve estimation and settle the difference after:
+	// unused tokens are returned, overuse is taken from the next requests
+	reserved := types.Text(config.Prompt).EstimateTokens() + config.MaxTokens
+	for _, msg := range messages {
+		reserved += msg.EstimateTokens()
+	}
+	reserved, err := p.wait(ctx, reserved)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.Provider.Invoke(ctx, config, messages, tools)
+	if err != nil {
+		p.settle(reserved, 0)
+		return nil, err
+	}
+	p.settle(reserved, res.TotalToken)
+	return res, nil
+}
+
+func (p *Provider) Embed(ctx context.Context, model string, input []string) (*types.Embeddings, error) {
+	embedder, ok := p.Provider.(types.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("provider doesn't support embeddings")
+	}
+	var reserved int
+	for _, text := range input {
+		reserved += types.Text(text).EstimateTokens()
+	}
+	reserved, err := p.wait(ctx, reserved)
+	if err != nil {
+		return nil, err
+	}
+	res, err := embedder.Embed(ctx, model, input)
+	if err != nil {
+		p.settle(reserved, 0)
+		return nil, err
+	}
+	p.settle(reserved, res.TotalToken)
+	return res, nil
+}
+
+// Transcribe audio. Only request is counted since audio size in tokens is unknown.
+func (p *Provider) Transcribe(ctx context.Context, config types.TranscribeConfig, audio types.Content) (string, error) {
+	transcriber, ok := p.Provider.(types.Transcriber)
+	if !ok {
+		return "", fmt.Errorf("provider doesn't support transcription")
+	}
+	if _, err := p.wait(ctx, 0); err != nil {
+		return "", err
+	}
+	return transcriber.Transcribe(ctx, config, audio)
+}
+
+// Stats of waiting requests.
+func (p *Provider) Stats() Stats {
+	return Stats{
+		Queue:    p.queue.Load(),
+		Requests: p.total.Load(),
+		Delayed:  p.delayed.Load(),
+		Wait:     time.Duration(p.waitTime.Load()),
+	}
+}
+
+// wait till request and tokens available. Returns number of reserved tokens.
+// Nothing is reserved if waiting fails.
+func (p *Provider) wait(ctx context.Context, tokens int) (int, error) {
+	p.queue.Add(1)
+	defer p.queue.Add(-1)
+	started := time.Now()
+
+	var delay time.Duration
+	if p.requests != nil {
+		delay = p.requests.take(1)
+	}
+	if p.tokens != nil {
+		tokens = min(tokens, int(p.tokens.size)) // can not reserve more than bucket size
+		delay = max(delay, p.tokens.take(tokens))
+	}
+	if err := sleep(ctx, delay); err != nil {
+		if p.requests != nil {
+			p.requests.put(1)
+		}
+		p.settle(tokens, 0)
+		return 0, fmt.Errorf("%w: wait %s for request and %d tokens: %w", ErrRateLimited, delay, tokens, err)
+	}
+
+	waited := time.Since(started)
+	p.total.Add(1)
+	p.waitTime.Add(int64(waited))
+	if waited > time.Millisecond {
+		p.delayed.Add(1)
+		slog.Debug("request delayed by rate limit", "wait", waited, "tokens", tokens, "queue", p.queue.Load())
+	}
+	return tokens, nil
+}
+
+// settle actual usage: unused tokens are returned, tokens above reservation are taken in advance from the next requests.
+func (p *Provider) settle(reserved int, used int) {
+	switch {
+	case p.tokens == nil:
+	case used < reserved:
+		p.tokens.put(reserved - used)
+	case used > reserved:
+		p.tokens.take(min(used-reserved, int(p.tokens.size)))
+	}
+}
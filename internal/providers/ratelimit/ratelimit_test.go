@@ -0,0 +1,118 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+type stubProvider struct{}
+
+func (stubProvider) Invoke(context.Context, types.Config, []types.Message, []types.ToolDefinition) (*types.Invoke, error) {
+	return &types.Invoke{TotalToken: 10}, nil
+}
+
+func TestProvider_Invoke(t *testing.T) {
+	limiter := ratelimit.New(stubProvider{}, ratelimit.Config{RequestsPerMinute: 1})
+
+	_, err := limiter.Invoke(context.Background(), types.Config{}, nil, nil)
+	require.NoError(t, err)
+
+	// the next request is allowed only after a minute
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = limiter.Invoke(ctx, types.Config{}, nil, nil)
+	assert.ErrorIs(t, err, ratelimit.ErrRateLimited)
+
+	stats := limiter.Stats()
+	assert.Equal(t, int64(1), stats.Requests)
+	assert.Equal(t, int64(0), stats.Queue)
+}
+
+type stubTranscriber struct{ stubProvider }
+
+func (stubTranscriber) Transcribe(context.Context, types.TranscribeConfig, types.Content) (string, error) {
+	return "hello", nil
+}
+
+func TestProvider_Transcribe(t *testing.T) {
+	limiter := ratelimit.New(stubTranscriber{}, ratelimit.Config{RequestsPerMinute: 1})
+
+	text, err := limiter.Transcribe(context.Background(), types.TranscribeConfig{}, types.Content{Mime: types.MIMEOgg})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", text)
+
+	// transcription shares request limit with completions
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = limiter.Invoke(ctx, types.Config{}, nil, nil)
+	assert.ErrorIs(t, err, ratelimit.ErrRateLimited)
+
+	_, err = ratelimit.New(stubProvider{}, ratelimit.Config{}).Transcribe(context.Background(), types.TranscribeConfig{}, types.Content{})
+	assert.Error(t, err)
+}
+
+type usageProvider struct {
+	stubTranscriber
+	used int
+	err  error
+}
+
+func (up usageProvider) Invoke(context.Context, types.Config, []types.Message, []types.ToolDefinition) (*types.Invoke, error) {
+	return &types.Invoke{TotalToken: up.used}, up.err
+}
+
+func TestProvider_Invoke_settle(t *testing.T) {
+	config := types.Config{MaxTokens: 400}
+	timeout := func() context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		t.Cleanup(cancel)
+		return ctx
+	}
+
+	// unused reservation is returned
+	limiter := ratelimit.New(usageProvider{used: 100}, ratelimit.Config{TokensPerMinute: 1000})
+	for i := 0; i < 5; i++ {
+		_, err := limiter.Invoke(timeout(), config, nil, nil)
+		require.NoError(t, err, "request %d", i)
+	}
+
+	// failed request doesn't consume tokens
+	failed := ratelimit.New(usageProvider{err: errors.New("unavailable")}, ratelimit.Config{TokensPerMinute: 1000})
+	for i := 0; i < 5; i++ {
+		_, err := failed.Invoke(timeout(), config, nil, nil)
+		require.NotErrorIs(t, err, ratelimit.ErrRateLimited, "request %d", i)
+	}
+
+	// overuse is taken from the next requests
+	overused := ratelimit.New(usageProvider{used: 2000}, ratelimit.Config{TokensPerMinute: 1000})
+	_, err := overused.Invoke(timeout(), config, nil, nil)
+	require.NoError(t, err)
+	_, err = overused.Invoke(timeout(), config, nil, nil)
+	require.ErrorIs(t, err, ratelimit.ErrRateLimited)
+}
+
+func TestProvider_wait_tokensFailed(t *testing.T) {
+	limiter := ratelimit.New(usageProvider{used: 10}, ratelimit.Config{RequestsPerMinute: 2, TokensPerMinute: 10})
+	config := types.Config{MaxTokens: 10}
+
+	_, err := limiter.Invoke(context.Background(), config, nil, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = limiter.Invoke(ctx, config, nil, nil)
+	require.ErrorIs(t, err, ratelimit.ErrRateLimited)
+
+	// request slot is not used by failed wait
+	_, err = limiter.Transcribe(ctx, types.TranscribeConfig{}, types.Content{Mime: types.MIMEOgg})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), limiter.Stats().Requests)
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"strings"
+)
+
+// Capabilities of the model.
+type Capabilities struct {
+	Vision     bool `json:"vision" yaml:"vision"`          // model accepts images
+	Tools      bool `json:"tools" yaml:"tools"`            // model supports function calling
+	JSON       bool `json:"json" yaml:"json"`              // model supports forced JSON output
+	Documents  bool `json:"documents" yaml:"documents"`    // model natively accepts documents (PDF, Markdown, CSV, HTML)
+	Reasoning  bool `json:"reasoning" yaml:"reasoning"`    // model thinks before answer (reasoning effort or budget applicable)
+	Cache      bool `json:"cache" yaml:"cache"`            // model supports explicit prompt caching (Bedrock cache points, Gemini cached content)
+	MaxContext int  `json:"max_context" yaml:"maxContext"` // context window size in tokens, 0 means unknown
+}
+
+// AllCapabilities is used for unknown models and providers which do not declare capabilities.
+// Native documents support and reasoning are rare, so they are not included.
+var AllCapabilities = Capabilities{
+	Vision: true,
+	Tools:  true,
+	JSON:   true,
+}
+
+// Capable is optional interface for providers which can describe capabilities of models.
+type Capable interface {
+	Capabilities(model string) Capabilities
+}
+
+// CapabilitiesOf returns capabilities of provider and model pair.
+// If provider doesn't implement [Capable], [AllCapabilities] returned.
+func CapabilitiesOf(provider Provider, model string) Capabilities {
+	if c, ok := provider.(Capable); ok {
+		return c.Capabilities(model)
+	}
+	return AllCapabilities
+}
+
+// ModelCapabilities declares capabilities for all models with the same name prefix.
+type ModelCapabilities struct {
+	Prefix string
+	Capabilities
+}
+
+// CapabilityRegistry is the list of known models.
+type CapabilityRegistry []ModelCapabilities
+
+// Find capabilities for model by the longest matched prefix. If nothing matched, fallback returned.
+func (cr CapabilityRegistry) Find(model string, fallback Capabilities) Capabilities {
+	var (
+		found   = fallback
+		longest = -1
+	)
+	for _, item := range cr {
+		if strings.HasPrefix(model, item.Prefix) && len(item.Prefix) > longest {
+			found = item.Capabilities
+			longest = len(item.Prefix)
+		}
+	}
+	return found
+}
@@ -0,0 +1,21 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func TestCapabilityRegistry_Find(t *testing.T) {
+	registry := types.CapabilityRegistry{
+		{Prefix: "llama3.2", Capabilities: types.Capabilities{Tools: true}},
+		{Prefix: "llama3.2-vision", Capabilities: types.Capabilities{Vision: true}},
+	}
+	fallback := types.Capabilities{JSON: true}
+
+	assert.Equal(t, types.Capabilities{Tools: true}, registry.Find("llama3.2:3b", fallback))
+	assert.Equal(t, types.Capabilities{Vision: true}, registry.Find("llama3.2-vision:11b", fallback))
+	assert.Equal(t, fallback, registry.Find("phi3", fallback))
+}
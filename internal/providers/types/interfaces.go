@@ -42,8 +42,9 @@ type Tool interface {
 	Call(ctx context.Context, args json.RawMessage) (Content, error)
 }
 
-// Role for each message.
-// ENUM(user,assistant,toolCall,toolResult)
+// Role for each message. Reasoning is thinking of model before the answer: it's stored, but not sent back to models.
+// Note is appended to thread by tools: it's not a conversation turn, so it's moved to the end of the turn in history.
+// ENUM(user,assistant,toolCall,toolResult,reasoning,note)
 type Role string
 
 // MIME for each message.
@@ -55,6 +56,14 @@ type Role string
 // jpg = image/jpg
 // webp = image/webp
 // gif = image/gif
+// mp3 = audio/mpeg
+// ogg = audio/ogg
+// wav = audio/wav
+// m4a = audio/mp4
+// pdf = application/pdf
+// markdown = text/markdown
+// csv = text/csv
+// html = text/html
 // )
 type MIME string
 
@@ -66,6 +75,45 @@ func (M MIME) IsImage() bool {
 	return strings.HasPrefix(string(M), "image/")
 }
 
+// IsDocument returns true for file-like content which may be natively supported by some providers.
+func (M MIME) IsDocument() bool {
+	switch M {
+	case MIMEPdf, MIMEMarkdown, MIMECsv, MIMEHtml:
+		return true
+	default:
+		return false
+	}
+}
+
+// DocumentFormat returns common file extension for document.
+func (M MIME) DocumentFormat() string {
+	switch M {
+	case MIMEMarkdown:
+		return "md"
+	case MIMEText:
+		return "txt"
+	default:
+		_, ext, _ := strings.Cut(string(M), "/")
+		return ext
+	}
+}
+
+func (M MIME) IsAudio() bool {
+	return strings.HasPrefix(string(M), "audio/")
+}
+
+// AudioFormat returns common file extension for audio.
+func (M MIME) AudioFormat() string {
+	switch M {
+	case MIMEMp3:
+		return "mp3"
+	case MIMEM4a:
+		return "m4a"
+	default:
+		return strings.TrimPrefix(string(M), "audio/")
+	}
+}
+
 func (M MIME) ImageFormat() string {
 	value := strings.TrimPrefix(string(M), "image/")
 	if value == "jpg" {
@@ -77,6 +125,18 @@ func (M MIME) ImageFormat() string {
 type Content struct {
 	Data []byte
 	Mime MIME
+	Name string // optional original file name
+}
+
+// rough estimation of tokens for one image
+const imageTokens = 1000
+
+// EstimateTokens roughly: ~4 characters per token for text, fixed size for images.
+func (c Content) EstimateTokens() int {
+	if c.Mime.IsImage() {
+		return imageTokens
+	}
+	return len(c.Data)/4 + 1
 }
 
 func (msg *Content) String() string {
@@ -120,7 +180,38 @@ type Message struct {
 	ToolName string
 	Role     Role
 	User     string
-	Content  Content
+	Parts    []Content // ordered content (ex: caption and image). Tool calls and results have exactly one part
+	// Signature of reasoning issued by provider (Claude). Signed reasoning is sent back to continue after tool calls.
+	// It's not stored in threads.
+	Signature string
+}
+
+// Content returns the first part. Convenient for single-part messages (tool calls and results).
+func (msg *Message) Content() Content {
+	if len(msg.Parts) == 0 {
+		return Content{}
+	}
+	return msg.Parts[0]
+}
+
+// Text of all text parts joined by new line.
+func (msg *Message) Text() string {
+	var texts []string
+	for _, part := range msg.Parts {
+		if part.Mime.IsText() {
+			texts = append(texts, string(part.Data))
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// EstimateTokens of all parts.
+func (msg *Message) EstimateTokens() int {
+	var n int
+	for _, part := range msg.Parts {
+		n += part.EstimateTokens()
+	}
+	return n
 }
 
 type ToolCall struct {
@@ -149,6 +240,12 @@ type Config struct {
 	Prompt    string `json:"prompt" yaml:"prompt"`
 	MaxTokens int    `json:"max_tokens" yaml:"maxTokens"`
 	ForceJSON bool   `json:"force_json" yaml:"forceJSON"`
+	// ReasoningEffort for models with effort-based reasoning (OpenAI o-series): low, medium or high. Empty means model default.
+	ReasoningEffort string `json:"reasoning_effort,omitempty" yaml:"reasoningEffort,omitempty"`
+	// ReasoningBudget is max number of thinking tokens for models with budget-based reasoning (Claude). 0 disables thinking.
+	ReasoningBudget int `json:"reasoning_budget,omitempty" yaml:"reasoningBudget,omitempty"`
+	// Cache stable prefix (system prompt and tools) on provider side where caching is explicit (Bedrock, Gemini).
+	Cache bool `json:"cache,omitempty" yaml:"cache,omitempty"`
 }
 
 type Request struct {
@@ -175,6 +272,7 @@ type Invoke struct {
 	InputToken  int
 	OutputToken int
 	TotalToken  int
+	CachedToken int // input tokens read from prompt cache, included in InputToken
 }
 
 func (inv *Invoke) ToolCalls() []Message {
@@ -191,3 +289,33 @@ func (inv *Invoke) ToolCalls() []Message {
 type Provider interface {
 	Invoke(ctx context.Context, config Config, messages []Message, tools []ToolDefinition) (*Invoke, error)
 }
+
+// Transcriber is optional interface for providers which can convert audio to text.
+type Transcriber interface {
+	Transcribe(ctx context.Context, config TranscribeConfig, audio Content) (string, error)
+}
+
+// Moderator is optional interface for providers which can classify harmful content.
+// Returns names of flagged categories, empty if content is allowed.
+type Moderator interface {
+	Moderate(ctx context.Context, model string, text string) ([]string, error)
+}
+
+// TranscribeConfig for speech-to-text models.
+type TranscribeConfig struct {
+	Model    string `json:"model" yaml:"model"`
+	Language string `json:"language,omitempty" yaml:"language,omitempty"` // ISO-639-1 input language, improves accuracy and latency
+	Prompt   string `json:"prompt,omitempty" yaml:"prompt,omitempty"`     // optional text to guide model style or continue previous segment
+}
+
+// Embeddings of input texts. Vectors are in the same order as input.
+type Embeddings struct {
+	Vectors    [][]float32
+	InputToken int
+	TotalToken int
+}
+
+// Embedder is optional interface for providers which can generate embeddings.
+type Embedder interface {
+	Embed(ctx context.Context, model string, input []string) (*Embeddings, error)
+}
@@ -27,6 +27,22 @@ const (
 	MIMEWebp MIME = "image/webp"
 	// MIMEGif is a MIME of type gif.
 	MIMEGif MIME = "image/gif"
+	// MIMEMp3 is a MIME of type mp3.
+	MIMEMp3 MIME = "audio/mpeg"
+	// MIMEOgg is a MIME of type ogg.
+	MIMEOgg MIME = "audio/ogg"
+	// MIMEWav is a MIME of type wav.
+	MIMEWav MIME = "audio/wav"
+	// MIMEM4a is a MIME of type m4a.
+	MIMEM4a MIME = "audio/mp4"
+	// MIMEPdf is a MIME of type pdf.
+	MIMEPdf MIME = "application/pdf"
+	// MIMEMarkdown is a MIME of type markdown.
+	MIMEMarkdown MIME = "text/markdown"
+	// MIMECsv is a MIME of type csv.
+	MIMECsv MIME = "text/csv"
+	// MIMEHtml is a MIME of type html.
+	MIMEHtml MIME = "text/html"
 )
 
 var ErrInvalidMIME = errors.New("not a valid MIME")
@@ -41,6 +57,14 @@ func MIMEValues() []MIME {
 		MIMEJpg,
 		MIMEWebp,
 		MIMEGif,
+		MIMEMp3,
+		MIMEOgg,
+		MIMEWav,
+		MIMEM4a,
+		MIMEPdf,
+		MIMEMarkdown,
+		MIMECsv,
+		MIMEHtml,
 	}
 }
 
@@ -64,6 +88,14 @@ var _MIMEValue = map[string]MIME{
 	"image/jpg":        MIMEJpg,
 	"image/webp":       MIMEWebp,
 	"image/gif":        MIMEGif,
+	"audio/mpeg":       MIMEMp3,
+	"audio/ogg":        MIMEOgg,
+	"audio/wav":        MIMEWav,
+	"audio/mp4":        MIMEM4a,
+	"application/pdf":  MIMEPdf,
+	"text/markdown":    MIMEMarkdown,
+	"text/csv":         MIMECsv,
+	"text/html":        MIMEHtml,
 }
 
 // ParseMIME attempts to convert a string to a MIME.
@@ -123,6 +155,10 @@ const (
 	RoleToolCall Role = "toolCall"
 	// RoleToolResult is a Role of type toolResult.
 	RoleToolResult Role = "toolResult"
+	// RoleReasoning is a Role of type reasoning.
+	RoleReasoning Role = "reasoning"
+	// RoleNote is a Role of type note.
+	RoleNote Role = "note"
 )
 
 var ErrInvalidRole = errors.New("not a valid Role")
@@ -134,6 +170,8 @@ func RoleValues() []Role {
 		RoleAssistant,
 		RoleToolCall,
 		RoleToolResult,
+		RoleReasoning,
+		RoleNote,
 	}
 }
 
@@ -154,6 +192,8 @@ var _RoleValue = map[string]Role{
 	"assistant":  RoleAssistant,
 	"toolCall":   RoleToolCall,
 	"toolResult": RoleToolResult,
+	"reasoning":  RoleReasoning,
+	"note":       RoleNote,
 }
 
 // ParseRole attempts to convert a string to a Role.
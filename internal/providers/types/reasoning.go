@@ -0,0 +1,37 @@
+package types
+
+import (
+	"strings"
+)
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// SplitThinking separates reasoning wrapped by <think></think> tags (DeepSeek R1, QwQ and similar open models)
+// from the answer. Text without leading tag is returned as is. Unclosed tag (ex: output cut by max tokens)
+// means whole text is reasoning.
+func SplitThinking(text string) (reasoning, answer string) {
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+	if !strings.HasPrefix(trimmed, thinkOpen) {
+		return "", text
+	}
+	trimmed = trimmed[len(thinkOpen):]
+	reasoning, answer, found := strings.Cut(trimmed, thinkClose)
+	if !found {
+		return strings.TrimSpace(trimmed), ""
+	}
+	return strings.TrimSpace(reasoning), strings.TrimSpace(answer)
+}
+
+// Reasoning message from text. Returns false if text is empty.
+func Reasoning(text string) (Message, bool) {
+	if strings.TrimSpace(text) == "" {
+		return Message{}, false
+	}
+	return Message{
+		Role:  RoleReasoning,
+		Parts: []Content{Text(text)},
+	}, true
+}
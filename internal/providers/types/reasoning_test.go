@@ -0,0 +1,23 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func TestSplitThinking(t *testing.T) {
+	reasoning, answer := types.SplitThinking("\n<think>\nsky is blue because...\n</think>\n\nRayleigh scattering")
+	assert.Equal(t, "sky is blue because...", reasoning)
+	assert.Equal(t, "Rayleigh scattering", answer)
+
+	reasoning, answer = types.SplitThinking("plain answer with <think> inside")
+	assert.Empty(t, reasoning)
+	assert.Equal(t, "plain answer with <think> inside", answer)
+
+	reasoning, answer = types.SplitThinking("<think>cut by max tokens")
+	assert.Equal(t, "cut by max tokens", reasoning)
+	assert.Empty(t, answer)
+}
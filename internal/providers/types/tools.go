@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sync/atomic"
 
 	"github.com/invopop/jsonschema"
@@ -38,6 +39,80 @@ func MustTool[In any](name string, description string, callable func(ctx context
 	}
 }
 
+// ParseSchema converts schema defined in config (ex: YAML map) to JSON schema. Empty input means any object.
+func ParseSchema(input map[string]any) (*jsonschema.Schema, error) {
+	if len(input) == 0 {
+		return &jsonschema.Schema{Type: "object"}, nil
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// MergeAllOf returns schema with allOf parts (recursively) merged into single schema: properties and required fields
+// are combined, later parts override properties with the same name, other attributes are taken from the first schema
+// which defines them. Refs in parts should be resolved before. Schema without allOf is returned as-is.
+func MergeAllOf(schema *jsonschema.Schema) *jsonschema.Schema {
+	if schema == nil || len(schema.AllOf) == 0 {
+		return schema
+	}
+	out := *schema
+	out.AllOf = nil
+	out.Required = slices.Clone(schema.Required)
+	if schema.Properties != nil {
+		out.Properties = jsonschema.NewProperties()
+		for item := schema.Properties.Oldest(); item != nil; item = item.Next() {
+			out.Properties.Set(item.Key, item.Value)
+		}
+	}
+	for _, part := range schema.AllOf {
+		part = MergeAllOf(part)
+		if part == nil {
+			continue
+		}
+		if out.Type == "" {
+			out.Type = part.Type
+		}
+		if out.Format == "" {
+			out.Format = part.Format
+		}
+		if out.Description == "" {
+			out.Description = part.Description
+		}
+		if out.Enum == nil {
+			out.Enum = part.Enum
+		}
+		if out.Items == nil {
+			out.Items = part.Items
+		}
+		if part.Properties != nil {
+			if out.Properties == nil {
+				out.Properties = jsonschema.NewProperties()
+			}
+			for item := part.Properties.Oldest(); item != nil; item = item.Next() {
+				out.Properties.Set(item.Key, item.Value)
+			}
+		}
+		for _, name := range part.Required {
+			if !slices.Contains(out.Required, name) {
+				out.Required = append(out.Required, name)
+			}
+		}
+		out.AnyOf = append(slices.Clip(out.AnyOf), part.AnyOf...)
+		out.OneOf = append(slices.Clip(out.OneOf), part.OneOf...)
+	}
+	if out.Type == "" && out.Properties != nil {
+		out.Type = "object"
+	}
+	return &out
+}
+
 type simpleTool struct {
 	name        string
 	description string
@@ -128,3 +203,35 @@ func (st *DynamicToolbox) Add(tools ...Tool) {
 		return tools, nil
 	})
 }
+
+type callerKey struct{}
+
+// Caller of tools: thread (empty for stateless runs) and user of the current run.
+type Caller struct {
+	Thread string
+	User   string
+}
+
+// WithCaller stores caller in context, so tools can apply access rules.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom context. Empty caller returned if not set.
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+type attachmentsKey struct{}
+
+// WithAttachments stores files of the conversation in context, so tools can send them (ex: uploads).
+func WithAttachments(ctx context.Context, attachments []Content) context.Context {
+	return context.WithValue(ctx, attachmentsKey{}, attachments)
+}
+
+// AttachmentsFrom context in order of appearance. Nil if not set.
+func AttachmentsFrom(ctx context.Context) []Content {
+	attachments, _ := ctx.Value(attachmentsKey{}).([]Content)
+	return attachments
+}
@@ -0,0 +1,38 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+func TestMergeAllOf(t *testing.T) {
+	base := &jsonschema.Schema{Type: "object", Description: "Base", Required: []string{"id"}, Properties: jsonschema.NewProperties()}
+	base.Properties.Set("id", &jsonschema.Schema{Type: "integer"})
+	base.Properties.Set("name", &jsonschema.Schema{Type: "string"})
+
+	extra := &jsonschema.Schema{Required: []string{"id", "age"}, Properties: jsonschema.NewProperties()}
+	extra.Properties.Set("name", &jsonschema.Schema{Type: "string", Description: "Full name"})
+	extra.Properties.Set("age", &jsonschema.Schema{Type: "integer"})
+
+	root := &jsonschema.Schema{Description: "Person", AllOf: []*jsonschema.Schema{base, {AllOf: []*jsonschema.Schema{extra}}}}
+	merged := types.MergeAllOf(root)
+
+	require.NotSame(t, root, merged)
+	assert.Len(t, root.AllOf, 2) // source is not changed
+	assert.Empty(t, merged.AllOf)
+	assert.Equal(t, "object", merged.Type)
+	assert.Equal(t, "Person", merged.Description)
+	assert.Equal(t, []string{"id", "age"}, merged.Required)
+	assert.Equal(t, 3, merged.Properties.Len())
+	name, _ := merged.Properties.Get("name")
+	assert.Equal(t, "Full name", name.Description)
+	assert.Equal(t, 2, base.Properties.Len())
+
+	plain := &jsonschema.Schema{Type: "string"}
+	assert.Same(t, plain, types.MergeAllOf(plain))
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+// ToolChat is the name of MCP tool which runs brain on thread.
+const ToolChat = "chat"
+
+// DefaultMCPUser is the user of messages sent by MCP clients, unless specified in request.
+const DefaultMCPUser = "mcp"
+
+type chatRequest struct {
+	Thread  string `json:"thread" jsonschema:"description=Thread name. Conversation history is kept per thread"`
+	Message string `json:"message" jsonschema:"description=Message to the assistant"`
+	User    string `json:"user,omitempty" jsonschema:"description=Optional user name"`
+}
+
+// ChatTool for MCP clients: adds message to thread and runs brain (same as POST /{thread}).
+func (srv *Server) ChatTool() types.Tool {
+	return types.MustTool(ToolChat, "Send message to the assistant in conversation thread and get reply", srv.chat)
+}
+
+func (srv *Server) chat(ctx context.Context, req chatRequest) (types.Content, error) {
+	if strings.TrimSpace(req.Thread) == "" {
+		return types.Content{}, fmt.Errorf("thread is required")
+	}
+	if err := srv.checkThread(req.Thread); err != nil {
+		return types.Content{}, err
+	}
+	if req.User == "" {
+		req.User = DefaultMCPUser
+	}
+	ctx, cancel := context.WithTimeout(ctx, srv.Timeout)
+	defer cancel()
+
+	res, err := srv.Brain.Chat(ctx, req.Thread, types.Message{
+		Role:  types.RoleUser,
+		User:  req.User,
+		Parts: []types.Content{types.Text(req.Message)},
+	})
+	if err != nil {
+		return types.Content{}, err
+	}
+	slog.Info("complete", "source", "mcp", "thread", req.Thread, "input", res.TotalInputTokens(), "output", res.TotalOutputTokens(), "total", res.TotalTokens(), "cached", res.TotalCachedTokens())
+	return res.Reply(), nil
+}
@@ -1,17 +1,26 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/pikocloud/pikobrain/internal/brain"
+	"github.com/pikocloud/pikobrain/internal/moderation"
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
 	"github.com/pikocloud/pikobrain/internal/providers/types"
 	"github.com/pikocloud/pikobrain/internal/utils"
 )
@@ -21,6 +30,8 @@ const (
 	HeaderUser = "X-User"
 	QueryUser  = "user"
 	QueryRole  = "role"
+	// QueryReasoning (true/false) adds model reasoning to the reply as multipart/mixed response.
+	QueryReasoning = "reasoning"
 )
 
 const (
@@ -28,12 +39,28 @@ const (
 	HeaderRunInputTokens  = "X-Run-Input-Tokens"  // total input tokens
 	HeaderRunOutputTokens = "X-Run-Output-Tokens" // total output tokens
 	HeaderRunTotalTokens  = "X-Run-Total-Tokens"  // total "total" tokens
+	HeaderRunCachedTokens = "X-Run-Cached-Tokens" // total input tokens read from prompt cache
 	HeaderRunContext      = "X-Run-Context"       // total number of messages
 )
 
+// ReservedThreads are names of built-in routes which shadow threads with the same name (POST /embeddings).
+var ReservedThreads = []string{"embeddings"}
+
+// ErrReservedThread returned if thread name is used by another route.
+var ErrReservedThread = errors.New("thread name is reserved")
+
 type Server struct {
-	Brain   *brain.Brain
-	Timeout time.Duration
+	Brain    *brain.Brain
+	Timeout  time.Duration
+	Reserved []string // thread names which can not be used (see [ReservedThreads])
+}
+
+// checkThread returns error if thread name is reserved.
+func (srv *Server) checkThread(thread string) error {
+	if slices.Contains(srv.Reserved, thread) {
+		return fmt.Errorf("%w: %q", ErrReservedThread, thread)
+	}
+	return nil
 }
 
 func (srv *Server) Run(writer http.ResponseWriter, request *http.Request) {
@@ -54,24 +81,35 @@ func (srv *Server) Run(writer http.ResponseWriter, request *http.Request) {
 
 	if err != nil {
 		slog.Error("Failed to execute request", "error", err)
-		writer.WriteHeader(http.StatusInternalServerError)
+		writer.WriteHeader(statusCode(err))
 		_, _ = writer.Write([]byte(err.Error()))
 		return
 	}
 
-	reply := res.Reply()
-	writer.Header().Set("Content-Type", string(reply.Mime))
-	writer.Header().Set("Content-Length", strconv.Itoa(len(reply.Data)))
+	contentType, body, err := replyBody(request, res)
+	if err != nil {
+		slog.Error("Failed to encode reply", "error", err)
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write([]byte(err.Error()))
+		return
+	}
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Content-Length", strconv.Itoa(len(body)))
 	setHeaders(writer, duration, res, messages)
 
 	writer.WriteHeader(http.StatusOK)
-	_, _ = writer.Write(reply.Data)
+	_, _ = writer.Write(body)
 
-	slog.Info("complete", "duration", duration, "input", res.TotalInputTokens(), "output", res.TotalOutputTokens(), "total", res.TotalTokens())
+	slog.Info("complete", "duration", duration, "input", res.TotalInputTokens(), "output", res.TotalOutputTokens(), "total", res.TotalTokens(), "cached", res.TotalCachedTokens())
 }
 
 func (srv *Server) Append(writer http.ResponseWriter, request *http.Request) {
 	thread := request.PathValue("thread")
+	if err := srv.checkThread(thread); err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(err.Error()))
+		return
+	}
 
 	messages, err := parseRequest(request)
 	if err != nil {
@@ -90,7 +128,7 @@ func (srv *Server) Append(writer http.ResponseWriter, request *http.Request) {
 
 	if err != nil {
 		slog.Error("Failed to execute request", "error", err)
-		writer.WriteHeader(http.StatusInternalServerError)
+		writer.WriteHeader(statusCode(err))
 		_, _ = writer.Write([]byte(err.Error()))
 		return
 	}
@@ -105,6 +143,11 @@ func (srv *Server) Append(writer http.ResponseWriter, request *http.Request) {
 
 func (srv *Server) Chat(writer http.ResponseWriter, request *http.Request) {
 	thread := request.PathValue("thread")
+	if err := srv.checkThread(thread); err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(err.Error()))
+		return
+	}
 
 	messages, err := parseRequest(request)
 	if err != nil {
@@ -123,21 +166,111 @@ func (srv *Server) Chat(writer http.ResponseWriter, request *http.Request) {
 
 	if err != nil {
 		slog.Error("Failed to execute request", "error", err)
-		writer.WriteHeader(http.StatusInternalServerError)
+		writer.WriteHeader(statusCode(err))
 		_, _ = writer.Write([]byte(err.Error()))
 		return
 	}
 
-	reply := res.Reply()
+	contentType, body, err := replyBody(request, res)
+	if err != nil {
+		slog.Error("Failed to encode reply", "error", err)
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write([]byte(err.Error()))
+		return
+	}
 	writer.Header().Set("Hx-Redirect", ".")
-	writer.Header().Set("Content-Type", string(reply.Mime))
-	writer.Header().Set("Content-Length", strconv.Itoa(len(reply.Data)))
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Content-Length", strconv.Itoa(len(body)))
 	setHeaders(writer, duration, res, messages)
 
 	writer.WriteHeader(http.StatusOK)
-	_, _ = writer.Write(reply.Data)
+	_, _ = writer.Write(body)
+
+	slog.Info("complete", "duration", duration, "input", res.TotalInputTokens(), "output", res.TotalOutputTokens(), "total", res.TotalTokens(), "cached", res.TotalCachedTokens())
+}
+
+func (srv *Server) Embeddings(writer http.ResponseWriter, request *http.Request) {
+	messages, err := parseRequest(request)
+	if err != nil {
+		slog.Error("Failed to parse request", "error", err)
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(err.Error()))
+		return
+	}
+
+	var input = make([]string, 0, len(messages))
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if !part.Mime.IsText() {
+				writer.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = writer.Write([]byte("only text content supported for embeddings"))
+				return
+			}
+			input = append(input, string(part.Data))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), srv.Timeout)
+	defer cancel()
+
+	started := time.Now()
+	res, err := srv.Brain.Embed(ctx, input)
+	duration := time.Since(started)
+
+	if err != nil {
+		slog.Error("Failed to execute request", "error", err)
+		writer.WriteHeader(statusCode(err))
+		_, _ = writer.Write([]byte(err.Error()))
+		return
+	}
+
+	data, err := json.Marshal(embeddingsResponse{
+		Model:      srv.Brain.Definition().Embedding.Model,
+		Embeddings: res.Vectors,
+	})
+	if err != nil {
+		slog.Error("Failed to encode response", "error", err)
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	writer.Header().Set(HeaderRunDuration, strconv.FormatFloat(duration.Seconds(), 'f', -1, 64))
+	writer.Header().Set(HeaderRunInputTokens, strconv.Itoa(res.InputToken))
+	writer.Header().Set(HeaderRunOutputTokens, "0")
+	writer.Header().Set(HeaderRunTotalTokens, strconv.Itoa(res.TotalToken))
+	writer.Header().Set(HeaderRunContext, strconv.Itoa(len(messages)))
+
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(data)
+
+	slog.Info("embeddings generated", "duration", duration, "input", res.InputToken, "total", res.TotalToken, "messages", len(messages))
+}
+
+// RateLimit returns stats of provider rate limiter as JSON.
+func (srv *Server) RateLimit(writer http.ResponseWriter, _ *http.Request) {
+	stats, ok := srv.Brain.RateLimit()
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte("rate limit is not configured"))
+		return
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		slog.Error("Failed to encode response", "error", err)
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(data)
+}
 
-	slog.Info("complete", "duration", duration, "input", res.TotalInputTokens(), "output", res.TotalOutputTokens(), "total", res.TotalTokens())
+type embeddingsResponse struct {
+	Model      string      `json:"model"`
+	Embeddings [][]float32 `json:"embeddings"`
 }
 
 func setHeaders(writer http.ResponseWriter, duration time.Duration, res brain.Response, messages []types.Message) {
@@ -145,9 +278,47 @@ func setHeaders(writer http.ResponseWriter, duration time.Duration, res brain.Re
 	writer.Header().Set(HeaderRunInputTokens, strconv.Itoa(res.TotalInputTokens()))
 	writer.Header().Set(HeaderRunOutputTokens, strconv.Itoa(res.TotalOutputTokens()))
 	writer.Header().Set(HeaderRunTotalTokens, strconv.Itoa(res.TotalTokens()))
+	writer.Header().Set(HeaderRunCachedTokens, strconv.Itoa(res.TotalCachedTokens()))
 	writer.Header().Set(HeaderRunContext, strconv.Itoa(len(messages)))
 }
 
+// replyBody returns reply of model. If reasoning requested and exists, multipart/mixed response returned:
+// reasoning parts go first, reply is the last part. Role of each part is in [HeaderRole].
+func replyBody(request *http.Request, res brain.Response) (string, []byte, error) {
+	reply := res.Reply()
+	withReasoning, _ := strconv.ParseBool(request.URL.Query().Get(QueryReasoning))
+	reasoning := res.Reasoning()
+	if !withReasoning || len(reasoning) == 0 {
+		return string(reply.Mime), reply.Data, nil
+	}
+
+	var buf bytes.Buffer
+	out := multipart.NewWriter(&buf)
+	writePart := func(role types.Role, content types.Content) error {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Type", string(content.Mime))
+		header.Set(HeaderRole, role.String())
+		w, err := out.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content.Data)
+		return err
+	}
+	for _, msg := range reasoning {
+		if err := writePart(types.RoleReasoning, types.Text(msg.Text())); err != nil {
+			return "", nil, fmt.Errorf("write reasoning: %w", err)
+		}
+	}
+	if err := writePart(types.RoleAssistant, reply); err != nil {
+		return "", nil, fmt.Errorf("write reply: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", nil, fmt.Errorf("close multipart: %w", err)
+	}
+	return "multipart/mixed; boundary=" + out.Boundary(), buf.Bytes(), nil
+}
+
 func parseRequest(request *http.Request) ([]types.Message, error) {
 	baseRole, err := getRole(request.URL.Query().Get(QueryRole), types.RoleUser)
 	if err != nil {
@@ -169,11 +340,12 @@ func parseRequest(request *http.Request) ([]types.Message, error) {
 	if err != nil {
 		return nil, fmt.Errorf("parse payload: %v", err)
 	}
+	content.Name = fileName(request.Header.Get("Content-Disposition"))
 
 	return []types.Message{{
-		Role:    baseRole,
-		User:    baseUser,
-		Content: content,
+		Role:  baseRole,
+		User:  baseUser,
+		Parts: []types.Content{content},
 	}}, nil
 }
 
@@ -217,10 +389,16 @@ func readMultipart(request *http.Request, baseUser string, baseRole types.Role)
 		if err != nil {
 			return nil, fmt.Errorf("parse payload: %w", err)
 		}
+		content.Name = part.FileName()
+		// parts in a row from the same author are single message (ex: image with caption)
+		if n := len(ans); n > 0 && ans[n-1].Role == role && ans[n-1].User == user {
+			ans[n-1].Parts = append(ans[n-1].Parts, content)
+			continue
+		}
 		ans = append(ans, types.Message{
-			Role:    role,
-			User:    user,
-			Content: content,
+			Role:  role,
+			User:  user,
+			Parts: []types.Content{content},
 		})
 	}
 	return ans, nil
@@ -242,6 +420,22 @@ func parsePayload(contentType string, body []byte) (types.Content, error) {
 		return types.Content{Data: body, Mime: types.MIMEGif}, nil
 	case "image/webp":
 		return types.Content{Data: body, Mime: types.MIMEWebp}, nil
+	case "audio/mpeg", "audio/mp3":
+		return types.Content{Data: body, Mime: types.MIMEMp3}, nil
+	case "audio/ogg":
+		return types.Content{Data: body, Mime: types.MIMEOgg}, nil
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return types.Content{Data: body, Mime: types.MIMEWav}, nil
+	case "audio/mp4", "audio/m4a", "audio/x-m4a":
+		return types.Content{Data: body, Mime: types.MIMEM4a}, nil
+	case "application/pdf":
+		return types.Content{Data: body, Mime: types.MIMEPdf}, nil
+	case "text/markdown", "text/x-markdown":
+		return types.Content{Data: body, Mime: types.MIMEMarkdown}, nil
+	case "text/csv":
+		return types.Content{Data: body, Mime: types.MIMECsv}, nil
+	case "text/html":
+		return types.Content{Data: body, Mime: types.MIMEHtml}, nil
 	case "application/x-www-form-urlencoded":
 		value, err := url.QueryUnescape(string(body))
 		if err != nil {
@@ -253,6 +447,34 @@ func parsePayload(contentType string, body []byte) (types.Content, error) {
 	}
 }
 
+// fileName from Content-Disposition header (if set).
+func fileName(contentDisposition string) string {
+	if contentDisposition == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(contentDisposition)
+	if err != nil || params["filename"] == "" {
+		return ""
+	}
+	return filepath.Base(params["filename"])
+}
+
+// statusCode maps execution error to HTTP status code.
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, brain.ErrUnsupportedContent):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, brain.ErrEmbeddingDisabled):
+		return http.StatusNotImplemented
+	case errors.Is(err, ratelimit.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, moderation.ErrBlocked):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func getRole(v string, base types.Role) (types.Role, error) {
 	if v == "" {
 		return base, nil
@@ -0,0 +1,171 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/brain"
+	"github.com/pikocloud/pikobrain/internal/ent"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/server"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+// newTestBrain backed by OpenAI-compatible server. Definition can be adjusted by fn.
+func newTestBrain(t *testing.T, providerURL string, fn func(definition *brain.Definition)) *brain.Brain {
+	t.Helper()
+	ctx := context.Background()
+	db, err := ent.New(ctx, ent.Config{
+		URL:          "sqlite://:memory:?cache=shared&_fk=1&_pragma=foreign_keys(1)",
+		MaxConn:      3,
+		IdleConn:     3,
+		IdleTimeout:  time.Minute,
+		ConnLifeTime: time.Hour,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	token := "test"
+	definition := brain.Default()
+	definition.URL = providerURL
+	definition.Secret = utils.Value[string]{Value: &token}
+	if fn != nil {
+		fn(&definition)
+	}
+	b, err := brain.New(ctx, db, &types.DynamicToolbox{}, definition)
+	require.NoError(t, err)
+	return b
+}
+
+func TestServer_Run_headers(t *testing.T) {
+	// OpenAI-compatible stub which reports cached prompt tokens
+	provider := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{
+			"id": "test",
+			"object": "chat.completion",
+			"model": "gpt-4o-mini",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "pong"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 1200, "completion_tokens": 3, "total_tokens": 1203, "prompt_tokens_details": {"cached_tokens": 1024}}
+		}`))
+	}))
+	defer provider.Close()
+
+	b := newTestBrain(t, provider.URL, nil)
+
+	srv := &server.Server{Brain: b, Timeout: time.Minute}
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("ping"))
+	request.Header.Set("Content-Type", "text/plain")
+	recorder := httptest.NewRecorder()
+	srv.Run(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "pong", recorder.Body.String())
+	assert.Equal(t, "1200", recorder.Header().Get(server.HeaderRunInputTokens))
+	assert.Equal(t, "1024", recorder.Header().Get(server.HeaderRunCachedTokens))
+	assert.Equal(t, "3", recorder.Header().Get(server.HeaderRunOutputTokens))
+	assert.Equal(t, "1203", recorder.Header().Get(server.HeaderRunTotalTokens))
+}
+
+func TestServer_reservedThreads(t *testing.T) {
+	srv := &server.Server{Timeout: time.Minute, Reserved: []string{"embeddings", "mcp"}}
+
+	for _, handler := range []http.HandlerFunc{srv.Chat, srv.Append} {
+		request := httptest.NewRequest(http.MethodPost, "/embeddings/", strings.NewReader("hello"))
+		request.SetPathValue("thread", "embeddings")
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), server.ErrReservedThread.Error())
+	}
+
+	_, err := srv.ChatTool().Call(context.Background(), []byte(`{"thread": "mcp", "message": "hello"}`))
+	require.ErrorIs(t, err, server.ErrReservedThread)
+}
+
+func TestServer_Embeddings(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/embeddings", request.URL.Path)
+		var req struct {
+			Input []string `json:"input"`
+			Model string   `json:"model"`
+		}
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&req))
+		assert.Equal(t, []string{"hello", "world"}, req.Input)
+		assert.Equal(t, "text-embedding-3-small", req.Model)
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{
+			"object": "list",
+			"model": "text-embedding-3-small",
+			"data": [{"object": "embedding", "index": 1, "embedding": [0.3, 0.4]}, {"object": "embedding", "index": 0, "embedding": [0.1, 0.2]}],
+			"usage": {"prompt_tokens": 2, "total_tokens": 2}
+		}`))
+	}))
+	defer provider.Close()
+
+	t.Run("success", func(t *testing.T) {
+		b := newTestBrain(t, provider.URL, func(definition *brain.Definition) {
+			definition.Embedding = &brain.Embedding{Model: "text-embedding-3-small"}
+		})
+		srv := &server.Server{Brain: b, Timeout: time.Minute}
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		require.NoError(t, form.WriteField("first", "hello"))
+		require.NoError(t, form.WriteField("second", "world"))
+		require.NoError(t, form.Close())
+
+		request := httptest.NewRequest(http.MethodPost, "/embeddings", &body)
+		request.Header.Set("Content-Type", form.FormDataContentType())
+		recorder := httptest.NewRecorder()
+		srv.Embeddings(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "2", recorder.Header().Get(server.HeaderRunInputTokens))
+		assert.Equal(t, "2", recorder.Header().Get(server.HeaderRunTotalTokens))
+		assert.JSONEq(t, `{"model": "text-embedding-3-small", "embeddings": [[0.1, 0.2], [0.3, 0.4]]}`, recorder.Body.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv := &server.Server{Brain: newTestBrain(t, provider.URL, nil), Timeout: time.Minute}
+		request := httptest.NewRequest(http.MethodPost, "/embeddings", strings.NewReader("hello"))
+		request.Header.Set("Content-Type", "text/plain")
+		recorder := httptest.NewRecorder()
+		srv.Embeddings(recorder, request)
+
+		assert.Equal(t, http.StatusNotImplemented, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), brain.ErrEmbeddingDisabled.Error())
+	})
+
+	t.Run("bad payload", func(t *testing.T) {
+		b := newTestBrain(t, provider.URL, func(definition *brain.Definition) {
+			definition.Embedding = &brain.Embedding{Model: "text-embedding-3-small"}
+		})
+		srv := &server.Server{Brain: b, Timeout: time.Minute}
+
+		// multipart without boundary can not be parsed
+		request := httptest.NewRequest(http.MethodPost, "/embeddings", strings.NewReader("hello"))
+		request.Header.Set("Content-Type", "multipart/form-data")
+		recorder := httptest.NewRecorder()
+		srv.Embeddings(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		// only text can be embedded
+		request = httptest.NewRequest(http.MethodPost, "/embeddings", strings.NewReader("PNG"))
+		request.Header.Set("Content-Type", "image/png")
+		recorder = httptest.NewRecorder()
+		srv.Embeddings(recorder, request)
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+}
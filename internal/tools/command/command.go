@@ -0,0 +1,322 @@
+// Package command provides tools which run local executables.
+//
+// Arguments are rendered as Go templates from tool arguments (or passed as JSON on stdin), stdout is the result.
+// Non-zero exit code is returned as tool error with the tail of stderr.
+//
+// Values of tool arguments can not start with "-" (to prevent injection of options) and required arguments
+// can not be empty (to keep positions of arguments).
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/invopop/jsonschema"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxResponse = 1024 * 1024
+	maxStderr          = 4096 // tail of stderr in errors
+)
+
+type Config struct {
+	Namespace   string        `json:"namespace" yaml:"namespace,omitempty"`      // Prefix all tools with value and underscore
+	Timeout     time.Duration `json:"timeout" yaml:"timeout,omitempty"`          // Max duration of single call, process is killed after. Default is 30s
+	MaxResponse int           `json:"max_response" yaml:"maxResponse,omitempty"` // Max size of stdout in bytes. Default is 1MiB
+	Tools       []ToolConfig  `json:"tools" yaml:"tools"`
+}
+
+type ToolConfig struct {
+	Name        string               `json:"name" yaml:"name"`
+	Description string               `json:"description" yaml:"description"`
+	Input       map[string]any       `json:"input" yaml:"input"`                                  // JSON schema of arguments (object)
+	Command     string               `json:"command" yaml:"command"`                              // Executable name or path
+	Args        []string             `json:"args,omitempty" yaml:"args,omitempty"`                // Arguments as Go templates over tool arguments. Missing optional arguments are empty strings, empty results are omitted. Values can not start with "-", required values can not be empty
+	Stdin       bool                 `json:"stdin" yaml:"stdin,omitempty"`                        // Pass tool arguments as JSON to stdin
+	Dir         string               `json:"dir,omitempty" yaml:"dir,omitempty"`                  // Working directory. Default is current directory
+	Env         []utils.Pair[string] `json:"env,omitempty" yaml:"env,omitempty"`                  // Extra environment variables
+	InheritEnv  bool                 `json:"inherit_env" yaml:"inheritEnv,omitempty"`             // Pass environment of the service to the process
+	Output      string               `json:"output,omitempty" yaml:"output,omitempty"`            // MIME type of stdout. Default is text/plain
+	Timeout     time.Duration        `json:"timeout,omitempty" yaml:"timeout,omitempty"`          // Overrides global timeout
+	MaxResponse int                  `json:"max_response,omitempty" yaml:"maxResponse,omitempty"` // Overrides global max response
+}
+
+// New tools from commands definitions. Executables are resolved at load time.
+func New(config Config) ([]types.Tool, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxResponse <= 0 {
+		config.MaxResponse = DefaultMaxResponse
+	}
+	var ans = make([]types.Tool, 0, len(config.Tools))
+	for _, def := range config.Tools {
+		tool, err := newTool(config, def)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q: %w", def.Name, err)
+		}
+		ans = append(ans, tool)
+	}
+	return ans, nil
+}
+
+func newTool(config Config, def ToolConfig) (*commandTool, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("name is not set")
+	}
+	if def.Command == "" {
+		return nil, fmt.Errorf("command is not set")
+	}
+	path, err := exec.LookPath(def.Command)
+	if err != nil {
+		return nil, fmt.Errorf("find command: %w", err)
+	}
+
+	var args = make([]*template.Template, 0, len(def.Args))
+	for i, arg := range def.Args {
+		t, err := template.New(fmt.Sprint("arg", i)).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("parse argument #%d: %w", i, err)
+		}
+		args = append(args, t)
+	}
+
+	var env []string
+	if def.InheritEnv {
+		env = os.Environ()
+	}
+	for _, pair := range def.Env {
+		value, err := pair.Get()
+		if err != nil {
+			return nil, fmt.Errorf("get env %q value: %w", pair.Name, err)
+		}
+		env = append(env, pair.Name+"="+value)
+	}
+	if env == nil {
+		env = []string{} // nil means inherit all
+	}
+
+	var mime = types.MIMEText
+	if def.Output != "" {
+		mime, err = types.ParseMIME(def.Output)
+		if err != nil {
+			return nil, fmt.Errorf("parse output: %w", err)
+		}
+	}
+
+	input, err := types.ParseSchema(def.Input)
+	if err != nil {
+		return nil, fmt.Errorf("parse input schema: %w", err)
+	}
+
+	// optional arguments are rendered as empty strings instead of "<no value>"
+	var optional []string
+	if input.Properties != nil {
+		for p := input.Properties.Oldest(); p != nil; p = p.Next() {
+			optional = append(optional, p.Key)
+		}
+	}
+
+	timeout := def.Timeout
+	if timeout <= 0 {
+		timeout = config.Timeout
+	}
+	maxResponse := def.MaxResponse
+	if maxResponse <= 0 {
+		maxResponse = config.MaxResponse
+	}
+
+	return &commandTool{
+		name:        utils.Concat("_", config.Namespace, def.Name),
+		description: def.Description,
+		input:       input,
+		path:        path,
+		args:        args,
+		optional:    optional,
+		required:    input.Required,
+		stdin:       def.Stdin,
+		dir:         def.Dir,
+		env:         env,
+		mime:        mime,
+		timeout:     timeout,
+		maxResponse: maxResponse,
+	}, nil
+}
+
+type commandTool struct {
+	name        string
+	description string
+	input       *jsonschema.Schema
+	path        string
+	args        []*template.Template
+	optional    []string
+	required    []string
+	stdin       bool
+	dir         string
+	env         []string
+	mime        types.MIME
+	timeout     time.Duration
+	maxResponse int
+}
+
+func (ct *commandTool) Name() string {
+	return ct.name
+}
+
+func (ct *commandTool) Description() string {
+	return ct.description
+}
+
+func (ct *commandTool) Input() *jsonschema.Schema {
+	return ct.input
+}
+
+func (ct *commandTool) Call(ctx context.Context, args json.RawMessage) (types.Content, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	var params map[string]any
+	if err := json.Unmarshal(args, &params); err != nil {
+		return types.Content{}, fmt.Errorf("parse arguments: %w", err)
+	}
+
+	if params == nil {
+		params = make(map[string]any)
+	}
+	for _, name := range ct.optional {
+		if _, ok := params[name]; !ok {
+			params[name] = ""
+		}
+	}
+
+	if len(ct.args) > 0 {
+		if err := ct.checkParams(params); err != nil {
+			return types.Content{}, err
+		}
+	}
+
+	var argv = make([]string, 0, len(ct.args))
+	for i, t := range ct.args {
+		var buf strings.Builder
+		if err := t.Execute(&buf, params); err != nil {
+			return types.Content{}, fmt.Errorf("render argument #%d: %w", i, err)
+		}
+		if buf.Len() > 0 {
+			argv = append(argv, buf.String())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ct.timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{left: ct.maxResponse}
+	stderr := &tailBuffer{size: maxStderr}
+
+	cmd := exec.CommandContext(ctx, ct.path, argv...)
+	cmd.Dir = ct.dir
+	cmd.Env = ct.env
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second // do not wait forever for children holding pipes
+	if ct.stdin {
+		cmd.Stdin = bytes.NewReader(args)
+	}
+
+	err := cmd.Run()
+	if errors.Is(stdout.err, utils.ErrStreamTooBig) {
+		return types.Content{}, fmt.Errorf("output is too big (limit %d bytes)", ct.maxResponse)
+	}
+	if ctx.Err() != nil {
+		return types.Content{}, fmt.Errorf("run command: %w", ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return types.Content{}, fmt.Errorf("command exited with code %d: %s", exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return types.Content{}, fmt.Errorf("run command: %w", err)
+	}
+	return types.Content{Data: stdout.buf.Bytes(), Mime: ct.mime}, nil
+}
+
+// checkParams which can be rendered to arguments.
+func (ct *commandTool) checkParams(params map[string]any) error {
+	for _, name := range ct.required {
+		if params[name] == "" {
+			return fmt.Errorf("argument %q is required and can not be empty", name)
+		}
+	}
+	for name, value := range params {
+		if hasOption(value) {
+			return fmt.Errorf("argument %q can not start with '-'", name)
+		}
+	}
+	return nil
+}
+
+// hasOption checks recursively if any string value looks like command line option.
+func hasOption(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.HasPrefix(v, "-")
+	case []any:
+		for _, item := range v {
+			if hasOption(item) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, item := range v {
+			if hasOption(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// limitedBuffer fails with [utils.ErrStreamTooBig] once limit exceeded.
+type limitedBuffer struct {
+	buf  bytes.Buffer
+	left int
+	err  error
+}
+
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if len(p) > lb.left {
+		lb.err = utils.ErrStreamTooBig
+		return 0, lb.err
+	}
+	lb.left -= len(p)
+	return lb.buf.Write(p)
+}
+
+// tailBuffer keeps only the last bytes.
+type tailBuffer struct {
+	data []byte
+	size int
+}
+
+func (tb *tailBuffer) Write(p []byte) (int, error) {
+	tb.data = append(tb.data, p...)
+	if extra := len(tb.data) - tb.size; extra > 0 {
+		tb.data = tb.data[extra:]
+	}
+	return len(p), nil
+}
+
+func (tb *tailBuffer) String() string {
+	return string(tb.data)
+}
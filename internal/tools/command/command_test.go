@@ -0,0 +1,137 @@
+package command_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/command"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+func TestNew(t *testing.T) {
+	secret := "s3cr3t"
+	tools, err := command.New(command.Config{
+		Namespace: "cli",
+		Tools: []command.ToolConfig{{
+			Name:        "echo",
+			Description: "Print words",
+			Input: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"word":  map[string]any{"type": "string"},
+					"extra": map[string]any{"type": "string"},
+				},
+			},
+			Command: "echo",
+			Args:    []string{"-n", "{{.word}}", "{{.extra}}"},
+		}, {
+			Name:    "stdin",
+			Command: "cat",
+			Stdin:   true,
+			Output:  "application/json",
+		}, {
+			Name:    "env",
+			Command: "sh",
+			Args:    []string{"-c", "printf %s \"$TOKEN\""},
+			Env:     []utils.Pair[string]{{Name: "TOKEN", Value: utils.Value[string]{Value: &secret}}},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, tools, 3)
+	assert.Equal(t, "cli_echo", tools[0].Name())
+
+	out, err := tools[0].Call(context.Background(), json.RawMessage(`{"word": "hello"}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEText, out.Mime)
+	assert.Equal(t, "hello", string(out.Data))
+
+	out, err = tools[1].Call(context.Background(), json.RawMessage(`{"x": 1}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEJson, out.Mime)
+	assert.JSONEq(t, `{"x": 1}`, string(out.Data))
+
+	out, err = tools[2].Call(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, secret, string(out.Data))
+}
+
+func TestCommand_errors(t *testing.T) {
+	tools, err := command.New(command.Config{
+		Timeout:     100 * time.Millisecond,
+		MaxResponse: 10,
+		Tools: []command.ToolConfig{{
+			Name:    "fail",
+			Command: "sh",
+			Args:    []string{"-c", "echo broken >&2; exit 3"},
+		}, {
+			Name:    "slow",
+			Command: "sleep",
+			Args:    []string{"5"},
+		}, {
+			Name:    "big",
+			Command: "sh",
+			Args:    []string{"-c", "echo 0123456789abcdef"},
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = tools[0].Call(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "code 3")
+	assert.Contains(t, err.Error(), "broken")
+
+	_, err = tools[1].Call(context.Background(), nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = tools[2].Call(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output is too big")
+
+	_, err = command.New(command.Config{Tools: []command.ToolConfig{{Name: "missing", Command: "definitely-not-existent-binary"}}})
+	require.Error(t, err)
+}
+
+func TestCommand_arguments(t *testing.T) {
+	tools, err := command.New(command.Config{
+		Tools: []command.ToolConfig{{
+			Name: "copy",
+			Input: map[string]any{
+				"type":     "object",
+				"required": []any{"src", "dst"},
+				"properties": map[string]any{
+					"src":   map[string]any{"type": "string"},
+					"dst":   map[string]any{"type": "string"},
+					"count": map[string]any{"type": "integer"},
+					"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				},
+			},
+			Command: "echo",
+			Args:    []string{"-n", "{{.src}}", "{{.dst}}", "{{.count}}"},
+		}},
+	})
+	require.NoError(t, err)
+
+	out, err := tools[0].Call(context.Background(), json.RawMessage(`{"src": "a", "dst": "b", "count": -1}`))
+	require.NoError(t, err)
+	assert.Equal(t, "a b -1", string(out.Data))
+
+	// options injection
+	_, err = tools[0].Call(context.Background(), json.RawMessage(`{"src": "--help", "dst": "b"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `argument "src" can not start with '-'`)
+
+	_, err = tools[0].Call(context.Background(), json.RawMessage(`{"src": "a", "dst": "b", "tags": ["ok", "-rf"]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `argument "tags" can not start with '-'`)
+
+	// empty required argument would shift positions
+	_, err = tools[0].Call(context.Background(), json.RawMessage(`{"src": "", "dst": "b"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `argument "src" is required and can not be empty`)
+}
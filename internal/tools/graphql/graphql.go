@@ -0,0 +1,365 @@
+// Package graphql provides tools from GraphQL endpoint: each allowed root query or mutation field becomes a tool.
+//
+// Schema is fetched by introspection on every tools refresh. Arguments of the field become tool input, selection set
+// of the result is generated automatically up to configured depth (fields with required arguments are skipped).
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/invopop/jsonschema"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tracing"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxResponse = 1024 * 1024
+	DefaultDepth       = 3
+)
+
+// AllFields in allow-list enables all fields of root type.
+const AllFields = "*"
+
+type Config struct {
+	Namespace   string               `json:"namespace" yaml:"namespace,omitempty"`           // Prefix all tools with value and underscore
+	URL         string               `json:"url" yaml:"url"`                                 // GraphQL endpoint
+	Headers     []utils.Pair[string] `json:"headers,omitempty" yaml:"headers,omitempty"`     // Extra outgoing headers
+	Timeout     time.Duration        `json:"timeout" yaml:"timeout,omitempty"`               // Request timeout. Default is 30s
+	MaxResponse int                  `json:"max_response" yaml:"maxResponse,omitempty"`      // Maximum response body size in bytes. Default is 1MiB
+	Depth       int                  `json:"depth" yaml:"depth,omitempty"`                   // Max depth of generated selection set. Default is 3
+	Queries     []string             `json:"queries,omitempty" yaml:"queries,omitempty"`     // Allowed query fields. Default is all
+	Mutations   []string             `json:"mutations,omitempty" yaml:"mutations,omitempty"` // Allowed mutation fields. Default is none, use "*" for all
+}
+
+// New tools from GraphQL endpoint.
+func New(ctx context.Context, config Config) ([]types.Tool, error) {
+	if config.URL == "" {
+		return nil, errors.New("url should be set")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxResponse <= 0 {
+		config.MaxResponse = DefaultMaxResponse
+	}
+	if config.Depth <= 0 {
+		config.Depth = DefaultDepth
+	}
+
+	var headers = make(http.Header)
+	for _, h := range config.Headers {
+		value, err := h.Get()
+		if err != nil {
+			return nil, fmt.Errorf("get header %q value: %w", h.Name, err)
+		}
+		headers.Add(h.Name, value)
+	}
+
+	c := &client{
+		url:         config.URL,
+		headers:     headers,
+		maxResponse: config.MaxResponse,
+		http: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: tracing.Transport(http.DefaultTransport),
+		},
+	}
+
+	var data introspection
+	if err := c.do(ctx, introspectionQuery, nil, &data); err != nil {
+		return nil, fmt.Errorf("introspect schema: %w", err)
+	}
+	s := newSchema(&data)
+
+	var ans []types.Tool
+	var names = utils.NewSet[string]()
+	for _, root := range []struct {
+		operation string
+		typeName  string
+		allowed   []string
+		all       bool
+	}{
+		{operation: "query", typeName: s.query, allowed: config.Queries, all: len(config.Queries) == 0},
+		{operation: "mutation", typeName: s.mutation, allowed: config.Mutations},
+	} {
+		if root.typeName == "" {
+			continue
+		}
+		t, ok := s.types[root.typeName]
+		if !ok {
+			return nil, fmt.Errorf("%s type %q not defined", root.operation, root.typeName)
+		}
+		all := root.all || slices.Contains(root.allowed, AllFields)
+		allowed := utils.NewSet(root.allowed...)
+		for _, f := range t.Fields {
+			if !all && !allowed.Contains(f.Name) {
+				slog.Debug("graphql field not allowed by config", "operation", root.operation, "field", f.Name)
+				continue
+			}
+			if names.Contains(f.Name) {
+				slog.Warn("graphql field skipped due to name conflict", "operation", root.operation, "field", f.Name)
+				continue
+			}
+			names.Add(f.Name)
+			tool, err := s.newTool(c, root.operation, f, config.Depth)
+			if err != nil {
+				return nil, fmt.Errorf("create tool for %s %q: %w", root.operation, f.Name, err)
+			}
+			tool.name = utils.Concat("_", config.Namespace, f.Name)
+			ans = append(ans, tool)
+		}
+	}
+	return ans, nil
+}
+
+func (s *schema) newTool(c *client, operation string, f field, depth int) (*graphqlTool, error) {
+	var input = &jsonschema.Schema{
+		Type:       "object",
+		Properties: jsonschema.NewProperties(),
+	}
+	var (
+		variables []string
+		arguments []string
+	)
+	for _, arg := range f.Args {
+		prop := s.inputSchema(arg.Type, nil)
+		prop.Description = arg.Description
+		input.Properties.Set(arg.Name, prop)
+		if arg.required() {
+			input.Required = append(input.Required, arg.Name)
+		}
+		variables = append(variables, "$"+arg.Name+": "+arg.Type.String())
+		arguments = append(arguments, arg.Name+": $"+arg.Name)
+	}
+
+	var query strings.Builder
+	query.WriteString(operation)
+	if len(variables) > 0 {
+		query.WriteString("(" + strings.Join(variables, ", ") + ")")
+	}
+	query.WriteString(" { " + f.Name)
+	if len(arguments) > 0 {
+		query.WriteString("(" + strings.Join(arguments, ", ") + ")")
+	}
+	if named, ok := s.types[f.Type.named().Name]; ok && named.Kind != kindScalar && named.Kind != kindEnum {
+		sel := s.selection(f.Type, depth)
+		if sel == "" {
+			return nil, errors.New("no fields can be selected in result")
+		}
+		query.WriteString(" " + sel)
+	}
+	query.WriteString(" }")
+
+	return &graphqlTool{
+		description: f.Description,
+		input:       input,
+		query:       query.String(),
+		client:      c,
+	}, nil
+}
+
+// inputSchema converts input type to JSON schema. Recursive input objects are not expanded.
+func (s *schema) inputSchema(ref typeRef, visited []string) *jsonschema.Schema {
+	switch ref.Kind {
+	case kindNonNull:
+		if ref.OfType == nil {
+			return &jsonschema.Schema{}
+		}
+		return s.inputSchema(*ref.OfType, visited)
+	case kindList:
+		var items = &jsonschema.Schema{}
+		if ref.OfType != nil {
+			items = s.inputSchema(*ref.OfType, visited)
+		}
+		return &jsonschema.Schema{Type: "array", Items: items}
+	}
+	t, ok := s.types[ref.Name]
+	if !ok {
+		return &jsonschema.Schema{Type: "string"}
+	}
+	switch t.Kind {
+	case kindEnum:
+		var values = make([]any, 0, len(t.EnumValues))
+		for _, v := range t.EnumValues {
+			values = append(values, v.Name)
+		}
+		return &jsonschema.Schema{Type: "string", Enum: values, Description: t.Description}
+	case kindInputObject:
+		var obj = &jsonschema.Schema{Type: "object", Description: t.Description}
+		if slices.Contains(visited, t.Name) {
+			return obj
+		}
+		visited = append(visited, t.Name)
+		obj.Properties = jsonschema.NewProperties()
+		for _, f := range t.InputFields {
+			prop := s.inputSchema(f.Type, visited)
+			if f.Description != "" {
+				prop.Description = f.Description
+			}
+			obj.Properties.Set(f.Name, prop)
+			if f.required() {
+				obj.Required = append(obj.Required, f.Name)
+			}
+		}
+		return obj
+	default:
+		return scalarSchema(t)
+	}
+}
+
+func scalarSchema(t *fullType) *jsonschema.Schema {
+	switch t.Name {
+	case "Int":
+		return &jsonschema.Schema{Type: "integer"}
+	case "Float":
+		return &jsonschema.Schema{Type: "number"}
+	case "Boolean":
+		return &jsonschema.Schema{Type: "boolean"}
+	case "String", "ID":
+		return &jsonschema.Schema{Type: "string"}
+	default:
+		// custom scalars (dates, JSON, ...) are usually serialized as strings
+		return &jsonschema.Schema{Type: "string", Description: utils.Concat(". ", t.Name, t.Description)}
+	}
+}
+
+type graphqlTool struct {
+	name        string
+	description string
+	input       *jsonschema.Schema
+	query       string
+	client      *client
+}
+
+func (tool *graphqlTool) Name() string {
+	return tool.name
+}
+
+func (tool *graphqlTool) Description() string {
+	return tool.description
+}
+
+func (tool *graphqlTool) Input() *jsonschema.Schema {
+	return tool.input
+}
+
+func (tool *graphqlTool) Call(ctx context.Context, args json.RawMessage) (types.Content, error) {
+	var variables map[string]any
+	if err := json.Unmarshal(args, &variables); err != nil {
+		return types.Content{}, fmt.Errorf("parse arguments: %w", err)
+	}
+	var data json.RawMessage
+	if err := tool.client.do(ctx, tool.query, variables, &data); err != nil {
+		return types.Content{}, err
+	}
+	return types.Content{Data: data, Mime: types.MIMEJson}, nil
+}
+
+type client struct {
+	url         string
+	headers     http.Header
+	maxResponse int
+	http        *http.Client
+}
+
+type gqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []gqlError      `json:"errors"`
+}
+
+type gqlError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e gqlError) String() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	var parts = make([]string, 0, len(e.Path))
+	for _, p := range e.Path {
+		parts = append(parts, fmt.Sprint(p))
+	}
+	return strings.Join(parts, ".") + ": " + e.Message
+}
+
+// do executes operation and decodes data to out. GraphQL errors are returned as error, unless partial data returned:
+// in that case data is kept and errors are appended to it, so model can see both.
+func (c *client) do(ctx context.Context, query string, variables map[string]any, out any) error {
+	payload, err := json.Marshal(gqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(utils.NewLimitedReader(res.Body, c.maxResponse))
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	var response gqlResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		if res.StatusCode/100 != 2 {
+			return fmt.Errorf("invalid response status code: %d", res.StatusCode)
+		}
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	hasData := len(response.Data) > 0 && string(response.Data) != "null"
+	if len(response.Errors) > 0 && !hasData {
+		var messages = make([]string, 0, len(response.Errors))
+		for _, e := range response.Errors {
+			messages = append(messages, e.String())
+		}
+		return fmt.Errorf("graphql: %s", strings.Join(messages, "; "))
+	}
+	if !hasData {
+		return fmt.Errorf("no data in response (status %d)", res.StatusCode)
+	}
+
+	if raw, ok := out.(*json.RawMessage); ok && len(response.Errors) > 0 {
+		// partial result
+		partial, err := json.Marshal(response)
+		if err != nil {
+			return fmt.Errorf("encode partial response: %w", err)
+		}
+		*raw = partial
+		return nil
+	}
+	if err := json.Unmarshal(response.Data, out); err != nil {
+		return fmt.Errorf("decode data: %w", err)
+	}
+	return nil
+}
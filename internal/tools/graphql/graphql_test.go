@@ -0,0 +1,191 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/graphql"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const testSchema = `{"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": {"name": "Mutation"},
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "description": "Get user by ID", "args": [
+        {"name": "id", "description": "User ID", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+      ], "type": {"kind": "OBJECT", "name": "User"}},
+      {"name": "search", "args": [
+        {"name": "filter", "type": {"kind": "INPUT_OBJECT", "name": "Filter"}},
+        {"name": "limit", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}, "defaultValue": "10"}
+      ], "type": {"kind": "LIST", "ofType": {"kind": "UNION", "name": "Result"}}},
+      {"name": "version", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+    ]},
+    {"kind": "OBJECT", "name": "Mutation", "fields": [
+      {"name": "deleteUser", "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+      ], "type": {"kind": "SCALAR", "name": "Boolean"}},
+      {"name": "createUser", "args": [
+        {"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+      ], "type": {"kind": "OBJECT", "name": "User"}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+      {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "role", "args": [], "type": {"kind": "ENUM", "name": "Role"}},
+      {"name": "friends", "args": [
+        {"name": "first", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}}
+      ], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}},
+      {"name": "manager", "args": [], "type": {"kind": "OBJECT", "name": "User"}}
+    ]},
+    {"kind": "OBJECT", "name": "Post", "fields": [
+      {"name": "title", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "name", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+    ]},
+    {"kind": "UNION", "name": "Result", "possibleTypes": [{"name": "User"}, {"name": "Post"}]},
+    {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "USER"}]},
+    {"kind": "INPUT_OBJECT", "name": "Filter", "inputFields": [
+      {"name": "role", "type": {"kind": "ENUM", "name": "Role"}},
+      {"name": "text", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}},
+      {"name": "or", "type": {"kind": "LIST", "ofType": {"kind": "INPUT_OBJECT", "name": "Filter"}}}
+    ]},
+    {"kind": "SCALAR", "name": "ID"},
+    {"kind": "SCALAR", "name": "Int"},
+    {"kind": "SCALAR", "name": "String"},
+    {"kind": "SCALAR", "name": "Boolean"}
+  ]
+}}`
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "secret", request.Header.Get("Authorization"))
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&req))
+		writer.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(req.Query, "__schema"):
+			_, _ = writer.Write([]byte(`{"data": ` + testSchema + `}`))
+		case strings.HasPrefix(req.Query, "query($id: ID!) { user(id: $id)"):
+			// friends requires argument, manager of manager is beyond depth
+			assert.Equal(t, "query($id: ID!) { user(id: $id) { id name role manager { id name role } } }", req.Query)
+			if req.Variables["id"] != "1" {
+				writer.WriteHeader(http.StatusOK)
+				_, _ = writer.Write([]byte(`{"data": null, "errors": [{"message": "user not found", "path": ["user"]}]}`))
+				return
+			}
+			_, _ = writer.Write([]byte(`{"data": {"user": {"id": "1", "name": "Alice", "role": "ADMIN", "manager": null}}}`))
+		case strings.HasPrefix(req.Query, "query { version }"):
+			_, _ = writer.Write([]byte(`{"data": {"version": "1.0"}, "errors": [{"message": "deprecated"}]}`))
+		case strings.HasPrefix(req.Query, "query($filter: Filter, $limit: Int!) { search(filter: $filter, limit: $limit)"):
+			// name is String in User and String! in Post: aliased, otherwise fields can not be merged
+			assert.Equal(t, "query($filter: Filter, $limit: Int!) { search(filter: $filter, limit: $limit) "+
+				"{ __typename ... on User { id User_name: name role manager { id name role } } ... on Post { title Post_name: name } } }", req.Query)
+			_, _ = writer.Write([]byte(`{"data": {"search": [{"__typename": "Post", "title": "Hello", "Post_name": "hello"}]}}`))
+		default:
+			writer.WriteHeader(http.StatusBadRequest)
+			_, _ = writer.Write([]byte(`{"errors": [{"message": "unexpected query"}]}`))
+		}
+	}))
+}
+
+func TestNew(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	secret := "secret"
+	ctx := context.Background()
+	tools, err := graphql.New(ctx, graphql.Config{
+		URL:       srv.URL,
+		Namespace: "api",
+		Depth:     2,
+		Mutations: []string{"createUser"},
+		Headers:   []utils.Pair[string]{{Name: "Authorization", Value: utils.Value[string]{Value: &secret}}},
+	})
+	require.NoError(t, err)
+
+	var snapshot = make(types.Snapshot)
+	for _, tool := range tools {
+		snapshot[tool.Name()] = tool
+	}
+	require.Len(t, snapshot, 4)
+	require.NotContains(t, snapshot, "api_deleteUser") // not in allow-list
+
+	t.Run("input", func(t *testing.T) {
+		user := snapshot["api_user"]
+		require.NotNil(t, user)
+		assert.Equal(t, "Get user by ID", user.Description())
+		assert.Equal(t, []string{"id"}, user.Input().Required)
+		id, ok := user.Input().Properties.Get("id")
+		require.True(t, ok)
+		assert.Equal(t, "string", id.Type)
+		assert.Equal(t, "User ID", id.Description)
+
+		search := snapshot["api_search"].Input()
+		assert.Empty(t, search.Required) // limit has default value
+		filter, ok := search.Properties.Get("filter")
+		require.True(t, ok)
+		assert.Equal(t, "object", filter.Type)
+		assert.Equal(t, []string{"text"}, filter.Required)
+		role, _ := filter.Properties.Get("role")
+		assert.Equal(t, []any{"ADMIN", "USER"}, role.Enum)
+		or, _ := filter.Properties.Get("or")
+		assert.Equal(t, "array", or.Type)
+		assert.Equal(t, "object", or.Items.Type)
+		assert.Nil(t, or.Items.Properties) // recursion stopped
+	})
+
+	t.Run("call", func(t *testing.T) {
+		out, err := snapshot["api_user"].Call(ctx, json.RawMessage(`{"id": "1"}`))
+		require.NoError(t, err)
+		assert.Equal(t, types.MIMEJson, out.Mime)
+		assert.JSONEq(t, `{"user": {"id": "1", "name": "Alice", "role": "ADMIN", "manager": null}}`, string(out.Data))
+
+		out, err = snapshot["api_search"].Call(ctx, json.RawMessage(`{"filter": {"text": "hello"}}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"search": [{"__typename": "Post", "title": "Hello", "Post_name": "hello"}]}`, string(out.Data))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := snapshot["api_user"].Call(ctx, json.RawMessage(`{"id": "2"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "user: user not found")
+
+		_, err = snapshot["api_createUser"].Call(ctx, json.RawMessage(`{"name": "Bob"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected query")
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		out, err := snapshot["api_version"].Call(ctx, json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data": {"version": "1.0"}, "errors": [{"message": "deprecated"}]}`, string(out.Data))
+	})
+}
+
+func TestNew_queries(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	secret := "secret"
+	tools, err := graphql.New(context.Background(), graphql.Config{
+		URL:     srv.URL,
+		Queries: []string{"version"},
+		Headers: []utils.Pair[string]{{Name: "Authorization", Value: utils.Value[string]{Value: &secret}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "version", tools[0].Name())
+}
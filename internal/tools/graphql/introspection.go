@@ -0,0 +1,264 @@
+package graphql
+
+import (
+	"strings"
+)
+
+// introspectionQuery fetches types with enough nesting of type references (NON_NULL/LIST wrappers).
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types {
+      kind
+      name
+      description
+      fields {
+        name
+        description
+        args { ...InputValue }
+        type { ...TypeRef }
+      }
+      inputFields { ...InputValue }
+      enumValues { name }
+      possibleTypes { name }
+    }
+  }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// Type kinds.
+const (
+	kindScalar      = "SCALAR"
+	kindObject      = "OBJECT"
+	kindInterface   = "INTERFACE"
+	kindUnion       = "UNION"
+	kindEnum        = "ENUM"
+	kindInputObject = "INPUT_OBJECT"
+	kindList        = "LIST"
+	kindNonNull     = "NON_NULL"
+)
+
+type introspection struct {
+	Schema struct {
+		QueryType    *namedRef  `json:"queryType"`
+		MutationType *namedRef  `json:"mutationType"`
+		Types        []fullType `json:"types"`
+	} `json:"__schema"`
+}
+
+type namedRef struct {
+	Name string `json:"name"`
+}
+
+type fullType struct {
+	Kind          string       `json:"kind"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Fields        []field      `json:"fields"`
+	InputFields   []inputValue `json:"inputFields"`
+	EnumValues    []namedRef   `json:"enumValues"`
+	PossibleTypes []namedRef   `json:"possibleTypes"`
+}
+
+type field struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Args        []inputValue `json:"args"`
+	Type        typeRef      `json:"type"`
+}
+
+// hasRequiredArgs returns true if field can not be selected without arguments.
+func (f *field) hasRequiredArgs() bool {
+	for _, arg := range f.Args {
+		if arg.required() {
+			return true
+		}
+	}
+	return false
+}
+
+type inputValue struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Type         typeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+func (iv *inputValue) required() bool {
+	return iv.Type.Kind == kindNonNull && iv.DefaultValue == nil
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+// named type without wrappers.
+func (tr *typeRef) named() *typeRef {
+	if tr.OfType != nil && (tr.Kind == kindNonNull || tr.Kind == kindList) {
+		return tr.OfType.named()
+	}
+	return tr
+}
+
+// String in GraphQL notation (ex: [String!]!).
+func (tr *typeRef) String() string {
+	switch tr.Kind {
+	case kindNonNull:
+		if tr.OfType == nil {
+			return ""
+		}
+		return tr.OfType.String() + "!"
+	case kindList:
+		if tr.OfType == nil {
+			return "[]"
+		}
+		return "[" + tr.OfType.String() + "]"
+	default:
+		return tr.Name
+	}
+}
+
+type schema struct {
+	types    map[string]*fullType
+	query    string
+	mutation string
+}
+
+func newSchema(data *introspection) *schema {
+	s := &schema{types: make(map[string]*fullType, len(data.Schema.Types))}
+	for i := range data.Schema.Types {
+		t := &data.Schema.Types[i]
+		s.types[t.Name] = t
+	}
+	if data.Schema.QueryType != nil {
+		s.query = data.Schema.QueryType.Name
+	}
+	if data.Schema.MutationType != nil {
+		s.mutation = data.Schema.MutationType.Name
+	}
+	return s
+}
+
+// selection set for type up to depth. Returns empty string for scalars and if nothing can be selected.
+func (s *schema) selection(ref typeRef, depth int) string {
+	named := ref.named()
+	t, ok := s.types[named.Name]
+	if !ok {
+		return ""
+	}
+	switch t.Kind {
+	case kindObject, kindInterface:
+		if depth <= 0 {
+			return ""
+		}
+		var fields []string
+		if t.Kind == kindInterface {
+			fields = append(fields, "__typename")
+		}
+		fields = append(fields, s.fields(t, depth, nil)...)
+		if len(fields) == 0 || (len(fields) == 1 && fields[0] == "__typename") {
+			return ""
+		}
+		return "{ " + strings.Join(fields, " ") + " }"
+	case kindUnion:
+		if depth <= 0 {
+			return ""
+		}
+		conflicts := s.conflicts(t)
+		var fields = []string{"__typename"}
+		for _, possible := range t.PossibleTypes {
+			member, ok := s.types[possible.Name]
+			if !ok {
+				continue
+			}
+			if sub := s.fields(member, depth, conflicts); len(sub) > 0 {
+				fields = append(fields, "... on "+possible.Name+" { "+strings.Join(sub, " ")+" }")
+			}
+		}
+		return "{ " + strings.Join(fields, " ") + " }"
+	default:
+		return ""
+	}
+}
+
+// fields of object which can be selected up to depth. Fields from conflicts are aliased as <Type>_<field>.
+func (s *schema) fields(t *fullType, depth int, conflicts map[string]bool) []string {
+	var fields []string
+	for _, f := range t.Fields {
+		if f.hasRequiredArgs() {
+			continue
+		}
+		name := f.Name
+		if conflicts[f.Name] {
+			name = t.Name + "_" + f.Name + ": " + f.Name
+		}
+		inner := f.Type.named()
+		if ft, ok := s.types[inner.Name]; !ok || ft.Kind == kindScalar || ft.Kind == kindEnum {
+			fields = append(fields, name)
+			continue
+		}
+		if sub := s.selection(f.Type, depth-1); sub != "" {
+			fields = append(fields, name+" "+sub)
+		}
+	}
+	return fields
+}
+
+// conflicts in union: fields with the same name but different types in members. Server rejects such
+// selections (fields in set can not be merged) unless they are aliased.
+func (s *schema) conflicts(union *fullType) map[string]bool {
+	var seen = make(map[string]string)
+	var conflicts = make(map[string]bool)
+	for _, possible := range union.PossibleTypes {
+		member, ok := s.types[possible.Name]
+		if !ok {
+			continue
+		}
+		for _, f := range member.Fields {
+			kind := f.Type.String()
+			if prev, ok := seen[f.Name]; ok && prev != kind {
+				conflicts[f.Name] = true
+			}
+			seen[f.Name] = kind
+		}
+	}
+	return conflicts
+}
@@ -9,26 +9,34 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/pikocloud/pikobrain/internal/ent"
 	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/command"
+	"github.com/pikocloud/pikobrain/internal/tools/graphql"
+	"github.com/pikocloud/pikobrain/internal/tools/mcp"
 	"github.com/pikocloud/pikobrain/internal/tools/openapi"
+	"github.com/pikocloud/pikobrain/internal/tools/rpc"
+	"github.com/pikocloud/pikobrain/internal/tools/script"
+	"github.com/pikocloud/pikobrain/internal/tools/threads"
 )
 
-func LoadFile(file string) ([]types.ToolProviderFunc, error) {
+// LoadFile with tools definitions. DB is used by internal tools.
+func LoadFile(file string, db *ent.Client) ([]types.ToolProviderFunc, error) {
 	f, err := os.Open(file)
 	if err != nil {
 		return nil, fmt.Errorf("open file: %w", err)
 	}
 	defer f.Close()
 
-	return Decode(f)
+	return Decode(f, db)
 }
 
 // Decode stream assuming that it's multi-document YAML tools definition.
-func Decode(src io.Reader) ([]types.ToolProviderFunc, error) {
+func Decode(src io.Reader, db *ent.Client) ([]types.ToolProviderFunc, error) {
 	var ans []types.ToolProviderFunc
 	doc := yaml.NewDecoder(src)
 	for {
-		fp, err := decodePart(doc)
+		fp, err := decodePart(doc, db)
 		if errors.Is(err, io.EOF) {
 			break
 		}
@@ -40,7 +48,7 @@ func Decode(src io.Reader) ([]types.ToolProviderFunc, error) {
 	return ans, nil
 }
 
-func decodePart(dec *yaml.Decoder) (types.ToolProviderFunc, error) {
+func decodePart(dec *yaml.Decoder, db *ent.Client) (types.ToolProviderFunc, error) {
 	var root yaml.Node
 	if err := dec.Decode(&root); err != nil {
 		return nil, fmt.Errorf("decode document: %w", err)
@@ -60,6 +68,70 @@ func decodePart(dec *yaml.Decoder) (types.ToolProviderFunc, error) {
 		return func(ctx context.Context) ([]types.Tool, error) {
 			return openapi.New(ctx, config)
 		}, nil
+	case Internal:
+		var config threads.Config
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("decode config: %w", err)
+		}
+		tools, err := threads.New(db, config)
+		if err != nil {
+			return nil, fmt.Errorf("create internal tools: %w", err)
+		}
+		return func(ctx context.Context) ([]types.Tool, error) {
+			return tools, nil
+		}, nil
+	case Script:
+		var config script.Config
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("decode config: %w", err)
+		}
+		tools, err := script.New(config)
+		if err != nil {
+			return nil, fmt.Errorf("create script tools: %w", err)
+		}
+		return func(ctx context.Context) ([]types.Tool, error) {
+			return tools, nil
+		}, nil
+	case Exec:
+		var config command.Config
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("decode config: %w", err)
+		}
+		tools, err := command.New(config)
+		if err != nil {
+			return nil, fmt.Errorf("create exec tools: %w", err)
+		}
+		return func(ctx context.Context) ([]types.Tool, error) {
+			return tools, nil
+		}, nil
+	case MCP:
+		var config mcp.Config
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("decode config: %w", err)
+		}
+		provider, err := mcp.New(config)
+		if err != nil {
+			return nil, fmt.Errorf("create mcp tools: %w", err)
+		}
+		return provider, nil
+	case GraphQL:
+		var config graphql.Config
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("decode config: %w", err)
+		}
+		return func(ctx context.Context) ([]types.Tool, error) {
+			return graphql.New(ctx, config)
+		}, nil
+	case GRPC:
+		var config rpc.Config
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("decode config: %w", err)
+		}
+		provider, err := rpc.New(config)
+		if err != nil {
+			return nil, fmt.Errorf("create grpc tools: %w", err)
+		}
+		return provider, nil
 	}
 
 	return nil, fmt.Errorf("unknown tool type: %s", meta.Type)
@@ -5,6 +5,12 @@ package loader
 // ToolType describes which tool should be used.
 // ENUM(
 // OpenAPI = openapi,
+// Internal = internal,
+// Script = script,
+// Exec = exec,
+// MCP = mcp,
+// GraphQL = graphql,
+// GRPC = grpc,
 // )
 type ToolType string
 
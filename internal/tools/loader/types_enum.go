@@ -14,6 +14,18 @@ import (
 const (
 	// OpenAPI is a ToolType of type OpenAPI.
 	OpenAPI ToolType = "openapi"
+	// Internal is a ToolType of type Internal.
+	Internal ToolType = "internal"
+	// Script is a ToolType of type Script.
+	Script ToolType = "script"
+	// Exec is a ToolType of type Exec.
+	Exec ToolType = "exec"
+	// MCP is a ToolType of type MCP.
+	MCP ToolType = "mcp"
+	// GraphQL is a ToolType of type GraphQL.
+	GraphQL ToolType = "graphql"
+	// GRPC is a ToolType of type GRPC.
+	GRPC ToolType = "grpc"
 )
 
 var ErrInvalidToolType = errors.New("not a valid ToolType")
@@ -31,7 +43,13 @@ func (x ToolType) IsValid() bool {
 }
 
 var _ToolTypeValue = map[string]ToolType{
-	"openapi": OpenAPI,
+	"openapi":  OpenAPI,
+	"internal": Internal,
+	"script":   Script,
+	"exec":     Exec,
+	"mcp":      MCP,
+	"graphql":  GraphQL,
+	"grpc":     GRPC,
 }
 
 // ParseToolType attempts to convert a string to a ToolType.
@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed returned when connection to server is closed or broken.
+var ErrClosed = errors.New("connection closed")
+
+// transport delivers JSON-RPC messages. Incoming messages are passed to receive; broken persistent connection
+// reported by fail.
+type transport interface {
+	start(ctx context.Context, receive func([]byte), fail func(error)) error
+	send(ctx context.Context, payload []byte) error
+	close() error
+}
+
+// Client of MCP server. Only tools are supported.
+type Client struct {
+	transport transport
+	nextID    atomic.Int64
+	lock      sync.Mutex
+	pending   map[int64]chan *Message
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+	server    InitializeResult
+}
+
+// connect starts transport and does initialization handshake.
+func connect(ctx context.Context, tr transport, info Implementation) (*Client, error) {
+	c := &Client{
+		transport: tr,
+		pending:   make(map[int64]chan *Message),
+		done:      make(chan struct{}),
+	}
+	if err := tr.start(ctx, c.receive, c.fail); err != nil {
+		return nil, fmt.Errorf("start transport: %w", err)
+	}
+
+	err := c.call(ctx, MethodInitialize, InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]any{},
+		ClientInfo:      info,
+	}, &c.server)
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	if err := c.notify(ctx, NotifyInitialized, nil); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("send initialized: %w", err)
+	}
+	slog.Debug("mcp server connected", "server", c.server.ServerInfo.Name, "version", c.server.ServerInfo.Version, "protocol", c.server.ProtocolVersion)
+	return c, nil
+}
+
+// Server info received during initialization.
+func (c *Client) Server() InitializeResult {
+	return c.server
+}
+
+// ListTools returns all tools (all pages).
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var (
+		ans    []Tool
+		cursor string
+	)
+	for {
+		var page ListToolsResult
+		if err := c.call(ctx, MethodToolsList, ListToolsParams{Cursor: cursor}, &page); err != nil {
+			return nil, err
+		}
+		ans = append(ans, page.Tools...)
+		if page.NextCursor == "" {
+			return ans, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool by name with JSON arguments.
+func (c *Client) CallTool(ctx context.Context, name string, args json.RawMessage) (*CallToolResult, error) {
+	var ans CallToolResult
+	if err := c.call(ctx, MethodToolsCall, CallToolParams{Name: name, Arguments: args}, &ans); err != nil {
+		return nil, err
+	}
+	return &ans, nil
+}
+
+// Closed returns true if connection is no longer usable.
+func (c *Client) Closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close connection. Safe to call multiple times.
+func (c *Client) Close() error {
+	c.fail(ErrClosed)
+	return c.transport.close()
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, out any) error {
+	id := c.nextID.Add(1)
+	ch := make(chan *Message, 1)
+	c.lock.Lock()
+	c.pending[id] = ch
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, id)
+		c.lock.Unlock()
+	}()
+
+	payload, err := encode(json.RawMessage(strconv.FormatInt(id, 10)), method, params)
+	if err != nil {
+		return err
+	}
+	if err := c.transport.send(ctx, payload); err != nil {
+		return fmt.Errorf("send %s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return c.err
+	case res := <-ch:
+		if res.Error != nil {
+			return res.Error
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(res.Result, out); err != nil {
+			return fmt.Errorf("decode %s result: %w", method, err)
+		}
+		return nil
+	}
+}
+
+func (c *Client) notify(ctx context.Context, method string, params any) error {
+	payload, err := encode(nil, method, params)
+	if err != nil {
+		return err
+	}
+	return c.transport.send(ctx, payload)
+}
+
+func (c *Client) receive(data []byte) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		slog.Warn("invalid message from mcp server", "error", err)
+		return
+	}
+	if msg.IsNotification() {
+		slog.Debug("mcp notification", "method", msg.Method)
+		return
+	}
+	if msg.IsRequest() {
+		// server-initiated requests: only ping is supported
+		go c.reply(msg)
+		return
+	}
+	id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+	if err != nil {
+		slog.Warn("unexpected response id from mcp server", "id", string(msg.ID))
+		return
+	}
+	c.lock.Lock()
+	ch, ok := c.pending[id]
+	c.lock.Unlock()
+	if ok {
+		ch <- &msg
+	}
+}
+
+func (c *Client) reply(req Message) {
+	res := Message{JSONRPC: "2.0", ID: req.ID}
+	if req.Method == MethodPing {
+		res.Result = json.RawMessage("{}")
+	} else {
+		res.Error = &Error{Code: CodeMethodNotFound, Message: "method not supported: " + req.Method}
+	}
+	payload, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+	if err := c.transport.send(context.Background(), payload); err != nil {
+		slog.Debug("failed reply to mcp server", "method", req.Method, "error", err)
+	}
+}
+
+func (c *Client) fail(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
+
+func encode(id json.RawMessage, method string, params any) ([]byte, error) {
+	msg := Message{JSONRPC: "2.0", ID: id, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("encode params: %w", err)
+		}
+		msg.Params = raw
+	}
+	return json.Marshal(msg)
+}
@@ -0,0 +1,279 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const headerSession = "Mcp-Session-Id"
+
+// streamableTransport implements streamable HTTP: every message is POST, response is JSON or SSE stream.
+// Connection is reported as broken on network errors and on expired session (404), so the client reconnects.
+type streamableTransport struct {
+	url         string
+	headers     http.Header
+	client      *http.Client
+	maxResponse int
+
+	receive func([]byte)
+	fail    func(error)
+	lock    sync.Mutex
+	session string
+}
+
+func (st *streamableTransport) start(_ context.Context, receive func([]byte), fail func(error)) error {
+	st.receive = receive
+	st.fail = fail
+	return nil
+}
+
+func (st *streamableTransport) send(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, st.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	withSession := st.prepare(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	res, err := st.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("do request: %w", err)
+		if ctx.Err() == nil {
+			st.fail(err)
+		}
+		return err
+	}
+	if session := res.Header.Get(headerSession); session != "" {
+		st.lock.Lock()
+		st.session = session
+		st.lock.Unlock()
+	}
+	if res.StatusCode == http.StatusAccepted {
+		_ = res.Body.Close()
+		return nil
+	}
+	if res.StatusCode/100 != 2 {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err := fmt.Errorf("status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+		if withSession && res.StatusCode == http.StatusNotFound {
+			err = fmt.Errorf("session expired: %w: %w", ErrClosed, err)
+			st.fail(err)
+		}
+		return err
+	}
+
+	contentType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if contentType == "text/event-stream" {
+		// server may keep stream open for related requests and notifications
+		go func() {
+			defer res.Body.Close()
+			if err := readEvents(res.Body, st.maxResponse, func(event, data string) {
+				if event == "" || event == "message" {
+					st.receive([]byte(data))
+				}
+			}); err != nil {
+				slog.Debug("mcp event stream closed", "error", err)
+			}
+		}()
+		return nil
+	}
+
+	defer res.Body.Close()
+	body, err := io.ReadAll(utils.NewLimitedReader(res.Body, st.maxResponse))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		st.receive(body)
+	}
+	return nil
+}
+
+func (st *streamableTransport) close() error {
+	st.lock.Lock()
+	session := st.session
+	st.lock.Unlock()
+	if session == "" {
+		return nil
+	}
+	// terminate session, best effort
+	req, err := http.NewRequest(http.MethodDelete, st.url, nil)
+	if err != nil {
+		return err
+	}
+	st.prepare(req)
+	res, err := st.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// prepare request headers. Returns true if session is set.
+func (st *streamableTransport) prepare(req *http.Request) bool {
+	for k, v := range st.headers {
+		req.Header[k] = v
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	if st.session != "" {
+		req.Header.Set(headerSession, st.session)
+	}
+	return st.session != ""
+}
+
+// sseTransport implements legacy HTTP+SSE transport: long-living GET stream for incoming messages and POST to
+// endpoint announced by server for outgoing.
+type sseTransport struct {
+	url         string
+	headers     http.Header
+	client      *http.Client
+	maxResponse int
+
+	endpoint string
+	cancel   context.CancelFunc
+}
+
+func (st *sseTransport) start(ctx context.Context, receive func([]byte), fail func(error)) error {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, st.url, nil)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("create request: %w", err)
+	}
+	for k, v := range st.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	res, err := st.client.Do(req)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("open stream: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		cancel()
+		return fmt.Errorf("open stream: status %d", res.StatusCode)
+	}
+	st.cancel = cancel
+
+	endpoint := make(chan string, 1)
+	go func() {
+		defer res.Body.Close()
+		err := readEvents(res.Body, st.maxResponse, func(event, data string) {
+			switch event {
+			case "endpoint":
+				select {
+				case endpoint <- data:
+				default:
+				}
+			case "", "message":
+				receive([]byte(data))
+			}
+		})
+		if err == nil {
+			err = ErrClosed
+		}
+		fail(fmt.Errorf("event stream: %w", err))
+	}()
+
+	select {
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	case data := <-endpoint:
+		base, err := url.Parse(st.url)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("parse url: %w", err)
+		}
+		ref, err := url.Parse(data)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("parse endpoint: %w", err)
+		}
+		st.endpoint = base.ResolveReference(ref).String()
+		return nil
+	}
+}
+
+func (st *sseTransport) send(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, st.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	for k, v := range st.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := st.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (st *sseTransport) close() error {
+	if st.cancel != nil {
+		st.cancel()
+	}
+	return nil
+}
+
+// readEvents parses server-sent events stream. Data of a single event is limited by maxSize.
+func readEvents(stream io.Reader, maxSize int, handler func(event, data string)) error {
+	// the longest line is data field with prefix and delimiter
+	maxLine := maxSize + len("data: \r\n")
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLine)), maxLine)
+	var (
+		event string
+		data  strings.Builder
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				handler(event, strings.TrimSuffix(data.String(), "\n"))
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// comment (keep-alive)
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			value := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			if data.Len()+len(value) > maxSize {
+				return utils.ErrStreamTooBig
+			}
+			data.WriteString(value)
+			data.WriteString("\n")
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return utils.ErrStreamTooBig
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,309 @@
+// Package mcp provides tools from Model Context Protocol servers over stdio (local process), streamable HTTP or
+// legacy HTTP+SSE transports.
+//
+// Connection is established lazily on the first tools refresh and re-established on the next refresh if broken.
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/invopop/jsonschema"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tracing"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxResponse = 1024 * 1024
+)
+
+// ClientInfo sent to servers.
+var ClientInfo = Implementation{Name: "pikobrain", Version: "1.0.0"}
+
+type Config struct {
+	Namespace   string               `json:"namespace" yaml:"namespace,omitempty"`       // Prefix all tools with value and underscore
+	Command     string               `json:"command,omitempty" yaml:"command,omitempty"` // Executable of stdio server
+	Args        []string             `json:"args,omitempty" yaml:"args,omitempty"`       // Arguments of stdio server
+	Dir         string               `json:"dir,omitempty" yaml:"dir,omitempty"`         // Working directory of stdio server
+	Env         []utils.Pair[string] `json:"env,omitempty" yaml:"env,omitempty"`         // Extra environment variables of stdio server
+	InheritEnv  bool                 `json:"inherit_env" yaml:"inheritEnv,omitempty"`    // Pass environment of the service to stdio server
+	URL         string               `json:"url,omitempty" yaml:"url,omitempty"`         // URL of HTTP server. Used if command is not set
+	SSE         bool                 `json:"sse" yaml:"sse,omitempty"`                   // Use legacy HTTP+SSE transport instead of streamable HTTP
+	Headers     []utils.Pair[string] `json:"headers,omitempty" yaml:"headers,omitempty"` // Extra outgoing headers for HTTP server
+	Timeout     time.Duration        `json:"timeout" yaml:"timeout,omitempty"`           // Max duration of single request. Default is 30s
+	MaxResponse int                  `json:"max_response" yaml:"maxResponse,omitempty"`  // Max size of single message in bytes. Default is 1MiB
+	Exclude     []string             `json:"exclude,omitempty" yaml:"exclude,omitempty"` // Exclude specific tools (by original name)
+	Include     []string             `json:"include,omitempty" yaml:"include,omitempty"` // Only specific tools (by original name). Default is all
+}
+
+// New provider of tools from MCP server. Configuration is validated immediately, connection is lazy.
+func New(config Config) (types.ToolProviderFunc, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxResponse <= 0 {
+		config.MaxResponse = DefaultMaxResponse
+	}
+	factory, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	srv := &server{
+		config:    config,
+		transport: factory,
+		excluded:  utils.NewSet(config.Exclude...),
+		included:  utils.NewSet(config.Include...),
+	}
+	return srv.tools, nil
+}
+
+func newTransport(config Config) (func() transport, error) {
+	switch {
+	case config.Command != "":
+		path, err := exec.LookPath(config.Command)
+		if err != nil {
+			return nil, fmt.Errorf("find command: %w", err)
+		}
+		var env []string
+		if config.InheritEnv {
+			env = os.Environ()
+		}
+		for _, pair := range config.Env {
+			value, err := pair.Get()
+			if err != nil {
+				return nil, fmt.Errorf("get env %q value: %w", pair.Name, err)
+			}
+			env = append(env, pair.Name+"="+value)
+		}
+		if env == nil {
+			env = []string{} // nil means inherit all
+		}
+		return func() transport {
+			return &stdioTransport{path: path, args: config.Args, dir: config.Dir, env: env, maxResponse: config.MaxResponse}
+		}, nil
+	case config.URL != "":
+		var headers = make(http.Header)
+		for _, h := range config.Headers {
+			value, err := h.Get()
+			if err != nil {
+				return nil, fmt.Errorf("get header %q value: %w", h.Name, err)
+			}
+			headers.Add(h.Name, value)
+		}
+		// no client timeout: streams are long-living, requests are limited by context
+		client := &http.Client{Transport: tracing.Transport(http.DefaultTransport)}
+		if config.SSE {
+			return func() transport {
+				return &sseTransport{url: config.URL, headers: headers, client: client, maxResponse: config.MaxResponse}
+			}, nil
+		}
+		return func() transport {
+			return &streamableTransport{url: config.URL, headers: headers, client: client, maxResponse: config.MaxResponse}
+		}, nil
+	default:
+		return nil, errors.New("command or url should be set")
+	}
+}
+
+type server struct {
+	config    Config
+	transport func() transport
+	excluded  utils.Set[string]
+	included  utils.Set[string]
+
+	lock   sync.Mutex
+	client *Client
+}
+
+// tools lists tools from server. Used as tools provider, so list changes are picked by refresh.
+func (s *server) tools(ctx context.Context) ([]types.Tool, error) {
+	client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	list, err := client.ListTools(ctx)
+	if err != nil {
+		s.reset(client)
+		return nil, fmt.Errorf("list tools: %w", err)
+	}
+
+	var ans = make([]types.Tool, 0, len(list))
+	for _, def := range list {
+		if s.excluded.Contains(def.Name) || (len(s.included) > 0 && !s.included.Contains(def.Name)) {
+			continue
+		}
+		var input = &jsonschema.Schema{Type: "object"}
+		if len(def.InputSchema) > 0 {
+			if err := json.Unmarshal(def.InputSchema, input); err != nil {
+				slog.Warn("ignoring mcp tool with invalid schema", "tool", def.Name, "error", err)
+				continue
+			}
+		}
+		ans = append(ans, &remoteTool{
+			name:        utils.Concat("_", s.config.Namespace, def.Name),
+			remoteName:  def.Name,
+			description: def.Description,
+			input:       input,
+			server:      s,
+		})
+	}
+	return ans, nil
+}
+
+func (s *server) call(ctx context.Context, name string, args json.RawMessage) (*CallToolResult, error) {
+	client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	res, err := client.CallTool(ctx, name, args)
+	if client.Closed() {
+		s.reset(client)
+	}
+	return res, err
+}
+
+// connect returns live client or establishes a new connection.
+func (s *server) connect(ctx context.Context) (*Client, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.client != nil && !s.client.Closed() {
+		return s.client, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	client, err := connect(ctx, s.transport(), ClientInfo)
+	if err != nil {
+		return nil, fmt.Errorf("connect to mcp server: %w", err)
+	}
+	s.client = client
+	return client, nil
+}
+
+// reset drops broken client, so the next call reconnects.
+func (s *server) reset(client *Client) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.client == client {
+		s.client = nil
+	}
+	_ = client.Close()
+}
+
+type remoteTool struct {
+	name        string
+	remoteName  string
+	description string
+	input       *jsonschema.Schema
+	server      *server
+}
+
+func (rt *remoteTool) Name() string {
+	return rt.name
+}
+
+func (rt *remoteTool) Description() string {
+	return rt.description
+}
+
+func (rt *remoteTool) Input() *jsonschema.Schema {
+	return rt.input
+}
+
+func (rt *remoteTool) Call(ctx context.Context, args json.RawMessage) (types.Content, error) {
+	res, err := rt.server.call(ctx, rt.remoteName, args)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("call mcp tool: %w", err)
+	}
+	content, err := mapResult(res.Content)
+	if err != nil {
+		return types.Content{}, err
+	}
+	if res.IsError {
+		return types.Content{}, fmt.Errorf("mcp tool failed: %s", content.String())
+	}
+	if len(content.Data) > rt.server.config.MaxResponse {
+		return types.Content{}, fmt.Errorf("result is too big (%d bytes, limit %d)", len(content.Data), rt.server.config.MaxResponse)
+	}
+	return content, nil
+}
+
+// mapResult converts MCP content to single content. Single item is mapped as-is, multiple items are merged as text
+// where binary items are replaced by placeholders.
+func mapResult(items []ContentItem) (types.Content, error) {
+	if len(items) == 0 {
+		return types.Text(""), nil
+	}
+	var parts = make([]types.Content, 0, len(items))
+	for _, item := range items {
+		part, err := mapItem(item)
+		if err != nil {
+			return types.Content{}, err
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	var texts = make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Mime.IsText() {
+			texts = append(texts, string(part.Data))
+		} else {
+			texts = append(texts, utils.Concat(" ", "["+string(part.Mime), part.Name)+"]")
+		}
+	}
+	return types.Text(strings.Join(texts, "\n")), nil
+}
+
+func mapItem(item ContentItem) (types.Content, error) {
+	switch item.Type {
+	case "text":
+		return types.Text(item.Text), nil
+	case "image", "audio":
+		data, err := base64.StdEncoding.DecodeString(item.Data)
+		if err != nil {
+			return types.Content{}, fmt.Errorf("decode %s: %w", item.Type, err)
+		}
+		return types.Content{Data: data, Mime: types.MIME(item.MimeType)}, nil
+	case "resource":
+		if item.Resource == nil {
+			return types.Content{}, errors.New("empty resource")
+		}
+		res := item.Resource
+		if res.Blob != "" {
+			data, err := base64.StdEncoding.DecodeString(res.Blob)
+			if err != nil {
+				return types.Content{}, fmt.Errorf("decode resource: %w", err)
+			}
+			return types.Content{Data: data, Mime: types.MIME(res.MimeType), Name: res.URI}, nil
+		}
+		// text resource may have any type (ex: source code), but it's still a text for model
+		var mime = types.MIMEText
+		if types.MIME(res.MimeType).IsText() {
+			mime = types.MIME(res.MimeType)
+		}
+		return types.Content{Data: []byte(res.Text), Mime: mime, Name: res.URI}, nil
+	case "resource_link":
+		return types.Text(utils.Concat(" ", item.Name, item.URI)), nil
+	default:
+		return types.Content{}, fmt.Errorf("unsupported content type %q", item.Type)
+	}
+}
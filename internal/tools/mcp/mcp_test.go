@@ -0,0 +1,314 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/mcp"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const stdioServerEnv = "MCP_TEST_STDIO_SERVER"
+
+func TestMain(m *testing.M) {
+	// test binary acts as stdio server
+	if os.Getenv(stdioServerEnv) != "" {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if res := handle(scanner.Bytes()); res != nil {
+				_, _ = os.Stdout.Write(append(res, '\n'))
+			}
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestNew_streamable(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprint("stream=", stream), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if request.Method == http.MethodDelete {
+					return
+				}
+				var payload json.RawMessage
+				require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
+				res := handle(payload)
+				writer.Header().Set("Mcp-Session-Id", "session-1")
+				if res == nil {
+					writer.WriteHeader(http.StatusAccepted)
+					return
+				}
+				if stream {
+					writer.Header().Set("Content-Type", "text/event-stream")
+					_, _ = fmt.Fprintf(writer, "event: message\ndata: %s\n\n", res)
+					return
+				}
+				writer.Header().Set("Content-Type", "application/json")
+				_, _ = writer.Write(res)
+			}))
+			defer srv.Close()
+
+			testProvider(t, mcp.Config{URL: srv.URL, Namespace: "remote"})
+		})
+	}
+}
+
+func TestNew_sse(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		clients = map[string]chan []byte{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sse", func(writer http.ResponseWriter, request *http.Request) {
+		ch := make(chan []byte, 10)
+		lock.Lock()
+		clients["1"] = ch
+		lock.Unlock()
+		writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(writer, "event: endpoint\ndata: /messages?session=1\n\n")
+		writer.(http.Flusher).Flush()
+		for {
+			select {
+			case <-request.Context().Done():
+				return
+			case msg := <-ch:
+				_, _ = fmt.Fprintf(writer, "event: message\ndata: %s\n\n", msg)
+				writer.(http.Flusher).Flush()
+			}
+		}
+	})
+	mux.HandleFunc("POST /messages", func(writer http.ResponseWriter, request *http.Request) {
+		var payload json.RawMessage
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
+		lock.Lock()
+		ch := clients[request.URL.Query().Get("session")]
+		lock.Unlock()
+		if res := handle(payload); res != nil {
+			ch <- res
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	defer srv.CloseClientConnections() // provider keeps stream open
+
+	testProvider(t, mcp.Config{URL: srv.URL + "/sse", SSE: true, Namespace: "remote"})
+}
+
+func TestNew_streamableSessionExpired(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		sessions int
+		expired  = map[string]bool{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodDelete {
+			return
+		}
+		var payload json.RawMessage
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
+		var msg mcp.Message
+		require.NoError(t, json.Unmarshal(payload, &msg))
+
+		lock.Lock()
+		session := request.Header.Get("Mcp-Session-Id")
+		if msg.Method == mcp.MethodInitialize {
+			sessions++
+			session = fmt.Sprint("session-", sessions)
+		}
+		gone := expired[session]
+		lock.Unlock()
+		if gone {
+			http.Error(writer, "session not found", http.StatusNotFound)
+			return
+		}
+		writer.Header().Set("Mcp-Session-Id", session)
+		res := handle(payload)
+		if res == nil {
+			writer.WriteHeader(http.StatusAccepted)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write(res)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	provider, err := mcp.New(mcp.Config{URL: srv.URL})
+	require.NoError(t, err)
+	tools, err := provider(ctx)
+	require.NoError(t, err)
+	var echo types.Tool
+	for _, tool := range tools {
+		if tool.Name() == "echo" {
+			echo = tool
+		}
+	}
+	require.NotNil(t, echo)
+
+	lock.Lock()
+	expired["session-1"] = true
+	lock.Unlock()
+
+	_, err = echo.Call(ctx, json.RawMessage(`{"text": "hello"}`))
+	require.ErrorIs(t, err, mcp.ErrClosed)
+
+	// the next call reconnects without tools refresh
+	out, err := echo.Call(ctx, json.RawMessage(`{"text": "hello"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(out.Data))
+	lock.Lock()
+	assert.Equal(t, 2, sessions)
+	lock.Unlock()
+}
+
+func TestNew_sseMaxResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.WriteHeader(http.StatusAccepted)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(writer, "event: endpoint\ndata: /messages\n\n")
+		// endless line without delimiter
+		_, _ = fmt.Fprint(writer, "data: ", strings.Repeat("x", 128*1024))
+		writer.(http.Flusher).Flush()
+		<-request.Context().Done()
+	}))
+	defer srv.Close()
+	defer srv.CloseClientConnections()
+
+	provider, err := mcp.New(mcp.Config{URL: srv.URL, SSE: true, MaxResponse: 1024})
+	require.NoError(t, err)
+	_, err = provider(context.Background())
+	require.ErrorIs(t, err, utils.ErrStreamTooBig)
+}
+
+func TestNew_stdio(t *testing.T) {
+	t.Setenv(stdioServerEnv, "1")
+	testProvider(t, mcp.Config{Command: os.Args[0], InheritEnv: true, Namespace: "remote"})
+}
+
+func TestNew_stdioMaxResponse(t *testing.T) {
+	t.Setenv(stdioServerEnv, "1")
+	provider, err := mcp.New(mcp.Config{Command: os.Args[0], InheritEnv: true, MaxResponse: 32})
+	require.NoError(t, err)
+
+	// initialization response exceeds limit
+	_, err = provider(context.Background())
+	require.ErrorIs(t, err, utils.ErrStreamTooBig)
+}
+
+func TestNew_invalid(t *testing.T) {
+	_, err := mcp.New(mcp.Config{})
+	require.Error(t, err)
+}
+
+func testProvider(t *testing.T, config mcp.Config) {
+	t.Helper()
+	ctx := context.Background()
+	provider, err := mcp.New(config)
+	require.NoError(t, err)
+
+	tools, err := provider(ctx)
+	require.NoError(t, err)
+	require.Len(t, tools, 4)
+
+	var snapshot = make(types.Snapshot)
+	for _, tool := range tools {
+		snapshot[tool.Name()] = tool
+	}
+	echo := snapshot["remote_echo"]
+	require.NotNil(t, echo)
+	assert.Equal(t, "Echo text", echo.Description())
+	assert.Equal(t, "object", echo.Input().Type)
+
+	out, err := echo.Call(ctx, json.RawMessage(`{"text": "hello"}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEText, out.Mime)
+	assert.Equal(t, "hello", string(out.Data))
+
+	out, err = snapshot["remote_image"].Call(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEPng, out.Mime)
+	assert.Equal(t, []byte("PNG"), out.Data)
+
+	out, err = snapshot["remote_resource"].Call(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEText, out.Mime)
+	assert.Equal(t, "file:///readme.txt", out.Name)
+	assert.Equal(t, "read me", string(out.Data))
+
+	_, err = snapshot["remote_fail"].Call(ctx, json.RawMessage(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+}
+
+// handle is a fake MCP server.
+func handle(payload []byte) []byte {
+	var msg mcp.Message
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.IsNotification() {
+		return nil
+	}
+	var result any
+	switch msg.Method {
+	case mcp.MethodInitialize:
+		result = mcp.InitializeResult{
+			ProtocolVersion: mcp.ProtocolVersion,
+			ServerInfo:      mcp.Implementation{Name: "test", Version: "1"},
+			Capabilities:    mcp.ServerCapabilities{Tools: &mcp.ToolsCapability{}},
+		}
+	case mcp.MethodToolsList:
+		var params mcp.ListToolsParams
+		_ = json.Unmarshal(msg.Params, &params)
+		schema := json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}}}`)
+		// two pages
+		if params.Cursor == "" {
+			result = mcp.ListToolsResult{NextCursor: "next", Tools: []mcp.Tool{
+				{Name: "echo", Description: "Echo text", InputSchema: schema},
+				{Name: "image", InputSchema: json.RawMessage(`{"type":"object"}`)},
+			}}
+		} else {
+			result = mcp.ListToolsResult{Tools: []mcp.Tool{
+				{Name: "resource", InputSchema: json.RawMessage(`{"type":"object"}`)},
+				{Name: "fail", InputSchema: json.RawMessage(`{"type":"object"}`)},
+			}}
+		}
+	case mcp.MethodToolsCall:
+		var params struct {
+			Name      string            `json:"name"`
+			Arguments map[string]string `json:"arguments"`
+		}
+		_ = json.Unmarshal(msg.Params, &params)
+		switch params.Name {
+		case "echo":
+			result = mcp.CallToolResult{Content: []mcp.ContentItem{{Type: "text", Text: params.Arguments["text"]}}}
+		case "image":
+			result = mcp.CallToolResult{Content: []mcp.ContentItem{{Type: "image", MimeType: "image/png", Data: base64.StdEncoding.EncodeToString([]byte("PNG"))}}}
+		case "resource":
+			result = mcp.CallToolResult{Content: []mcp.ContentItem{{Type: "resource", Resource: &mcp.ResourceContents{URI: "file:///readme.txt", MimeType: "text/plain", Text: "read me"}}}}
+		case "fail":
+			result = mcp.CallToolResult{IsError: true, Content: []mcp.ContentItem{{Type: "text", Text: "broken"}}}
+		}
+	default:
+		data, _ := json.Marshal(mcp.Message{JSONRPC: "2.0", ID: msg.ID, Error: &mcp.Error{Code: mcp.CodeMethodNotFound, Message: "not found"}})
+		return data
+	}
+	raw, _ := json.Marshal(result)
+	data, _ := json.Marshal(mcp.Message{JSONRPC: "2.0", ID: msg.ID, Result: raw})
+	return data
+}
@@ -0,0 +1,367 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+// DefaultMaxRequest is the max size of incoming HTTP message.
+const DefaultMaxRequest = 10 * 1024 * 1024
+
+// Server exposes tools over MCP (stdio or streamable HTTP). Server is stateless: session ID is issued, but not
+// required; notifications are delivered to stdio clients and HTTP clients with opened event stream.
+type Server struct {
+	Toolbox      types.Toolbox  // Dynamic tools
+	Tools        []types.Tool   // Additional static tools
+	Info         Implementation // Server info. Default is ClientInfo
+	Instructions string         // Optional instructions for clients
+	// Optional tool invocation (ex: instrumented). Default is direct call of snapshot.
+	Call func(ctx context.Context, tools types.Snapshot, name string, id string, input json.RawMessage) (types.Content, error)
+
+	lock      sync.Mutex
+	listeners map[chan []byte]struct{}
+	digest    string
+}
+
+// Snapshot of all published tools.
+func (s *Server) Snapshot() types.Snapshot {
+	var snapshot = make(types.Snapshot)
+	if s.Toolbox != nil {
+		for name, tool := range s.Toolbox.Snapshot() {
+			snapshot[name] = tool
+		}
+	}
+	for _, tool := range s.Tools {
+		snapshot[tool.Name()] = tool
+	}
+	return snapshot
+}
+
+// Refresh should be called after toolbox update. If tools list changed, clients are notified.
+func (s *Server) Refresh() {
+	digest := toolsDigest(s.Snapshot())
+	s.lock.Lock()
+	changed := s.digest != "" && s.digest != digest
+	s.digest = digest
+	var listeners = make([]chan []byte, 0, len(s.listeners))
+	for ch := range s.listeners {
+		listeners = append(listeners, ch)
+	}
+	s.lock.Unlock()
+	if !changed {
+		return
+	}
+	slog.Debug("mcp tools list changed", "clients", len(listeners))
+	payload, _ := encode(nil, NotifyToolsListChanged, nil)
+	for _, ch := range listeners {
+		select {
+		case ch <- payload:
+		default:
+			// slow client, it will get the list on the next request anyway
+		}
+	}
+}
+
+// Handle single JSON-RPC message. Returns nil for notifications and responses.
+func (s *Server) Handle(ctx context.Context, payload []byte) []byte {
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return s.reply(json.RawMessage("null"), nil, &Error{Code: CodeParseError, Message: err.Error()})
+	}
+	if !msg.IsRequest() {
+		return nil // responses are not expected: server does not send requests
+	}
+	if msg.IsNotification() {
+		slog.Debug("mcp notification", "method", msg.Method)
+		return nil
+	}
+	result, err := s.dispatch(ctx, msg)
+	return s.reply(msg.ID, result, err)
+}
+
+func (s *Server) dispatch(ctx context.Context, msg Message) (any, *Error) {
+	switch msg.Method {
+	case MethodInitialize:
+		var params InitializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		version := ProtocolVersion
+		if params.ProtocolVersion == "2024-11-05" {
+			version = params.ProtocolVersion // tools API is the same
+		}
+		info := s.Info
+		if info.Name == "" {
+			info = ClientInfo
+		}
+		return InitializeResult{
+			ProtocolVersion: version,
+			Capabilities:    ServerCapabilities{Tools: &ToolsCapability{ListChanged: true}},
+			ServerInfo:      info,
+			Instructions:    s.Instructions,
+		}, nil
+	case MethodPing:
+		return struct{}{}, nil
+	case MethodToolsList:
+		return s.listTools()
+	case MethodToolsCall:
+		var params CallToolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		return s.callTool(ctx, params)
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not supported: " + msg.Method}
+	}
+}
+
+func (s *Server) listTools() (any, *Error) {
+	snapshot := s.Snapshot()
+	var ans = ListToolsResult{Tools: make([]Tool, 0, len(snapshot))}
+	for _, tool := range snapshot {
+		schema, err := json.Marshal(tool.Input())
+		if err != nil {
+			return nil, &Error{Code: CodeInternalError, Message: fmt.Sprintf("encode schema of %q: %v", tool.Name(), err)}
+		}
+		ans.Tools = append(ans.Tools, Tool{Name: tool.Name(), Description: tool.Description(), InputSchema: schema})
+	}
+	slices.SortFunc(ans.Tools, func(a, b Tool) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return ans, nil
+}
+
+func (s *Server) callTool(ctx context.Context, params CallToolParams) (any, *Error) {
+	snapshot := s.Snapshot()
+	if _, ok := snapshot[params.Name]; !ok {
+		return nil, &Error{Code: CodeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+	args := params.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	call := s.Call
+	if call == nil {
+		call = func(ctx context.Context, tools types.Snapshot, name string, _ string, input json.RawMessage) (types.Content, error) {
+			return tools.Call(ctx, name, input)
+		}
+	}
+	out, err := call(ctx, snapshot, params.Name, "", args)
+	if err != nil {
+		// tool errors are part of result, so model can see them
+		return CallToolResult{IsError: true, Content: []ContentItem{{Type: "text", Text: err.Error()}}}, nil
+	}
+	return CallToolResult{Content: []ContentItem{toItem(out)}}, nil
+}
+
+func (s *Server) reply(id json.RawMessage, result any, rpcErr *Error) []byte {
+	res := Message{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			res.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+		} else {
+			res.Result = raw
+		}
+	}
+	data, _ := json.Marshal(res)
+	return data
+}
+
+func (s *Server) listen() chan []byte {
+	ch := make(chan []byte, 8)
+	s.lock.Lock()
+	if s.listeners == nil {
+		s.listeners = make(map[chan []byte]struct{})
+	}
+	s.listeners[ch] = struct{}{}
+	s.lock.Unlock()
+	return ch
+}
+
+func (s *Server) unlisten(ch chan []byte) {
+	s.lock.Lock()
+	delete(s.listeners, ch)
+	s.lock.Unlock()
+}
+
+// ServeStdio serves single client over new-line delimited messages until input closed or context canceled.
+func (s *Server) ServeStdio(ctx context.Context, input io.Reader, output io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		writeLock sync.Mutex
+		wg        sync.WaitGroup
+	)
+	write := func(payload []byte) {
+		writeLock.Lock()
+		defer writeLock.Unlock()
+		if _, err := output.Write(append(payload, '\n')); err != nil {
+			slog.Warn("failed write mcp message", "error", err)
+		}
+	}
+
+	notifications := s.listen()
+	defer s.unlisten(notifications)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case payload := <-notifications:
+				write(payload)
+			}
+		}
+	}()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(input)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 1 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read input: %w", err)
+		case line := <-lines:
+			// requests are processed concurrently: long chat should not block pings
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if res := s.Handle(ctx, line); res != nil {
+					write(res)
+				}
+			}()
+		}
+	}
+}
+
+// ServeHTTP implements streamable HTTP transport: POST for messages (JSON response), GET for notifications stream.
+func (s *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodPost:
+		s.servePost(writer, request)
+	case http.MethodGet:
+		s.serveStream(writer, request)
+	case http.MethodDelete:
+		writer.WriteHeader(http.StatusOK) // sessions are not tracked
+	default:
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) servePost(writer http.ResponseWriter, request *http.Request) {
+	payload, err := io.ReadAll(utils.NewLimitedReader(request.Body, DefaultMaxRequest))
+	if err != nil {
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	var msg Message
+	if json.Unmarshal(payload, &msg) == nil && msg.Method == MethodInitialize {
+		writer.Header().Set(headerSession, utils.RandomID(""))
+	}
+	res := s.Handle(request.Context(), payload)
+	if res == nil {
+		writer.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(res)
+}
+
+func (s *Server) serveStream(writer http.ResponseWriter, request *http.Request) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	notifications := s.listen()
+	defer s.unlisten(notifications)
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case payload := <-notifications:
+			if _, err := fmt.Fprintf(writer, "event: message\ndata: %s\n\n", payload); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// toItem converts content to MCP content item.
+func toItem(content types.Content) ContentItem {
+	switch {
+	case content.Mime.IsText():
+		return ContentItem{Type: "text", Text: string(content.Data)}
+	case content.Mime.IsImage():
+		return ContentItem{Type: "image", MimeType: string(content.Mime), Data: base64.StdEncoding.EncodeToString(content.Data)}
+	case content.Mime.IsAudio():
+		return ContentItem{Type: "audio", MimeType: string(content.Mime), Data: base64.StdEncoding.EncodeToString(content.Data)}
+	default:
+		return ContentItem{Type: "resource", Resource: &ResourceContents{
+			URI:      "file:///" + content.Name,
+			MimeType: string(content.Mime),
+			Blob:     base64.StdEncoding.EncodeToString(content.Data),
+		}}
+	}
+}
+
+func toolsDigest(snapshot types.Snapshot) string {
+	var names = make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	hash := sha256.New()
+	for _, name := range names {
+		tool := snapshot[name]
+		schema, _ := json.Marshal(tool.Input())
+		_, _ = fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", name, tool.Description(), schema)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,130 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/mcp"
+)
+
+type echoRequest struct {
+	Text string `json:"text"`
+}
+
+func echoTool(name string) types.Tool {
+	return types.MustTool(name, "Echo text", func(ctx context.Context, payload echoRequest) (types.Content, error) {
+		if payload.Text == "" {
+			return types.Content{}, errors.New("empty text")
+		}
+		return types.Text(payload.Text), nil
+	})
+}
+
+func TestServer_http(t *testing.T) {
+	var toolbox types.DynamicToolbox
+	toolbox.Add(echoTool("echo"))
+	require.NoError(t, toolbox.Update(context.Background(), true))
+
+	var called []string
+	srv := httptest.NewServer(&mcp.Server{
+		Toolbox: &toolbox,
+		Tools:   []types.Tool{echoTool("static")},
+		Call: func(ctx context.Context, tools types.Snapshot, name string, id string, input json.RawMessage) (types.Content, error) {
+			called = append(called, name)
+			return tools.Call(ctx, name, input)
+		},
+	})
+	defer srv.Close()
+
+	// use own client
+	provider, err := mcp.New(mcp.Config{URL: srv.URL})
+	require.NoError(t, err)
+	tools, err := provider(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "echo", tools[0].Name())
+	assert.Equal(t, "static", tools[1].Name())
+	_, ok := tools[0].Input().Properties.Get("text")
+	assert.True(t, ok)
+
+	out, err := tools[0].Call(context.Background(), json.RawMessage(`{"text": "hello"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(out.Data))
+
+	_, err = tools[1].Call(context.Background(), json.RawMessage(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty text")
+	assert.Equal(t, []string{"echo", "static"}, called)
+}
+
+func TestServer_stdio(t *testing.T) {
+	var (
+		enabled = []types.Tool{echoTool("echo")}
+		toolbox types.DynamicToolbox
+	)
+	toolbox.Provider(func(ctx context.Context) ([]types.Tool, error) {
+		return enabled, nil
+	})
+	require.NoError(t, toolbox.Update(context.Background(), true))
+
+	server := &mcp.Server{Toolbox: &toolbox}
+	server.Refresh()
+
+	inputReader, input := io.Pipe()
+	output, outputWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ServeStdio(context.Background(), inputReader, outputWriter)
+	}()
+	lines := bufio.NewScanner(output)
+	request := func(payload string) mcp.Message {
+		_, err := input.Write([]byte(payload + "\n"))
+		require.NoError(t, err)
+		require.True(t, lines.Scan())
+		var msg mcp.Message
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &msg))
+		return msg
+	}
+
+	res := request(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	require.Nil(t, res.Error)
+	var info mcp.InitializeResult
+	require.NoError(t, json.Unmarshal(res.Result, &info))
+	assert.True(t, info.Capabilities.Tools.ListChanged)
+
+	res = request(`{"jsonrpc":"2.0","id":2,"method":"unknown"}`)
+	require.NotNil(t, res.Error)
+	assert.Equal(t, mcp.CodeMethodNotFound, res.Error.Code)
+
+	// tools changed
+	enabled = append(enabled, echoTool("echo2"))
+	require.NoError(t, toolbox.Update(context.Background(), true))
+	server.Refresh()
+	require.True(t, lines.Scan())
+	var notification mcp.Message
+	require.NoError(t, json.Unmarshal(lines.Bytes(), &notification))
+	assert.Equal(t, mcp.NotifyToolsListChanged, notification.Method)
+
+	res = request(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	var list mcp.ListToolsResult
+	require.NoError(t, json.Unmarshal(res.Result, &list))
+	assert.Len(t, list.Tools, 2)
+
+	require.NoError(t, input.Close())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not stopped")
+	}
+}
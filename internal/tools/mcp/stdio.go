@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+// stdioTransport spawns local process and exchanges new-line delimited messages over stdin/stdout.
+// Process is killed if message exceeds max response size.
+type stdioTransport struct {
+	path        string
+	args        []string
+	dir         string
+	env         []string
+	maxResponse int
+
+	lock   sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	exited chan struct{}
+}
+
+func (st *stdioTransport) start(_ context.Context, receive func([]byte), fail func(error)) error {
+	// process should outlive context of the first request
+	cmd := exec.Command(st.path, st.args...) //nolint:gosec
+	cmd.Dir = st.dir
+	cmd.Env = st.env
+	cmd.WaitDelay = time.Second
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("stderr: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start process: %w", err)
+	}
+	st.cmd = cmd
+	st.stdin = stdin
+	st.exited = make(chan struct{})
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			slog.Debug("mcp server log", "command", st.path, "line", scanner.Text())
+		}
+	}()
+
+	go func() {
+		err := st.read(stdout, receive)
+		if err != nil {
+			_ = cmd.Process.Kill()
+		}
+		<-logged // pipes are closed by Wait, so all reads should be finished before
+		if waitErr := cmd.Wait(); err == nil {
+			err = waitErr
+		}
+		close(st.exited)
+		if err == nil {
+			err = ErrClosed
+		}
+		fail(fmt.Errorf("mcp server process exited: %w", err))
+	}()
+	return nil
+}
+
+// read messages till the end of stream.
+func (st *stdioTransport) read(stdout io.Reader, receive func([]byte)) error {
+	scanner := bufio.NewScanner(stdout)
+	// the line should fit with delimiter
+	scanner.Buffer(make([]byte, 0, min(64*1024, st.maxResponse+1)), st.maxResponse+1)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			receive(bytes.Clone(line))
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return fmt.Errorf("read message: %w (limit %d bytes)", utils.ErrStreamTooBig, st.maxResponse)
+	}
+	return scanner.Err()
+}
+
+func (st *stdioTransport) send(_ context.Context, payload []byte) error {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	if st.stdin == nil {
+		return ErrClosed
+	}
+	if _, err := st.stdin.Write(append(payload, '\n')); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+func (st *stdioTransport) close() error {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	if st.stdin == nil {
+		return nil
+	}
+	// closing stdin is the graceful way to stop server, kill it if it's not enough
+	err := st.stdin.Close()
+	st.stdin = nil
+	cmd, exited := st.cmd, st.exited
+	go func() {
+		select {
+		case <-exited:
+		case <-time.After(5 * time.Second):
+			_ = cmd.Process.Kill()
+		}
+	}()
+	if errors.Is(err, io.ErrClosedPipe) {
+		err = nil
+	}
+	return err
+}
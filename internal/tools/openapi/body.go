@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+)
+
+// Encode body generated by model (JSON) according to content type. Returns payload and Content-Type header.
+func (rb *requestBody) Encode(ctx context.Context, body json.RawMessage) ([]byte, string, error) {
+	switch rb.ContentType {
+	case mimeText:
+		var text string
+		if json.Unmarshal(body, &text) == nil {
+			return []byte(text), mimeText, nil
+		}
+		// model may send value of other type
+		return body, mimeText, nil
+	case mimeForm:
+		fields, err := decodeFields(body)
+		if err != nil {
+			return nil, "", err
+		}
+		var values = make(url.Values)
+		for _, name := range sortedKeys(fields) {
+			for _, v := range listOf(fields[name]) {
+				values.Add(name, formValue(v))
+			}
+		}
+		return []byte(values.Encode()), mimeForm, nil
+	case mimeMultipart:
+		return rb.encodeMultipart(ctx, body)
+	default:
+		return body, rb.ContentType, nil
+	}
+}
+
+func (rb *requestBody) encodeMultipart(ctx context.Context, body json.RawMessage) ([]byte, string, error) {
+	fields, err := decodeFields(body)
+	if err != nil {
+		return nil, "", err
+	}
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	for _, name := range sortedKeys(fields) {
+		for _, v := range listOf(fields[name]) {
+			if !rb.Files.Contains(name) {
+				if err := writer.WriteField(name, formValue(v)); err != nil {
+					return nil, "", fmt.Errorf("write field %q: %w", name, err)
+				}
+				continue
+			}
+			file, err := findAttachment(ctx, formValue(v))
+			if err != nil {
+				return nil, "", fmt.Errorf("field %q: %w", name, err)
+			}
+			fileName := file.Name
+			if fileName == "" {
+				fileName = name
+			}
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, name, fileName))
+			header.Set("Content-Type", string(file.Mime))
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, "", fmt.Errorf("create part %q: %w", name, err)
+			}
+			if _, err := part.Write(file.Data); err != nil {
+				return nil, "", fmt.Errorf("write part %q: %w", name, err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("close multipart: %w", err)
+	}
+	return buffer.Bytes(), writer.FormDataContentType(), nil
+}
+
+// findAttachment by file name or by number (starting from 1).
+func findAttachment(ctx context.Context, ref string) (types.Content, error) {
+	attachments := types.AttachmentsFrom(ctx)
+	for _, file := range attachments {
+		if file.Name != "" && file.Name == ref {
+			return file, nil
+		}
+	}
+	if idx, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil && idx >= 1 && idx <= len(attachments) {
+		return attachments[idx-1], nil
+	}
+	return types.Content{}, fmt.Errorf("attachment %q not found (%d available)", ref, len(attachments))
+}
+
+func decodeFields(body json.RawMessage) (map[string]any, error) {
+	var fields map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // keep numbers as is
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("body should be an object: %w", err)
+	}
+	return fields, nil
+}
+
+func sortedKeys(fields map[string]any) []string {
+	var keys = make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// listOf values: arrays are sent as repeated fields.
+func listOf(value any) []any {
+	if list, ok := value.([]any); ok {
+		return list
+	}
+	if value == nil {
+		return nil
+	}
+	return []any{value}
+}
+
+// formValue of field: scalars as is, objects as JSON.
+func formValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,198 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/openapi"
+)
+
+const bodiesSpec = `{
+  "openapi": "3.0.0",
+  "servers": [{"url": "/"}],
+  "paths": {
+    "/form": {"post": {
+      "operationId": "form",
+      "requestBody": {"required": true, "content": {"application/x-www-form-urlencoded": {"schema": {
+        "type": "object",
+        "properties": {"name": {"type": "string"}, "tags": {"type": "array", "items": {"type": "string"}}, "count": {"type": "integer"}}
+      }}}}
+    }},
+    "/upload": {"post": {
+      "operationId": "upload",
+      "requestBody": {"content": {"multipart/form-data": {"schema": {"$ref": "#/components/schemas/Upload"}}}}
+    }},
+    "/upload-composed": {"post": {
+      "operationId": "uploadComposed",
+      "requestBody": {"content": {"multipart/form-data": {"schema": {"allOf": [
+        {"$ref": "#/components/schemas/Meta"},
+        {"type": "object", "properties": {"file": {"$ref": "#/components/schemas/Binary"}, "pages": {"type": "array", "items": {"$ref": "#/components/schemas/Binary"}}}}
+      ]}}}}
+    }},
+    "/text": {"post": {
+      "operationId": "text",
+      "requestBody": {"content": {"text/plain; charset=utf-8": {"schema": {"type": "string", "description": "Note"}}}}
+    }},
+    "/patch": {"patch": {
+      "operationId": "patch",
+      "requestBody": {"content": {"application/merge-patch+json": {"schema": {"type": "object"}}}}
+    }},
+    "/xml": {"post": {
+      "operationId": "xml",
+      "requestBody": {"content": {"application/xml": {"schema": {"type": "object"}}}}
+    }}
+  },
+  "components": {"schemas": {"Upload": {
+    "type": "object",
+    "properties": {"title": {"type": "string"}, "file": {"type": "string", "format": "binary", "description": "Document"}}
+  }, "Meta": {
+    "type": "object",
+    "required": ["title"],
+    "properties": {"title": {"type": "string"}}
+  }, "Binary": {
+    "type": "string", "format": "binary", "description": "Scan"
+  }}}
+}`
+
+type captured struct {
+	contentType string
+	form        map[string][]string
+	fileName    string
+	fileType    string
+	fileData    string
+	body        string
+}
+
+func TestNew_bodies(t *testing.T) {
+	var last captured
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(bodiesSpec))
+	})
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		last = captured{contentType: request.Header.Get("Content-Type")}
+		switch request.URL.Path {
+		case "/form":
+			require.NoError(t, request.ParseForm())
+			last.form = request.PostForm
+		case "/upload", "/upload-composed":
+			require.NoError(t, request.ParseMultipartForm(1024*1024))
+			last.form = request.MultipartForm.Value
+			file, header, err := request.FormFile("file")
+			require.NoError(t, err)
+			data, err := io.ReadAll(file)
+			require.NoError(t, err)
+			last.fileName = header.Filename
+			last.fileType = header.Header.Get("Content-Type")
+			last.fileData = string(data)
+		default:
+			data, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			last.body = string(data)
+		}
+		writer.Header().Set("Content-Type", "text/plain")
+		_, _ = writer.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	_, err := openapi.New(context.Background(), openapi.Config{URL: srv.URL + "/openapi.json"})
+	require.Error(t, err) // xml is not supported
+	assert.Contains(t, err.Error(), "does not support")
+
+	tools, err := openapi.New(context.Background(), openapi.Config{URL: srv.URL + "/openapi.json", Exclude: []string{"xml"}})
+	require.NoError(t, err)
+	var snapshot = make(types.Snapshot)
+	for _, tool := range tools {
+		snapshot[tool.Name()] = tool
+	}
+	require.Len(t, snapshot, 5)
+
+	ctx := types.WithAttachments(context.Background(), []types.Content{
+		{Data: []byte("image"), Mime: types.MIMEPng},
+		{Data: []byte("%PDF"), Mime: types.MIMEPdf, Name: "report.pdf"},
+	})
+
+	t.Run("form", func(t *testing.T) {
+		_, err := snapshot.Call(ctx, "form", json.RawMessage(`{"body": {"name": "Alice", "tags": ["a", "b"], "count": 12345678}}`))
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-www-form-urlencoded", last.contentType)
+		assert.Equal(t, map[string][]string{"name": {"Alice"}, "tags": {"a", "b"}, "count": {"12345678"}}, last.form)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		body, ok := snapshot["upload"].Input().Properties.Get("body")
+		require.True(t, ok)
+		file, ok := body.Properties.Get("file")
+		require.True(t, ok)
+		assert.Equal(t, "string", file.Type)
+		assert.Empty(t, file.Format)
+		assert.Contains(t, file.Description, "Document")
+
+		_, err := snapshot.Call(ctx, "upload", json.RawMessage(`{"body": {"title": "Q3", "file": "report.pdf"}}`))
+		require.NoError(t, err)
+		assert.Contains(t, last.contentType, "multipart/form-data")
+		assert.Equal(t, map[string][]string{"title": {"Q3"}}, last.form)
+		assert.Equal(t, "report.pdf", last.fileName)
+		assert.Equal(t, "application/pdf", last.fileType)
+		assert.Equal(t, "%PDF", last.fileData)
+
+		// by number
+		_, err = snapshot.Call(ctx, "upload", json.RawMessage(`{"body": {"file": "1"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, "file", last.fileName)
+		assert.Equal(t, "image", last.fileData)
+
+		_, err = snapshot.Call(ctx, "upload", json.RawMessage(`{"body": {"file": "missing.txt"}}`))
+		require.Error(t, err)
+	})
+
+	t.Run("multipart composition", func(t *testing.T) {
+		body, ok := snapshot["uploadComposed"].Input().Properties.Get("body")
+		require.True(t, ok)
+		assert.Equal(t, []string{"title"}, body.Required)
+		for _, name := range []string{"title", "file", "pages"} {
+			_, ok := body.Properties.Get(name)
+			assert.True(t, ok, name)
+		}
+		file, ok := body.Properties.Get("file")
+		require.True(t, ok)
+		assert.Empty(t, file.Ref)
+		assert.Contains(t, file.Description, "Scan")
+		pages, ok := body.Properties.Get("pages")
+		require.True(t, ok)
+		assert.Equal(t, "array", pages.Type)
+
+		_, err := snapshot.Call(ctx, "uploadComposed", json.RawMessage(`{"body": {"title": "Q3", "file": "report.pdf"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"title": {"Q3"}}, last.form)
+		assert.Equal(t, "report.pdf", last.fileName)
+		assert.Equal(t, "%PDF", last.fileData)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		body, ok := snapshot["text"].Input().Properties.Get("body")
+		require.True(t, ok)
+		assert.Equal(t, "string", body.Type)
+
+		_, err := snapshot.Call(ctx, "text", json.RawMessage(`{"body": "hello\nworld"}`))
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain", last.contentType)
+		assert.Equal(t, "hello\nworld", last.body)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		_, err := snapshot.Call(ctx, "patch", json.RawMessage(`{"body": {"name": null}}`))
+		require.NoError(t, err)
+		assert.Equal(t, "application/merge-patch+json", last.contentType)
+		assert.JSONEq(t, `{"name": null}`, last.body)
+	})
+}
@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten_keepsDefinitions(t *testing.T) {
+	tag := &jsonschema.Schema{Type: "string"}
+	base := &jsonschema.Schema{Type: "object", Properties: jsonschema.NewProperties()}
+	base.Properties.Set("tag", &jsonschema.Schema{Ref: "#/$defs/Tag"})
+	pet := &jsonschema.Schema{AllOf: []*jsonschema.Schema{{Ref: "#/$defs/Base"}}, OneOf: []*jsonschema.Schema{{Ref: "#/$defs/Tag"}}}
+	def := jsonschema.Definitions{"Tag": tag, "Base": base, "Pet": pet}
+
+	root := &jsonschema.Schema{Type: "object", Properties: jsonschema.NewProperties()}
+	root.Properties.Set("pet", &jsonschema.Schema{Ref: "#/$defs/Pet"})
+	root.Properties.Set("tags", &jsonschema.Schema{Type: "array", Items: &jsonschema.Schema{Ref: "#/$defs/Tag"}})
+
+	// twice: the second operation sees the same definitions
+	for range 2 {
+		out, err := flatten(def, root, DefaultDepth)
+		require.NoError(t, err)
+
+		resolved, ok := out.Properties.Get("pet")
+		require.True(t, ok)
+		assert.Equal(t, "object", resolved.Type)
+		prop, ok := resolved.Properties.Get("tag")
+		require.True(t, ok)
+		assert.Equal(t, "string", prop.Type)
+		require.Len(t, resolved.OneOf, 1)
+		assert.Equal(t, "string", resolved.OneOf[0].Type)
+		tags, _ := out.Properties.Get("tags")
+		assert.Equal(t, "string", tags.Items.Type)
+	}
+
+	// definitions and root still reference each other
+	prop, _ := base.Properties.Get("tag")
+	assert.Equal(t, "#/$defs/Tag", prop.Ref)
+	assert.Equal(t, "#/$defs/Base", pet.AllOf[0].Ref)
+	assert.Equal(t, "#/$defs/Tag", pet.OneOf[0].Ref)
+	prop, _ = root.Properties.Get("pet")
+	assert.Equal(t, "#/$defs/Pet", prop.Ref)
+	prop, _ = root.Properties.Get("tags")
+	assert.Equal(t, "#/$defs/Tag", prop.Items.Ref)
+}
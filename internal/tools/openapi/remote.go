@@ -16,6 +16,7 @@ import (
 	"github.com/invopop/jsonschema"
 
 	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tracing"
 	"github.com/pikocloud/pikobrain/internal/utils"
 )
 
@@ -40,7 +41,9 @@ type Config struct {
 
 // New tools from OpenAPI schema.
 // Schema should be OAS 3+.
-// Complex structures are not supported (allOf, anyOf, ...).
+// Compositions are supported: allOf is merged into single schema (unless refs are kept), oneOf and anyOf are kept as is
+// (providers with limited schema dialect downgrade them), variants of oneOf/anyOf with discriminator are restricted
+// to their discriminator values.
 func New(ctx context.Context, config Config) ([]types.Tool, error) {
 	doc, err := parseRemote(ctx, config.URL)
 	if err != nil {
@@ -76,7 +79,8 @@ func New(ctx context.Context, config Config) ([]types.Tool, error) {
 	}
 
 	httpClient := &http.Client{
-		Timeout: config.Timeout,
+		Timeout:   config.Timeout,
+		Transport: tracing.Transport(http.DefaultTransport),
 	}
 
 	var client httpClientFunc = func(req *http.Request) (*http.Response, error) {
@@ -107,7 +111,7 @@ func New(ctx context.Context, config Config) ([]types.Tool, error) {
 
 			var description = utils.Concat(". ", operation.Summary, operation.Description)
 
-			input, err := operation.toolInput()
+			input, body, err := operation.toolInput()
 			if err != nil {
 				if config.IgnoreInvalidOperations {
 					slog.Warn("ignoring invalid operation", "path", p, "method", method, "operation", operation.OperationID, "error", err)
@@ -135,6 +139,7 @@ func New(ctx context.Context, config Config) ([]types.Tool, error) {
 				method:       method,
 				baseURL:      baseURL,
 				pathTemplate: p,
+				body:         body,
 			})
 		}
 	}
@@ -151,6 +156,7 @@ type openAPITool struct {
 	method       string
 	baseURL      *url.URL
 	pathTemplate string
+	body         *requestBody
 }
 
 func (tool *openAPITool) Name() string {
@@ -187,12 +193,24 @@ func (tool *openAPITool) Call(ctx context.Context, message json.RawMessage) (typ
 
 	link.RawQuery = q.Encode()
 
-	out, err := http.NewRequestWithContext(ctx, strings.ToUpper(tool.method), link.String(), bytes.NewReader(req.Body))
+	var (
+		body     []byte
+		bodyType string
+	)
+	if len(req.Body) > 0 && tool.body != nil {
+		encoded, ct, err := tool.body.Encode(ctx, req.Body)
+		if err != nil {
+			return types.Content{}, fmt.Errorf("encode body: %w", err)
+		}
+		body, bodyType = encoded, ct
+	}
+
+	out, err := http.NewRequestWithContext(ctx, strings.ToUpper(tool.method), link.String(), bytes.NewReader(body))
 	if err != nil {
 		return types.Content{}, fmt.Errorf("create request: %w", err)
 	}
-	if len(req.Body) > 0 {
-		out.Header.Set("Content-Type", "application/json")
+	if bodyType != "" {
+		out.Header.Set("Content-Type", bodyType)
 	}
 
 	for k, v := range req.Headers {
@@ -6,16 +6,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/invopop/jsonschema"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 	"gopkg.in/yaml.v3"
 
+	"github.com/pikocloud/pikobrain/internal/providers/types"
 	"github.com/pikocloud/pikobrain/internal/utils"
 )
 
@@ -155,8 +158,128 @@ func (op *operationDefinition) convertRefs() {
 
 type httpClientFunc func(req *http.Request) (*http.Response, error)
 
-// Input for LLM [toolRequest].
-func (op *operationDefinition) toolInput() (*jsonschema.Schema, error) {
+// Supported request body MIME types in order of preference.
+const (
+	mimeJSON      = "application/json"
+	mimeForm      = "application/x-www-form-urlencoded"
+	mimeMultipart = "multipart/form-data"
+	mimeText      = "text/plain"
+)
+
+var supportedBodies = []string{mimeJSON, mimeForm, mimeMultipart, mimeText}
+
+// How tool body is encoded to request.
+type requestBody struct {
+	ContentType string            // one of supportedBodies or JSON-compatible type (ex: application/merge-patch+json)
+	Files       utils.Set[string] // multipart fields which are taken from attachments
+}
+
+// bodyContent picks the most preferred supported content of request body.
+func (op *operationDefinition) bodyContent() (string, *bodyContent, bool) {
+	for _, supported := range supportedBodies {
+		for contentType, content := range op.RequestBody.Content {
+			if utils.ContentType(contentType) == supported {
+				return supported, content, true
+			}
+		}
+		if supported != mimeJSON {
+			continue
+		}
+		for contentType, content := range op.RequestBody.Content {
+			if strings.HasSuffix(utils.ContentType(contentType), "+json") {
+				return utils.ContentType(contentType), content, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// bodySchema for tool input. For multipart, binary fields are replaced by attachment references.
+func (op *operationDefinition) bodySchema(contentType string, content *bodyContent) (*jsonschema.Schema, utils.Set[string]) {
+	var obj = content.Schema
+	if obj == nil {
+		obj = &schemaObject{}
+	}
+	switch contentType {
+	case mimeText:
+		return &jsonschema.Schema{Type: "string", Description: obj.Description}, nil
+	case mimeMultipart:
+		// file fields should be inlined: refs are resolved and compositions are merged
+		fields, required := op.formFields(obj, maxFormDepth)
+		var names = make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		sch := &jsonschema.Schema{
+			Type:        "object",
+			Description: op.resolve(obj).Description,
+			Required:    required,
+			Properties:  orderedmap.New[string, *jsonschema.Schema](),
+		}
+		var files = utils.NewSet[string]()
+		for _, name := range names {
+			prop := fields[name]
+			resolved := op.resolve(prop)
+			switch {
+			case resolved.isBinary():
+				files.Add(name)
+				sch.Properties.Set(name, attachmentSchema(utils.Concat(". ", prop.Description, resolved.Description)))
+			case resolved.Type == "array" && resolved.Items != nil && op.resolve(resolved.Items).isBinary():
+				files.Add(name)
+				sch.Properties.Set(name, &jsonschema.Schema{Type: "array", Description: utils.Concat(". ", prop.Description, resolved.Description), Items: attachmentSchema("")})
+			default:
+				sch.Properties.Set(name, prop.Schema())
+			}
+		}
+		return sch, files
+	default:
+		return obj.Schema(), nil
+	}
+}
+
+// maxFormDepth limits nesting of allOf in multipart schemas.
+const maxFormDepth = 10
+
+// formFields of multipart schema (merged from allOf parts) and names of required fields.
+func (op *operationDefinition) formFields(obj *schemaObject, depth int) (map[string]*schemaObject, []string) {
+	obj = op.resolve(obj)
+	if obj == nil || depth <= 0 {
+		return nil, nil
+	}
+	var fields = make(map[string]*schemaObject)
+	var required []string
+	for _, part := range obj.AllOf {
+		partFields, partRequired := op.formFields(part, depth-1)
+		maps.Copy(fields, partFields)
+		required = append(required, partRequired...)
+	}
+	maps.Copy(fields, obj.Properties)
+	required = append(required, obj.Required...)
+	slices.Sort(required)
+	return fields, slices.Compact(required)
+}
+
+// resolve reference to component schema. Unknown refs are returned as is.
+func (op *operationDefinition) resolve(obj *schemaObject) *schemaObject {
+	if obj == nil || obj.Ref == "" {
+		return obj
+	}
+	if resolved, ok := op.doc.Components.Schemas[strings.TrimPrefix(obj.Ref, "#/$defs/")]; ok {
+		return resolved
+	}
+	return obj
+}
+
+func attachmentSchema(description string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Description: utils.Concat(". ", description, "Attached file name or number of attachment as labeled in conversation (ex: 1 for [attachment #1])"),
+	}
+}
+
+// Input for LLM [toolRequest] and encoding of request body (nil if operation has no body).
+func (op *operationDefinition) toolInput() (*jsonschema.Schema, *requestBody, error) {
 	var schema = &jsonschema.Schema{
 		Type:       "object",
 		Properties: orderedmap.New[string, *jsonschema.Schema](),
@@ -185,15 +308,18 @@ func (op *operationDefinition) toolInput() (*jsonschema.Schema, error) {
 		hasHeaders bool
 	)
 
+	var body *requestBody
 	if op.RequestBody.Content != nil {
-		js, ok := op.RequestBody.Content["application/json"]
+		contentType, content, ok := op.bodyContent()
 		if !ok {
-			return nil, fmt.Errorf("content for operation %q does not support application/json", op.OperationID)
+			return nil, nil, fmt.Errorf("content for operation %q does not support any of: %s", op.OperationID, strings.Join(supportedBodies, ", "))
 		}
-		schema.Properties.Set("body", js.Schema.Schema())
+		bodySchema, files := op.bodySchema(contentType, content)
+		schema.Properties.Set("body", bodySchema)
 		if op.RequestBody.Required {
 			schema.Required = append(schema.Required, "body")
 		}
+		body = &requestBody{ContentType: contentType, Files: files}
 		hasBody = true
 	}
 
@@ -202,7 +328,7 @@ func (op *operationDefinition) toolInput() (*jsonschema.Schema, error) {
 	for _, param := range op.Parameters {
 		pid := paramID{In: param.In, Name: param.Name}
 		if dedup[pid] {
-			return nil, fmt.Errorf("duplicate parameter %+v", pid)
+			return nil, nil, fmt.Errorf("duplicate parameter %+v", pid)
 		}
 		dedup[pid] = true
 
@@ -228,7 +354,7 @@ func (op *operationDefinition) toolInput() (*jsonschema.Schema, error) {
 				headerObj.Required = append(headerObj.Required, param.Name)
 			}
 		default:
-			return nil, fmt.Errorf("unknown parameter %q location %q in operation %q", param.Name, param.In, op.OperationID)
+			return nil, nil, fmt.Errorf("unknown parameter %q location %q in operation %q", param.Name, param.In, op.OperationID)
 		}
 	}
 
@@ -247,11 +373,11 @@ func (op *operationDefinition) toolInput() (*jsonschema.Schema, error) {
 
 	def, err := op.doc.Dependencies(schema)
 	if err != nil {
-		return nil, fmt.Errorf("collect dependencies: %w", err)
+		return nil, nil, fmt.Errorf("collect dependencies: %w", err)
 	}
 	schema.Definitions = def
 
-	return schema, nil
+	return schema, body, nil
 }
 
 func (p *operationParameter) convertRefs() {
@@ -317,9 +443,15 @@ func (doc *schemaDocument) walk(root *jsonschema.Schema, handler func(parent *js
 		}
 	}
 
-	if root.Items != nil && root.Items.Ref != "" {
-		if out.Put(root.Items.Ref) {
-			refs = append(refs, root.Items.Ref)
+	if root.Items != nil {
+		if err := doc.walk(root.Items, handler, out); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range slices.Concat(root.AllOf, root.OneOf, root.AnyOf) {
+		if err := doc.walk(sub, handler, out); err != nil {
+			return err
 		}
 	}
 
@@ -409,6 +541,35 @@ type schemaObject struct {
 	Properties  map[string]*schemaObject `json:"properties,omitempty"`
 	Required    []string                 `json:"required,omitempty"`
 	Items       *schemaObject            `json:"items,omitempty"`
+
+	AllOf         []*schemaObject      `json:"allOf,omitempty" yaml:"allOf"`
+	OneOf         []*schemaObject      `json:"oneOf,omitempty" yaml:"oneOf"`
+	AnyOf         []*schemaObject      `json:"anyOf,omitempty" yaml:"anyOf"`
+	Discriminator *discriminatorObject `json:"discriminator,omitempty"`
+}
+
+type discriminatorObject struct {
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"` // value -> ref
+}
+
+// values of discriminator property for variant. Without explicit mapping it's schema name.
+func (d *discriminatorObject) values(ref string) []any {
+	var keys []string
+	for value, target := range d.Mapping {
+		if target == ref {
+			keys = append(keys, value)
+		}
+	}
+	if len(keys) == 0 {
+		keys = append(keys, strings.TrimPrefix(ref, "#/$defs/"))
+	}
+	slices.Sort(keys)
+	var ans = make([]any, 0, len(keys))
+	for _, k := range keys {
+		ans = append(ans, k)
+	}
+	return ans
 }
 
 func (so *schemaObject) convertRefs() {
@@ -421,6 +582,20 @@ func (so *schemaObject) convertRefs() {
 	for _, v := range so.Properties {
 		v.convertRefs()
 	}
+	for _, v := range slices.Concat(so.AllOf, so.OneOf, so.AnyOf) {
+		v.convertRefs()
+	}
+	if so.Discriminator != nil {
+		for value, target := range so.Discriminator.Mapping {
+			// mapping can be a ref or a schema name
+			so.Discriminator.Mapping[value] = "#/$defs/" + strings.TrimPrefix(target, "#/components/schemas/")
+		}
+	}
+}
+
+// isBinary returns true for file contents.
+func (so *schemaObject) isBinary() bool {
+	return so.Type == "string" && (so.Format == "binary" || so.Format == "base64")
 }
 
 func (so *schemaObject) Schema() *jsonschema.Schema {
@@ -454,9 +629,37 @@ func (so *schemaObject) Schema() *jsonschema.Schema {
 		out.Items = so.Items.Schema()
 	}
 
+	for _, v := range so.AllOf {
+		out.AllOf = append(out.AllOf, v.Schema())
+	}
+	out.OneOf = so.variants(so.OneOf)
+	out.AnyOf = so.variants(so.AnyOf)
+
 	return out
 }
 
+// variants of oneOf/anyOf. If discriminator defined, referenced variants are restricted to their discriminator values.
+func (so *schemaObject) variants(list []*schemaObject) []*jsonschema.Schema {
+	var ans []*jsonschema.Schema
+	for _, v := range list {
+		sch := v.Schema()
+		if so.Discriminator != nil && so.Discriminator.PropertyName != "" && v.Ref != "" {
+			marker := &jsonschema.Schema{
+				Type:       "object",
+				Properties: orderedmap.New[string, *jsonschema.Schema](),
+				Required:   []string{so.Discriminator.PropertyName},
+			}
+			marker.Properties.Set(so.Discriminator.PropertyName, &jsonschema.Schema{
+				Type: "string",
+				Enum: so.Discriminator.values(v.Ref),
+			})
+			sch = &jsonschema.Schema{AllOf: []*jsonschema.Schema{sch, marker}}
+		}
+		ans = append(ans, sch)
+	}
+	return ans
+}
+
 type paramID struct {
 	In   string
 	Name string
@@ -478,24 +681,51 @@ func flatten(def jsonschema.Definitions, child *jsonschema.Schema, maxDepth int)
 
 		return flatten(def, v, maxDepth-1)
 	}
-	var toUpdate = make(map[string]*jsonschema.Schema)
-	for it := child.Properties.Oldest(); it != nil; it = it.Next() {
-		v, err := flatten(def, it.Value, maxDepth-1)
-		if err != nil {
-			return nil, fmt.Errorf("resolve property %q: %w", it.Key, err)
+	// copy: definitions are shared between operations, resolved parts must not leak into them
+	out := *child
+	if child.Properties != nil {
+		out.Properties = jsonschema.NewProperties()
+		for it := child.Properties.Oldest(); it != nil; it = it.Next() {
+			v, err := flatten(def, it.Value, maxDepth-1)
+			if err != nil {
+				return nil, fmt.Errorf("resolve property %q: %w", it.Key, err)
+			}
+			out.Properties.Set(it.Key, v)
 		}
-		toUpdate[it.Key] = v
-	}
-
-	for k, v := range toUpdate {
-		child.Properties.Set(k, v)
 	}
 
 	items, err := flatten(def, child.Items, maxDepth-1)
 	if err != nil {
 		return nil, fmt.Errorf("resolve items: %w", err)
 	}
-	child.Items = items
+	out.Items = items
+
+	// compositions do not add nesting, recursion is still limited by refs
+	if out.AllOf, err = flattenAll(def, "allOf", child.AllOf, maxDepth); err != nil {
+		return nil, err
+	}
+	if out.OneOf, err = flattenAll(def, "oneOf", child.OneOf, maxDepth); err != nil {
+		return nil, err
+	}
+	if out.AnyOf, err = flattenAll(def, "anyOf", child.AnyOf, maxDepth); err != nil {
+		return nil, err
+	}
 
-	return child, nil
+	// parts are resolved, so they can be merged
+	return types.MergeAllOf(&out), nil
+}
+
+func flattenAll(def jsonschema.Definitions, name string, list []*jsonschema.Schema, maxDepth int) ([]*jsonschema.Schema, error) {
+	if list == nil {
+		return nil, nil
+	}
+	var ans = make([]*jsonschema.Schema, 0, len(list))
+	for i, v := range list {
+		resolved, err := flatten(def, v, maxDepth)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s[%d]: %w", name, i, err)
+		}
+		ans = append(ans, resolved)
+	}
+	return ans, nil
 }
@@ -0,0 +1,121 @@
+package openapi_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/tools/openapi"
+)
+
+const compositionSpec = `
+openapi: "3.0.0"
+servers:
+  - url: "http://localhost"
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: "#/components/schemas/Cat"
+        - $ref: "#/components/schemas/Dog"
+      discriminator:
+        propertyName: petType
+        mapping:
+          cat: Cat
+          kitten: "#/components/schemas/Cat"
+    Base:
+      type: object
+      description: Any pet
+      required: [ name ]
+      properties:
+        name:
+          type: string
+    Cat:
+      allOf:
+        - $ref: "#/components/schemas/Base"
+        - type: object
+          properties:
+            meow:
+              type: boolean
+            tags:
+              type: array
+              items:
+                anyOf:
+                  - $ref: "#/components/schemas/Tag"
+                  - type: string
+    Dog:
+      allOf:
+        - $ref: "#/components/schemas/Base"
+        - type: object
+          required: [ bark ]
+          properties:
+            bark:
+              type: string
+    Tag:
+      type: object
+      properties:
+        label:
+          type: string
+`
+
+func loadComposition(t *testing.T, keepRefs bool) *jsonschema.Schema {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(compositionSpec), 0600))
+	tools, err := openapi.New(context.Background(), openapi.Config{URL: "file://" + file, KeepRefs: keepRefs})
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	return tools[0].Input()
+}
+
+func TestNew_compositions(t *testing.T) {
+	body, ok := loadComposition(t, false).Properties.Get("body")
+	require.True(t, ok)
+	require.Len(t, body.OneOf, 2)
+
+	cat, dog := body.OneOf[0], body.OneOf[1]
+	assert.Empty(t, cat.AllOf)
+	assert.Equal(t, "object", cat.Type)
+	assert.Equal(t, "Any pet", cat.Description)
+	assert.ElementsMatch(t, []string{"name", "petType"}, cat.Required)
+	petType, ok := cat.Properties.Get("petType")
+	require.True(t, ok)
+	assert.Equal(t, []any{"cat", "kitten"}, petType.Enum)
+	_, ok = cat.Properties.Get("meow")
+	assert.True(t, ok)
+	tags, ok := cat.Properties.Get("tags")
+	require.True(t, ok)
+	require.Len(t, tags.Items.AnyOf, 2)
+	assert.Empty(t, tags.Items.AnyOf[0].Ref)
+	_, ok = tags.Items.AnyOf[0].Properties.Get("label")
+	assert.True(t, ok)
+
+	assert.ElementsMatch(t, []string{"name", "bark", "petType"}, dog.Required)
+	petType, ok = dog.Properties.Get("petType")
+	require.True(t, ok)
+	assert.Equal(t, []any{"Dog"}, petType.Enum) // implicit mapping
+}
+
+func TestNew_compositionsKeepRefs(t *testing.T) {
+	input := loadComposition(t, true)
+	body, ok := input.Properties.Get("body")
+	require.True(t, ok)
+	assert.Equal(t, "#/$defs/Pet", body.Ref)
+	for _, name := range []string{"Pet", "Cat", "Dog", "Base", "Tag"} {
+		assert.Contains(t, input.Definitions, name)
+	}
+}
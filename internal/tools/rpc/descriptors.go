@@ -0,0 +1,199 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// loadDescriptorSet from file (output of protoc --descriptor_set_out --include_imports).
+func loadDescriptorSet(file string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decode descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("link descriptors: %w", err)
+	}
+	return files, nil
+}
+
+// reflectionStream is common subset of v1 and v1alpha reflection protocols (messages are wire-compatible).
+type reflectionStream interface {
+	Send(*reflectionv1.ServerReflectionRequest) error
+	Recv() (*reflectionv1.ServerReflectionResponse, error)
+	CloseSend() error
+}
+
+type alphaStream struct {
+	reflectionv1alpha.ServerReflection_ServerReflectionInfoClient
+}
+
+func (s alphaStream) Send(req *reflectionv1.ServerReflectionRequest) error {
+	var out reflectionv1alpha.ServerReflectionRequest
+	if err := convert(req, &out); err != nil {
+		return err
+	}
+	return s.ServerReflection_ServerReflectionInfoClient.Send(&out)
+}
+
+func (s alphaStream) Recv() (*reflectionv1.ServerReflectionResponse, error) {
+	res, err := s.ServerReflection_ServerReflectionInfoClient.Recv()
+	if err != nil {
+		return nil, err
+	}
+	var out reflectionv1.ServerReflectionResponse
+	if err := convert(res, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func convert(src, dst proto.Message) error {
+	data, err := proto.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, dst)
+}
+
+// loadReflection discovers services and their descriptors using server reflection (v1, fallback to v1alpha).
+func loadReflection(ctx context.Context, conn grpc.ClientConnInterface) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &reflectionClient{files: make(map[string]*descriptorpb.FileDescriptorProto)}
+	v1, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err == nil {
+		client.stream = v1
+		err = client.collect()
+	}
+	if status.Code(err) == codes.Unimplemented {
+		v1alpha, streamErr := reflectionv1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		if streamErr != nil {
+			return nil, fmt.Errorf("open reflection stream: %w", streamErr)
+		}
+		client.files = make(map[string]*descriptorpb.FileDescriptorProto)
+		client.stream = alphaStream{v1alpha}
+		err = client.collect()
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = client.stream.CloseSend()
+
+	var set descriptorpb.FileDescriptorSet
+	for _, file := range client.files {
+		set.File = append(set.File, file)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("link descriptors: %w", err)
+	}
+	return files, nil
+}
+
+type reflectionClient struct {
+	stream reflectionStream
+	files  map[string]*descriptorpb.FileDescriptorProto
+}
+
+func (rc *reflectionClient) collect() error {
+	res, err := rc.request(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return fmt.Errorf("list services: %w", err)
+	}
+	for _, svc := range res.GetListServicesResponse().GetService() {
+		res, err := rc.request(&reflectionv1.ServerReflectionRequest{
+			MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: svc.GetName()},
+		})
+		if err != nil {
+			return fmt.Errorf("get descriptor of service %q: %w", svc.GetName(), err)
+		}
+		if err := rc.add(res.GetFileDescriptorResponse().GetFileDescriptorProto()); err != nil {
+			return fmt.Errorf("add descriptor of service %q: %w", svc.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// add files and fetch missed dependencies.
+func (rc *reflectionClient) add(raw [][]byte) error {
+	var pending []string
+	for _, data := range raw {
+		var file descriptorpb.FileDescriptorProto
+		if err := proto.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("decode file descriptor: %w", err)
+		}
+		rc.files[file.GetName()] = &file
+		pending = append(pending, file.GetDependency()...)
+	}
+	for _, dep := range pending {
+		if _, ok := rc.files[dep]; ok {
+			continue
+		}
+		res, err := rc.request(&reflectionv1.ServerReflectionRequest{
+			MessageRequest: &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+		})
+		if err == nil {
+			err = rc.add(res.GetFileDescriptorResponse().GetFileDescriptorProto())
+		}
+		if err != nil {
+			// well-known types are not always exposed by servers
+			known, findErr := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if findErr != nil {
+				return fmt.Errorf("get dependency %q: %w", dep, err)
+			}
+			rc.addKnown(known)
+		}
+	}
+	return nil
+}
+
+func (rc *reflectionClient) addKnown(file protoreflect.FileDescriptor) {
+	if _, ok := rc.files[file.Path()]; ok {
+		return
+	}
+	rc.files[file.Path()] = protodesc.ToFileDescriptorProto(file)
+	for i := 0; i < file.Imports().Len(); i++ {
+		rc.addKnown(file.Imports().Get(i).FileDescriptor)
+	}
+}
+
+func (rc *reflectionClient) request(req *reflectionv1.ServerReflectionRequest) (*reflectionv1.ServerReflectionResponse, error) {
+	if err := rc.stream.Send(req); err != nil {
+		if errors.Is(err, io.EOF) {
+			// actual error will be returned by Recv
+			_, err = rc.stream.Recv()
+		}
+		return nil, err
+	}
+	res, err := rc.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := res.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+	}
+	return res, nil
+}
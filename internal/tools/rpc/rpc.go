@@ -0,0 +1,257 @@
+// Package rpc provides tools from gRPC services: each selected unary method becomes a tool.
+//
+// Services are discovered using server reflection (on every tools refresh) or loaded from descriptor set file.
+// Request and response are converted using dynamic messages and protojson.
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/invopop/jsonschema"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tracing"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxResponse = 1024 * 1024
+)
+
+// reflectionPrefix of services which are never exposed unless explicitly included.
+const reflectionPrefix = "grpc.reflection."
+
+type Config struct {
+	Namespace   string               `json:"namespace" yaml:"namespace,omitempty"`               // Prefix all tools with value and underscore
+	Target      string               `json:"target" yaml:"target"`                               // gRPC target (ex: localhost:9090 or dns:///api.example.com:443)
+	TLS         bool                 `json:"tls" yaml:"tls,omitempty"`                           // Use TLS instead of plaintext
+	SkipVerify  bool                 `json:"skip_verify" yaml:"skipVerify,omitempty"`            // Do not verify server certificate
+	Descriptors string               `json:"descriptors,omitempty" yaml:"descriptors,omitempty"` // Descriptor set file (protoc --descriptor_set_out --include_imports). Default is server reflection
+	Headers     []utils.Pair[string] `json:"headers,omitempty" yaml:"headers,omitempty"`         // Extra outgoing metadata
+	Timeout     time.Duration        `json:"timeout" yaml:"timeout,omitempty"`                   // Request timeout. Default is 30s
+	MaxResponse int                  `json:"max_response" yaml:"maxResponse,omitempty"`          // Maximum response message size in bytes. Default is 1MiB
+	Include     []string             `json:"include,omitempty" yaml:"include,omitempty"`         // Only specific services (package.Service) or methods (package.Service/Method). Default is all
+	Exclude     []string             `json:"exclude,omitempty" yaml:"exclude,omitempty"`         // Exclude specific services or methods
+}
+
+// New provider of tools from gRPC server. Connection is lazy; descriptor set (if set) is loaded immediately.
+func New(config Config) (types.ToolProviderFunc, error) {
+	if config.Target == "" {
+		return nil, errors.New("target should be set")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxResponse <= 0 {
+		config.MaxResponse = DefaultMaxResponse
+	}
+
+	var md = metadata.MD{}
+	for _, h := range config.Headers {
+		value, err := h.Get()
+		if err != nil {
+			return nil, fmt.Errorf("get header %q value: %w", h.Name, err)
+		}
+		md.Append(h.Name, value)
+	}
+
+	var files *protoregistry.Files
+	if config.Descriptors != "" {
+		f, err := loadDescriptorSet(config.Descriptors)
+		if err != nil {
+			return nil, fmt.Errorf("load descriptors %q: %w", config.Descriptors, err)
+		}
+		files = f
+	}
+
+	creds := insecure.NewCredentials()
+	if config.TLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: config.SkipVerify}) //nolint:gosec
+	}
+	conn, err := grpc.NewClient(config.Target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(config.MaxResponse)),
+		tracing.ClientStats(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+
+	p := &provider{
+		config:   config,
+		conn:     conn,
+		metadata: md,
+		files:    files,
+		included: utils.NewSet(config.Include...),
+		excluded: utils.NewSet(config.Exclude...),
+	}
+	return p.tools, nil
+}
+
+type provider struct {
+	config   Config
+	conn     *grpc.ClientConn
+	metadata metadata.MD
+	files    *protoregistry.Files
+	included utils.Set[string]
+	excluded utils.Set[string]
+}
+
+func (p *provider) tools(ctx context.Context) ([]types.Tool, error) {
+	files := p.files
+	if files == nil {
+		ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(ctx, p.metadata), p.config.Timeout)
+		defer cancel()
+		f, err := loadReflection(ctx, p.conn)
+		if err != nil {
+			return nil, fmt.Errorf("reflection: %w", err)
+		}
+		files = f
+	}
+	resolver := dynamicpb.NewTypes(files)
+
+	var enabled []protoreflect.MethodDescriptor
+	var shortNames = make(map[string]int)
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			service := services.Get(i)
+			methods := service.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				if !p.enabled(method) {
+					continue
+				}
+				enabled = append(enabled, method)
+				shortNames[p.toolName(method, false)]++
+			}
+		}
+		return true
+	})
+	// files are not ordered
+	slices.SortFunc(enabled, func(a, b protoreflect.MethodDescriptor) int {
+		return strings.Compare(string(a.FullName()), string(b.FullName()))
+	})
+
+	var ans []types.Tool
+	var names = utils.NewSet[string]()
+	for _, method := range enabled {
+		// the same service in different packages (ex: versions) is distinguished by package
+		name := p.toolName(method, false)
+		if shortNames[name] > 1 {
+			name = p.toolName(method, true)
+		}
+		if names.Contains(name) {
+			slog.Warn("grpc method skipped due to name conflict", "method", method.FullName(), "tool", name)
+			continue
+		}
+		names.Add(name)
+		tool := p.newTool(method, resolver)
+		tool.name = name
+		ans = append(ans, tool)
+	}
+	return ans, nil
+}
+
+// toolName of method. Qualified name includes package of service.
+func (p *provider) toolName(method protoreflect.MethodDescriptor, qualified bool) string {
+	service := string(method.Parent().Name())
+	if qualified {
+		service = strings.ReplaceAll(string(method.Parent().FullName()), ".", "_")
+	}
+	return utils.Concat("_", p.config.Namespace, service, string(method.Name()))
+}
+
+func (p *provider) enabled(method protoreflect.MethodDescriptor) bool {
+	service := string(method.Parent().FullName())
+	fullMethod := service + "/" + string(method.Name())
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		slog.Debug("streaming grpc method skipped", "method", fullMethod)
+		return false
+	}
+	if p.excluded.Contains(service) || p.excluded.Contains(fullMethod) {
+		return false
+	}
+	if len(p.included) > 0 {
+		return p.included.Contains(service) || p.included.Contains(fullMethod)
+	}
+	return !strings.HasPrefix(service, reflectionPrefix)
+}
+
+func (p *provider) newTool(method protoreflect.MethodDescriptor, resolver *dynamicpb.Types) *grpcTool {
+	service := method.Parent().(protoreflect.ServiceDescriptor)
+	description := strings.TrimSpace(method.ParentFile().SourceLocations().ByDescriptor(method).LeadingComments)
+	if description == "" {
+		description = "Calls " + string(service.FullName()) + "/" + string(method.Name())
+	}
+	return &grpcTool{
+		description: description,
+		input:       messageSchema(method.Input(), nil),
+		method:      "/" + string(service.FullName()) + "/" + string(method.Name()),
+		request:     method.Input(),
+		response:    method.Output(),
+		resolver:    resolver,
+		provider:    p,
+	}
+}
+
+type grpcTool struct {
+	name        string
+	description string
+	input       *jsonschema.Schema
+	method      string
+	request     protoreflect.MessageDescriptor
+	response    protoreflect.MessageDescriptor
+	resolver    *dynamicpb.Types
+	provider    *provider
+}
+
+func (tool *grpcTool) Name() string {
+	return tool.name
+}
+
+func (tool *grpcTool) Description() string {
+	return tool.description
+}
+
+func (tool *grpcTool) Input() *jsonschema.Schema {
+	return tool.input
+}
+
+func (tool *grpcTool) Call(ctx context.Context, args json.RawMessage) (types.Content, error) {
+	req := dynamicpb.NewMessage(tool.request)
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true, Resolver: tool.resolver}).Unmarshal(args, req); err != nil {
+		return types.Content{}, fmt.Errorf("parse arguments: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(ctx, tool.provider.metadata), tool.provider.config.Timeout)
+	defer cancel()
+
+	res := dynamicpb.NewMessage(tool.response)
+	if err := tool.provider.conn.Invoke(ctx, tool.method, req, res); err != nil {
+		return types.Content{}, fmt.Errorf("call %s: %w", tool.method, err)
+	}
+
+	data, err := (protojson.MarshalOptions{Resolver: tool.resolver}).Marshal(res)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("encode response: %w", err)
+	}
+	return types.Content{Data: data, Mime: types.MIMEJson}, nil
+}
@@ -0,0 +1,178 @@
+package rpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/rpc"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+func newServer(t *testing.T, withReflection bool) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("x-token"); len(values) == 0 || values[0] != "secret" {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return handler(ctx, req)
+	}))
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("db", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	grpc_health_v1.RegisterHealthServer(srv, healthServer)
+	if withReflection {
+		reflection.Register(srv)
+	}
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+	return listener.Addr().String()
+}
+
+func tokenHeader() []utils.Pair[string] {
+	token := "secret"
+	return []utils.Pair[string]{{Name: "x-token", Value: utils.Value[string]{Value: &token}}}
+}
+
+func TestNew_reflection(t *testing.T) {
+	ctx := context.Background()
+	provider, err := rpc.New(rpc.Config{
+		Target:    newServer(t, true),
+		Namespace: "backend",
+		Headers:   tokenHeader(),
+	})
+	require.NoError(t, err)
+
+	tools, err := provider(ctx)
+	require.NoError(t, err)
+	// streaming Watch and reflection service are skipped
+	require.Len(t, tools, 1)
+	check := tools[0]
+	assert.Equal(t, "backend_Health_Check", check.Name())
+	assert.Equal(t, "object", check.Input().Type)
+	service, ok := check.Input().Properties.Get("service")
+	require.True(t, ok)
+	assert.Equal(t, "string", service.Type)
+
+	out, err := check.Call(ctx, json.RawMessage(`{"service": ""}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEJson, out.Mime)
+	assert.JSONEq(t, `{"status": "SERVING"}`, string(out.Data))
+
+	out, err = check.Call(ctx, json.RawMessage(`{"service": "db"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status": "NOT_SERVING"}`, string(out.Data))
+
+	_, err = check.Call(ctx, json.RawMessage(`{"service": "unknown"}`))
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestNew_descriptors(t *testing.T) {
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(grpc_health_v1.File_grpc_health_v1_health_proto),
+	}}
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "health.pb")
+	require.NoError(t, os.WriteFile(file, data, 0600))
+
+	ctx := context.Background()
+	provider, err := rpc.New(rpc.Config{
+		Target:      newServer(t, false),
+		Descriptors: file,
+		Include:     []string{"grpc.health.v1.Health/Check"},
+	})
+	require.NoError(t, err)
+
+	tools, err := provider(ctx)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "Health_Check", tools[0].Name())
+
+	// no metadata
+	_, err = tools[0].Call(ctx, json.RawMessage(`{}`))
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+// userServiceFile defines package with UserService (Get and List methods).
+func userServiceFile(pkg string) *descriptorpb.FileDescriptorProto {
+	method := func(name string) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String("." + pkg + ".Request"),
+			OutputType: proto.String("." + pkg + ".Request"),
+		}
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(strings.ReplaceAll(pkg, ".", "/") + "/user.proto"),
+		Package:     proto.String(pkg),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Request")}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("UserService"),
+			Method: []*descriptorpb.MethodDescriptorProto{method("Get"), method("List")},
+		}},
+	}
+}
+
+func TestNew_conflicts(t *testing.T) {
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		userServiceFile("pkg.v2"),
+		userServiceFile("pkg.v1"),
+		userServiceFile("pkg_v1"),
+	}}
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "users.pb")
+	require.NoError(t, os.WriteFile(file, data, 0600))
+
+	provider, err := rpc.New(rpc.Config{
+		Target:      "localhost:1",
+		Namespace:   "api",
+		Descriptors: file,
+		Include:     []string{"pkg.v1.UserService", "pkg.v2.UserService/Get", "pkg_v1.UserService/Get"},
+	})
+	require.NoError(t, err)
+
+	tools, err := provider(context.Background())
+	require.NoError(t, err)
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name())
+	}
+	// only conflicting names are qualified by package, still conflicting pkg_v1 is skipped
+	assert.Equal(t, []string{"api_pkg_v1_UserService_Get", "api_UserService_List", "api_pkg_v2_UserService_Get"}, names)
+}
+
+func TestNew_invalid(t *testing.T) {
+	_, err := rpc.New(rpc.Config{})
+	require.Error(t, err)
+
+	_, err = rpc.New(rpc.Config{Target: "localhost:1", Descriptors: "/not/exists.pb"})
+	require.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"slices"
+
+	"github.com/invopop/jsonschema"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// messageSchema converts protobuf message to JSON schema in protojson notation. Recursive messages are not expanded.
+func messageSchema(msg protoreflect.MessageDescriptor, visited []protoreflect.FullName) *jsonschema.Schema {
+	if known := wellKnownSchema(msg); known != nil {
+		return known
+	}
+	var obj = &jsonschema.Schema{Type: "object"}
+	if slices.Contains(visited, msg.FullName()) {
+		return obj
+	}
+	visited = append(visited, msg.FullName())
+	obj.Properties = jsonschema.NewProperties()
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		prop := fieldSchema(field, visited)
+		if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			prop.Description = "Only one field of " + string(oneof.Name()) + " can be set"
+		}
+		obj.Properties.Set(field.JSONName(), prop)
+		if field.Cardinality() == protoreflect.Required {
+			obj.Required = append(obj.Required, field.JSONName())
+		}
+	}
+	return obj
+}
+
+func fieldSchema(field protoreflect.FieldDescriptor, visited []protoreflect.FullName) *jsonschema.Schema {
+	switch {
+	case field.IsMap():
+		return &jsonschema.Schema{
+			Type:                 "object",
+			AdditionalProperties: singularSchema(field.MapValue(), visited),
+		}
+	case field.IsList():
+		return &jsonschema.Schema{
+			Type:  "array",
+			Items: singularSchema(field, visited),
+		}
+	default:
+		return singularSchema(field, visited)
+	}
+}
+
+func singularSchema(field protoreflect.FieldDescriptor, visited []protoreflect.FullName) *jsonschema.Schema {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return &jsonschema.Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &jsonschema.Schema{Type: "integer"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &jsonschema.Schema{Type: "number"}
+	case protoreflect.StringKind:
+		return &jsonschema.Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &jsonschema.Schema{Type: "string", ContentEncoding: "base64"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		var names = make([]any, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return &jsonschema.Schema{Type: "string", Enum: names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageSchema(field.Message(), visited)
+	default:
+		return &jsonschema.Schema{}
+	}
+}
+
+// wellKnownSchema returns schema of well-known types which have special JSON mapping, or nil.
+func wellKnownSchema(msg protoreflect.MessageDescriptor) *jsonschema.Schema {
+	switch msg.FullName() {
+	case "google.protobuf.Timestamp":
+		return &jsonschema.Schema{Type: "string", Format: "date-time"}
+	case "google.protobuf.Duration":
+		return &jsonschema.Schema{Type: "string", Description: "Duration in seconds with s suffix (ex: 1.5s)"}
+	case "google.protobuf.FieldMask":
+		return &jsonschema.Schema{Type: "string", Description: "Comma-separated field paths"}
+	case "google.protobuf.Struct":
+		return &jsonschema.Schema{Type: "object"}
+	case "google.protobuf.ListValue":
+		return &jsonschema.Schema{Type: "array", Items: &jsonschema.Schema{}}
+	case "google.protobuf.Value":
+		return &jsonschema.Schema{}
+	case "google.protobuf.Empty":
+		return &jsonschema.Schema{Type: "object"}
+	case "google.protobuf.BoolValue":
+		return &jsonschema.Schema{Type: "boolean"}
+	case "google.protobuf.Int32Value", "google.protobuf.Int64Value", "google.protobuf.UInt32Value", "google.protobuf.UInt64Value":
+		return &jsonschema.Schema{Type: "integer"}
+	case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return &jsonschema.Schema{Type: "number"}
+	case "google.protobuf.StringValue":
+		return &jsonschema.Schema{Type: "string"}
+	case "google.protobuf.BytesValue":
+		return &jsonschema.Schema{Type: "string", ContentEncoding: "base64"}
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+// Package script provides tools defined by Starlark scripts.
+//
+// Scripts are sandboxed: no filesystem access and no modules loading. Standard library contains json, time
+// and (if hosts are allowed) fetch for HTTP requests. Each call is limited by time and execution steps.
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/invopop/jsonschema"
+	starjson "go.starlark.net/lib/json"
+	startime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tracing"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxSteps    = 10_000_000
+	DefaultMaxResponse = 1024 * 1024
+	EntryPoint         = "call" // function in script which receives arguments
+	contextKey         = "context"
+)
+
+type Config struct {
+	Namespace   string        `json:"namespace" yaml:"namespace,omitempty"`              // Prefix all tools with value and underscore
+	Timeout     time.Duration `json:"timeout" yaml:"timeout,omitempty"`                  // Max duration of single call. Default is 10s
+	MaxSteps    uint64        `json:"max_steps" yaml:"maxSteps,omitempty"`               // Max number of execution steps (CPU limit) of single call. Default is 10M
+	MaxResponse int           `json:"max_response" yaml:"maxResponse,omitempty"`         // Max size of result and fetched body in bytes. Default is 1MiB
+	AllowHosts  []string      `json:"allow_hosts,omitempty" yaml:"allowHosts,omitempty"` // Hosts available for fetch (exact or *.domain). If empty, fetch is disabled
+	Tools       []ToolConfig  `json:"tools" yaml:"tools"`
+}
+
+type ToolConfig struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description" yaml:"description"`
+	Input       map[string]any `json:"input" yaml:"input"`                       // JSON schema of arguments (object)
+	Script      string         `json:"script,omitempty" yaml:"script,omitempty"` // Inline script
+	File        string         `json:"file,omitempty" yaml:"file,omitempty"`     // Script file, used if script is not set
+}
+
+// New tools from scripts. Scripts are compiled once, global state is initialized on every call.
+func New(config Config) ([]types.Tool, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxSteps == 0 {
+		config.MaxSteps = DefaultMaxSteps
+	}
+	if config.MaxResponse <= 0 {
+		config.MaxResponse = DefaultMaxResponse
+	}
+	env := &environment{config: config}
+	env.client = &http.Client{
+		Transport: tracing.Transport(http.DefaultTransport),
+		// redirects are checked as well, otherwise allowed host may lead script anywhere
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return env.checkURL(req.URL)
+		},
+	}
+
+	var ans = make([]types.Tool, 0, len(config.Tools))
+	for _, def := range config.Tools {
+		tool, err := env.newTool(def)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q: %w", def.Name, err)
+		}
+		ans = append(ans, tool)
+	}
+	return ans, nil
+}
+
+type environment struct {
+	config Config
+	client *http.Client
+}
+
+func (env *environment) newTool(def ToolConfig) (*scriptTool, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("name is not set")
+	}
+	src, filename := def.Script, def.Name+".star"
+	if src == "" {
+		if def.File == "" {
+			return nil, fmt.Errorf("script or file should be set")
+		}
+		data, err := os.ReadFile(def.File)
+		if err != nil {
+			return nil, fmt.Errorf("read script: %w", err)
+		}
+		src, filename = string(data), def.File
+	}
+
+	predeclared := env.predeclared()
+	_, program, err := starlark.SourceProgramOptions(&syntax.FileOptions{
+		Set:             true,
+		While:           true,
+		TopLevelControl: true,
+		Recursion:       true,
+	}, filename, src, predeclared.Has)
+	if err != nil {
+		return nil, fmt.Errorf("compile script: %w", err)
+	}
+
+	input, err := types.ParseSchema(def.Input)
+	if err != nil {
+		return nil, fmt.Errorf("parse input schema: %w", err)
+	}
+
+	return &scriptTool{
+		name:        utils.Concat("_", env.config.Namespace, def.Name),
+		description: def.Description,
+		input:       input,
+		program:     program,
+		predeclared: predeclared,
+		env:         env,
+	}, nil
+}
+
+func (env *environment) predeclared() starlark.StringDict {
+	dict := starlark.StringDict{
+		"json": starjson.Module,
+		"time": startime.Module,
+	}
+	if len(env.config.AllowHosts) > 0 {
+		dict["fetch"] = starlark.NewBuiltin("fetch", env.fetch)
+	}
+	return dict
+}
+
+// fetch(url, method="GET", headers={}, body="") returns struct with status, headers and body.
+func (env *environment) fetch(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		rawURL  string
+		method  = http.MethodGet
+		headers *starlark.Dict
+		body    string
+	)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "url", &rawURL, "method?", &method, "headers?", &headers, "body?", &body); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse url: %w", fn.Name(), err)
+	}
+	if err := env.checkURL(u); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	ctx, _ := thread.Local(contextKey).(context.Context)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), u.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", fn.Name(), err)
+	}
+	if headers != nil {
+		for _, item := range headers.Items() {
+			k, ok1 := starlark.AsString(item[0])
+			v, ok2 := starlark.AsString(item[1])
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("%s: headers should be strings", fn.Name())
+			}
+			req.Header.Set(k, v)
+		}
+	}
+	res, err := env.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(res.Body, int64(env.config.MaxResponse)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: read body: %w", fn.Name(), err)
+	}
+
+	resHeaders := starlark.NewDict(len(res.Header))
+	for k := range res.Header {
+		if err := resHeaders.SetKey(starlark.String(k), starlark.String(res.Header.Get(k))); err != nil {
+			return nil, err
+		}
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"status":  starlark.MakeInt(res.StatusCode),
+		"headers": resHeaders,
+		"body":    starlark.String(data),
+	}), nil
+}
+
+// checkURL returns error if scheme is not HTTP(S) or host is not allowed.
+func (env *environment) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if !env.allowed(u.Hostname()) {
+		return fmt.Errorf("host %q is not allowed", u.Hostname())
+	}
+	return nil
+}
+
+func (env *environment) allowed(host string) bool {
+	for _, pattern := range env.config.AllowHosts {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if strings.EqualFold(host, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+type scriptTool struct {
+	name        string
+	description string
+	input       *jsonschema.Schema
+	program     *starlark.Program
+	predeclared starlark.StringDict
+	env         *environment
+}
+
+func (st *scriptTool) Name() string {
+	return st.name
+}
+
+func (st *scriptTool) Description() string {
+	return st.description
+}
+
+func (st *scriptTool) Input() *jsonschema.Schema {
+	return st.input
+}
+
+func (st *scriptTool) Call(ctx context.Context, args json.RawMessage) (types.Content, error) {
+	ctx, cancel := context.WithTimeout(ctx, st.env.config.Timeout)
+	defer cancel()
+
+	thread := &starlark.Thread{
+		Name: st.name,
+		Print: func(_ *starlark.Thread, msg string) {
+			slog.Debug("script output", "tool", st.name, "message", msg)
+		},
+		// load is not set, so scripts can not load modules
+	}
+	thread.SetMaxExecutionSteps(st.env.config.MaxSteps)
+	thread.SetLocal(contextKey, ctx)
+
+	stop := context.AfterFunc(ctx, func() {
+		thread.Cancel(ctx.Err().Error())
+	})
+	defer stop()
+
+	globals, err := st.program.Init(thread, st.predeclared)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("init script: %w", err)
+	}
+	entry, ok := globals[EntryPoint].(starlark.Callable)
+	if !ok {
+		return types.Content{}, fmt.Errorf("function %q is not defined in script", EntryPoint)
+	}
+
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	decode := starjson.Module.Members["decode"]
+	value, err := starlark.Call(thread, decode, starlark.Tuple{starlark.String(args)}, nil)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("decode arguments: %w", err)
+	}
+
+	result, err := starlark.Call(thread, entry, starlark.Tuple{value}, nil)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("call script: %w", err)
+	}
+
+	var content types.Content
+	switch v := result.(type) {
+	case starlark.NoneType:
+		content = types.Text("")
+	case starlark.String:
+		content = types.Text(string(v))
+	default:
+		encoded, err := starlark.Call(thread, starjson.Module.Members["encode"], starlark.Tuple{result}, nil)
+		if err != nil {
+			return types.Content{}, fmt.Errorf("encode result: %w", err)
+		}
+		content = types.Content{Data: []byte(encoded.(starlark.String)), Mime: types.MIMEJson}
+	}
+	if len(content.Data) > st.env.config.MaxResponse {
+		return types.Content{}, fmt.Errorf("result is too big (%d bytes, limit %d)", len(content.Data), st.env.config.MaxResponse)
+	}
+	return content, nil
+}
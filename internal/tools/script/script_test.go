@@ -0,0 +1,162 @@
+package script_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/script"
+)
+
+func TestNew(t *testing.T) {
+	tools, err := script.New(script.Config{
+		Namespace: "math",
+		Tools: []script.ToolConfig{{
+			Name:        "sum",
+			Description: "Sum of numbers",
+			Input: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"values": map[string]any{"type": "array", "items": map[string]any{"type": "number"}},
+				},
+			},
+			Script: `
+def call(args):
+    return {"sum": total(args["values"])}
+
+def total(values):
+    s = 0
+    for v in values:
+        s += v
+    return s
+`,
+		}, {
+			Name:   "greet",
+			Script: `def call(args): return "hello " + args.get("name", "world")`,
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+
+	sum := tools[0]
+	assert.Equal(t, "math_sum", sum.Name())
+	assert.Equal(t, "object", sum.Input().Type)
+	assert.NotNil(t, sum.Input().Properties)
+
+	out, err := sum.Call(context.Background(), json.RawMessage(`{"values": [1, 2, 3]}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEJson, out.Mime)
+	assert.JSONEq(t, `{"sum": 6}`, string(out.Data))
+
+	out, err = tools[1].Call(context.Background(), json.RawMessage(`{"name": "piko"}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MIMEText, out.Mime)
+	assert.Equal(t, "hello piko", string(out.Data))
+}
+
+func TestNew_invalid(t *testing.T) {
+	_, err := script.New(script.Config{Tools: []script.ToolConfig{{Name: "broken", Script: "def call(args) return 1"}}})
+	require.Error(t, err)
+
+	_, err = script.New(script.Config{Tools: []script.ToolConfig{{Name: "undefined", Script: "def call(args): return fetch('http://example.com')"}}})
+	require.Error(t, err, "fetch should not be available without allowed hosts")
+
+	_, err = script.New(script.Config{Tools: []script.ToolConfig{{Name: "empty"}}})
+	require.Error(t, err)
+}
+
+func TestScript_limits(t *testing.T) {
+	tools, err := script.New(script.Config{
+		MaxSteps: 1000,
+		Tools: []script.ToolConfig{{
+			Name: "loop",
+			Script: `
+def call(args):
+    while True:
+        pass
+`,
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = tools[0].Call(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many steps")
+}
+
+func TestScript_fetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"method": "` + request.Method + `", "token": "` + request.Header.Get("X-Token") + `"}`))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	tools, err := script.New(script.Config{
+		AllowHosts: []string{u.Hostname()},
+		Tools: []script.ToolConfig{{
+			Name: "remote",
+			Script: `
+def call(args):
+    res = fetch(args["url"], method="POST", headers={"X-Token": "secret"})
+    return json.decode(res.body)
+`,
+		}},
+	})
+	require.NoError(t, err)
+
+	out, err := tools[0].Call(context.Background(), json.RawMessage(`{"url": "`+srv.URL+`"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"method": "POST", "token": "secret"}`, string(out.Data))
+
+	_, err = tools[0].Call(context.Background(), json.RawMessage(`{"url": "https://example.com"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `host "example.com" is not allowed`)
+}
+
+func TestScript_fetchRedirect(t *testing.T) {
+	var reached bool
+	hidden := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		reached = true
+		_, _ = writer.Write([]byte("secret"))
+	}))
+	defer hidden.Close()
+	hiddenURL, err := url.Parse(hidden.URL)
+	require.NoError(t, err)
+
+	// same server, but by name which is not allowed
+	target := "http://localhost:" + hiddenURL.Port()
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, target, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	tools, err := script.New(script.Config{
+		AllowHosts: []string{u.Hostname()},
+		Tools: []script.ToolConfig{{
+			Name: "remote",
+			Script: `
+def call(args):
+    return fetch(args["url"]).body
+`,
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = tools[0].Call(context.Background(), json.RawMessage(`{"url": "`+srv.URL+`"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `host "localhost" is not allowed`)
+	assert.False(t, reached)
+}
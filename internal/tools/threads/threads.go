@@ -0,0 +1,305 @@
+// Package threads provides internal tools which allow model to work with threads: list threads, read and search
+// messages, append notes.
+package threads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/pikocloud/pikobrain/internal/ent"
+	"github.com/pikocloud/pikobrain/internal/ent/message"
+	"github.com/pikocloud/pikobrain/internal/ent/predicate"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+const (
+	DefaultMaxMessages = 20
+	DefaultSearchDepth = 1000
+	DefaultNoteUser    = "note"
+)
+
+// Tool names (without namespace).
+const (
+	ToolListThreads    = "list_threads"
+	ToolReadThread     = "read_thread"
+	ToolSearchMessages = "search_messages"
+	ToolAppendNote     = "append_note"
+)
+
+// ErrAccessDenied returned if thread is not visible for the caller.
+var ErrAccessDenied = errors.New("access to thread denied")
+
+type Config struct {
+	Namespace   string   `json:"namespace" yaml:"namespace,omitempty"`         // Prefix all tools with value and underscore
+	Tools       []string `json:"tools,omitempty" yaml:"tools,omitempty"`       // Enabled tools (list_threads, read_thread, search_messages, append_note). Default is all
+	Prefixes    []string `json:"prefixes,omitempty" yaml:"prefixes,omitempty"` // Only threads with one of prefixes are visible. Default is all threads
+	Own         bool     `json:"own" yaml:"own,omitempty"`                     // Only thread of the caller (current run) is visible
+	MaxMessages int      `json:"max_messages" yaml:"maxMessages,omitempty"`    // Max number of messages returned by read and search. Default is 20
+	SearchDepth int      `json:"search_depth" yaml:"searchDepth,omitempty"`    // Number of the latest messages scanned by search. Default is 1000
+	NoteUser    string   `json:"note_user" yaml:"noteUser,omitempty"`          // User name of appended notes. Default is "note"
+}
+
+// New internal tools for threads backed by messages storage.
+func New(db *ent.Client, config Config) ([]types.Tool, error) {
+	if config.MaxMessages <= 0 {
+		config.MaxMessages = DefaultMaxMessages
+	}
+	if config.SearchDepth <= 0 {
+		config.SearchDepth = DefaultSearchDepth
+	}
+	if config.NoteUser == "" {
+		config.NoteUser = DefaultNoteUser
+	}
+	th := &threads{db: db, config: config}
+
+	all := map[string]types.Tool{
+		ToolListThreads:    types.MustTool(utils.Concat("_", config.Namespace, ToolListThreads), "List available conversation threads with number of messages and last activity", th.list),
+		ToolReadThread:     types.MustTool(utils.Concat("_", config.Namespace, ToolReadThread), "Read the most recent messages of conversation thread", th.read),
+		ToolSearchMessages: types.MustTool(utils.Concat("_", config.Namespace, ToolSearchMessages), "Search messages containing text (case-insensitive) in available threads", th.search),
+		ToolAppendNote:     types.MustTool(utils.Concat("_", config.Namespace, ToolAppendNote), "Append note to conversation thread. Note will be visible in thread history", th.note),
+	}
+
+	enabled := config.Tools
+	if len(enabled) == 0 {
+		enabled = []string{ToolListThreads, ToolReadThread, ToolSearchMessages, ToolAppendNote}
+	}
+	var ans = make([]types.Tool, 0, len(enabled))
+	for _, name := range enabled {
+		tool, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown internal tool %q", name)
+		}
+		ans = append(ans, tool)
+	}
+	return ans, nil
+}
+
+type threads struct {
+	db     *ent.Client
+	config Config
+}
+
+type listRequest struct {
+	Prefix string `json:"prefix,omitempty" jsonschema:"description=Optional prefix of thread name"`
+}
+
+type threadInfo struct {
+	Thread   string    `json:"thread"`
+	Messages int       `json:"messages"`
+	LastAt   time.Time `json:"last_at"`
+}
+
+func (th *threads) list(ctx context.Context, req listRequest) (types.Content, error) {
+	query := th.db.Message.Query().Where(message.Part(0), th.visibility(ctx))
+	if req.Prefix != "" {
+		query = query.Where(message.ThreadHasPrefix(req.Prefix))
+	}
+	// aggregated time is returned as string by some drivers, so time is taken from the last message
+	var rows []struct {
+		Thread string `json:"thread"`
+		Count  int    `json:"count"`
+		Max    int    `json:"max"`
+	}
+	err := query.GroupBy(message.FieldThread).Aggregate(ent.Count(), ent.Max(message.FieldID)).Scan(ctx, &rows)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("list threads: %w", err)
+	}
+	var ids = make([]int, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.Max)
+	}
+	last, err := th.db.Message.Query().Where(message.IDIn(ids...)).Select(message.FieldID, message.FieldCreatedAt).All(ctx)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("get last messages: %w", err)
+	}
+	var lastAt = make(map[int]time.Time, len(last))
+	for _, msg := range last {
+		lastAt[msg.ID] = msg.CreatedAt
+	}
+	var ans = make([]threadInfo, 0, len(rows))
+	for _, row := range rows {
+		ans = append(ans, threadInfo{Thread: row.Thread, Messages: row.Count, LastAt: lastAt[row.Max]})
+	}
+	slices.SortFunc(ans, func(a, b threadInfo) int {
+		return b.LastAt.Compare(a.LastAt)
+	})
+	return jsonContent(ans)
+}
+
+type readRequest struct {
+	Thread string `json:"thread" jsonschema:"description=Thread name"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"description=Max number of the latest messages"`
+}
+
+type messageInfo struct {
+	ID        int       `json:"id"`
+	Thread    string    `json:"thread,omitempty"`
+	Role      string    `json:"role"`
+	User      string    `json:"user,omitempty"`
+	Tool      string    `json:"tool,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (th *threads) read(ctx context.Context, req readRequest) (types.Content, error) {
+	if !th.allowed(ctx, req.Thread) {
+		return types.Content{}, fmt.Errorf("%w: %q", ErrAccessDenied, req.Thread)
+	}
+	list, err := th.db.Message.Query().
+		Where(message.Thread(req.Thread)).
+		Order(message.ByID(sql.OrderDesc())).
+		Limit(th.limit(req.Limit)).
+		All(ctx)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("read thread: %w", err)
+	}
+	slices.Reverse(list) // from oldest to newest
+	var ans = make([]messageInfo, 0, len(list))
+	for _, msg := range list {
+		ans = append(ans, toInfo(msg, false))
+	}
+	return jsonContent(ans)
+}
+
+type searchRequest struct {
+	Query  string `json:"query" jsonschema:"description=Text to search"`
+	Thread string `json:"thread,omitempty" jsonschema:"description=Optional thread name to search in"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"description=Max number of found messages"`
+}
+
+func (th *threads) search(ctx context.Context, req searchRequest) (types.Content, error) {
+	needle := strings.ToLower(strings.TrimSpace(req.Query))
+	if needle == "" {
+		return types.Content{}, fmt.Errorf("empty query")
+	}
+	query := th.db.Message.Query().Where(th.visibility(ctx), message.MimeIn(types.MIMEText, types.MIMEJson, types.MIMEMarkdown, types.MIMECsv))
+	if req.Thread != "" {
+		if !th.allowed(ctx, req.Thread) {
+			return types.Content{}, fmt.Errorf("%w: %q", ErrAccessDenied, req.Thread)
+		}
+		query = query.Where(message.Thread(req.Thread))
+	}
+	// content is stored as bytes, so text search is done in memory over the latest messages
+	list, err := query.Order(message.ByID(sql.OrderDesc())).Limit(th.config.SearchDepth).All(ctx)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("search messages: %w", err)
+	}
+	limit := th.limit(req.Limit)
+	var ans []messageInfo
+	for _, msg := range list {
+		if !strings.Contains(strings.ToLower(string(msg.Content)), needle) {
+			continue
+		}
+		ans = append(ans, toInfo(msg, true))
+		if len(ans) >= limit {
+			break
+		}
+	}
+	return jsonContent(ans)
+}
+
+type noteRequest struct {
+	Thread string `json:"thread" jsonschema:"description=Thread name"`
+	Text   string `json:"text" jsonschema:"description=Note text"`
+}
+
+func (th *threads) note(ctx context.Context, req noteRequest) (types.Content, error) {
+	if strings.TrimSpace(req.Text) == "" {
+		return types.Content{}, fmt.Errorf("empty note")
+	}
+	if req.Thread == "" || !th.allowed(ctx, req.Thread) {
+		return types.Content{}, fmt.Errorf("%w: %q", ErrAccessDenied, req.Thread)
+	}
+	msg, err := th.db.Message.Create().
+		SetThread(req.Thread).
+		SetRole(types.RoleNote).
+		SetUser(th.config.NoteUser).
+		SetMime(types.MIMEText).
+		SetContent([]byte(req.Text)).
+		Save(ctx)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("append note: %w", err)
+	}
+	return jsonContent(map[string]any{"id": msg.ID, "thread": msg.Thread})
+}
+
+// allowed returns true if thread is visible for the caller.
+func (th *threads) allowed(ctx context.Context, thread string) bool {
+	if th.config.Own {
+		if caller := types.CallerFrom(ctx); caller.Thread == "" || caller.Thread != thread {
+			return false
+		}
+	}
+	if len(th.config.Prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range th.config.Prefixes {
+		if strings.HasPrefix(thread, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// visibility is the same as allowed, but as query predicate.
+func (th *threads) visibility(ctx context.Context) predicate.Message {
+	var rules []predicate.Message
+	if th.config.Own {
+		// stateless runs have no own thread, empty thread name matches nothing
+		rules = append(rules, message.Thread(types.CallerFrom(ctx).Thread), message.ThreadNEQ(""))
+	}
+	if len(th.config.Prefixes) > 0 {
+		var prefixes = make([]predicate.Message, 0, len(th.config.Prefixes))
+		for _, prefix := range th.config.Prefixes {
+			prefixes = append(prefixes, message.ThreadHasPrefix(prefix))
+		}
+		rules = append(rules, message.Or(prefixes...))
+	}
+	if len(rules) == 0 {
+		return func(*sql.Selector) {}
+	}
+	return message.And(rules...)
+}
+
+func (th *threads) limit(requested int) int {
+	if requested <= 0 || requested > th.config.MaxMessages {
+		return th.config.MaxMessages
+	}
+	return requested
+}
+
+func toInfo(msg *ent.Message, withThread bool) messageInfo {
+	info := messageInfo{
+		ID:        msg.ID,
+		Role:      msg.Role.String(),
+		User:      msg.User,
+		Tool:      msg.ToolName,
+		CreatedAt: msg.CreatedAt,
+	}
+	if withThread {
+		info.Thread = msg.Thread
+	}
+	if msg.Mime.IsText() {
+		info.Content = string(msg.Content)
+	} else {
+		// binary content is not useful for model
+		info.Content = fmt.Sprintf("[%s %s]", msg.Mime, msg.FileName)
+	}
+	return info
+}
+
+func jsonContent(value any) (types.Content, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return types.Content{}, fmt.Errorf("marshal result: %w", err)
+	}
+	return types.Content{Data: data, Mime: types.MIMEJson}, nil
+}
@@ -0,0 +1,98 @@
+package threads_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/ent"
+	"github.com/pikocloud/pikobrain/internal/ent/message"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
+	"github.com/pikocloud/pikobrain/internal/tools/threads"
+)
+
+func TestThreads(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := ent.New(ctx, ent.Config{
+		URL:          "sqlite://:memory:?cache=shared&_fk=1&_pragma=foreign_keys(1)",
+		MaxConn:      1,
+		IdleConn:     1,
+		IdleTimeout:  time.Minute,
+		ConnLifeTime: time.Hour,
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, thread := range []string{"support-1", "support-2", "private"} {
+		err = db.Message.Create().SetThread(thread).SetRole(types.RoleUser).SetMime(types.MIMEText).SetContent([]byte("hello from " + thread)).Exec(ctx)
+		require.NoError(t, err)
+	}
+
+	tools, err := threads.New(db, threads.Config{Prefixes: []string{"support-"}})
+	require.NoError(t, err)
+	snapshot := types.Snapshot{}
+	for _, tool := range tools {
+		snapshot[tool.Name()] = tool
+	}
+
+	t.Run("list", func(t *testing.T) {
+		out, err := snapshot.Call(ctx, threads.ToolListThreads, json.RawMessage(`{}`))
+		require.NoError(t, err)
+		var list []struct {
+			Thread   string `json:"thread"`
+			Messages int    `json:"messages"`
+		}
+		require.NoError(t, json.Unmarshal(out.Data, &list))
+		require.Len(t, list, 2)
+		assert.Equal(t, 1, list[0].Messages)
+	})
+
+	t.Run("read", func(t *testing.T) {
+		out, err := snapshot.Call(ctx, threads.ToolReadThread, json.RawMessage(`{"thread":"support-1"}`))
+		require.NoError(t, err)
+		assert.Contains(t, string(out.Data), "hello from support-1")
+
+		_, err = snapshot.Call(ctx, threads.ToolReadThread, json.RawMessage(`{"thread":"private"}`))
+		require.ErrorIs(t, err, threads.ErrAccessDenied)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		out, err := snapshot.Call(ctx, threads.ToolSearchMessages, json.RawMessage(`{"query":"HELLO"}`))
+		require.NoError(t, err)
+		assert.Contains(t, string(out.Data), "support-2")
+		assert.NotContains(t, string(out.Data), "private")
+	})
+
+	t.Run("note", func(t *testing.T) {
+		_, err := snapshot.Call(ctx, threads.ToolAppendNote, json.RawMessage(`{"thread":"support-2","text":"call back tomorrow"}`))
+		require.NoError(t, err)
+		n, err := db.Message.Query().Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+		note, err := db.Message.Query().Order(message.ByID(sql.OrderDesc())).First(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleNote, note.Role)
+		assert.Equal(t, threads.DefaultNoteUser, note.User)
+	})
+
+	t.Run("own", func(t *testing.T) {
+		own, err := threads.New(db, threads.Config{Own: true, Tools: []string{threads.ToolReadThread}})
+		require.NoError(t, err)
+		read := own[0]
+
+		callerCtx := types.WithCaller(ctx, types.Caller{Thread: "private"})
+		_, err = read.Call(callerCtx, json.RawMessage(`{"thread":"private"}`))
+		require.NoError(t, err)
+		_, err = read.Call(callerCtx, json.RawMessage(`{"thread":"support-1"}`))
+		require.ErrorIs(t, err, threads.ErrAccessDenied)
+		_, err = read.Call(ctx, json.RawMessage(`{"thread":"private"}`))
+		require.ErrorIs(t, err, threads.ErrAccessDenied)
+	})
+}
@@ -0,0 +1,114 @@
+// Package tracing configures OpenTelemetry tracing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+type Config struct {
+	Exporter string `long:"exporter" env:"EXPORTER" description:"Traces exporter" default:"none" choice:"none" choice:"otlp" choice:"stdout"`
+	Endpoint string `long:"endpoint" env:"ENDPOINT" description:"OTLP HTTP endpoint (host:port). Standard OTEL_EXPORTER_OTLP_* variables are used if not set"`
+	Insecure bool   `long:"insecure" env:"INSECURE" description:"Use plain HTTP for OTLP endpoint"`
+	Service  string `long:"service" env:"SERVICE" description:"Service name in traces" default:"pikobrain"`
+
+	Output io.Writer `no-flag:"true"` // destination of stdout exporter, default is os.Stdout
+}
+
+// Setup global tracer provider and propagation. Returned function flushes and stops exporter.
+func Setup(ctx context.Context, config Config) (func(ctx context.Context) error, error) {
+	// propagation is enabled regardless of exporter, so traces of callers are joined with traces of tools
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch config.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		e, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("create OTLP exporter: %w", err)
+		}
+		exporter = e
+	case ExporterStdout:
+		output := config.Output
+		if output == nil {
+			output = os.Stdout
+		}
+		e, err := stdouttrace.New(stdouttrace.WithWriter(output), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("create stdout exporter: %w", err)
+		}
+		exporter = e
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", config.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.Service)))
+	if err != nil {
+		return nil, fmt.Errorf("create resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Instrument HTTP handler: span per request named by registration pattern in router.
+func Instrument(router *http.ServeMux, next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http", otelhttp.WithSpanNameFormatter(func(_ string, request *http.Request) string {
+		_, route := router.Handler(request)
+		if route == "" {
+			return request.Method
+		}
+		return route
+	}))
+}
+
+// End span and record error (if any).
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Transport propagates trace context to outgoing requests. If base is nil, [http.DefaultTransport] is used.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
+
+// ClientStats propagates trace context to outgoing gRPC calls.
+func ClientStats() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
@@ -1,9 +1,139 @@
 package utils
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
 	"strings"
+	"time"
 )
 
 func ContentType(contentType string) string {
 	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
 }
+
+// HTTP client configuration for outgoing requests.
+type HTTP struct {
+	Headers               []Pair[string] `json:"headers,omitempty" yaml:"headers,omitempty"`                               // Extra outgoing headers.
+	Proxy                 string         `json:"proxy,omitempty" yaml:"proxy,omitempty"`                                   // Proxy URL. If not set, environment variables are used (HTTP_PROXY, HTTPS_PROXY, NO_PROXY).
+	Timeout               time.Duration  `json:"timeout,omitempty" yaml:"timeout,omitempty"`                               // Total request timeout. Zero means no timeout.
+	ConnectTimeout        time.Duration  `json:"connect_timeout,omitempty" yaml:"connectTimeout,omitempty"`                // Timeout for establishing connection.
+	ResponseHeaderTimeout time.Duration  `json:"response_header_timeout,omitempty" yaml:"responseHeaderTimeout,omitempty"` // Timeout for waiting response headers after request is sent.
+	TLS                   struct {
+		CA       string `json:"ca,omitempty" yaml:"ca,omitempty"`             // Path to custom CA (PEM). Appended to system CA.
+		Insecure bool   `json:"insecure,omitempty" yaml:"insecure,omitempty"` // Skip server certificate verification.
+	} `json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// IsZero returns true if nothing configured.
+func (cfg *HTTP) IsZero() bool {
+	return len(cfg.Headers) == 0 &&
+		cfg.Proxy == "" &&
+		cfg.Timeout == 0 &&
+		cfg.ConnectTimeout == 0 &&
+		cfg.ResponseHeaderTimeout == 0 &&
+		cfg.TLS.CA == "" &&
+		!cfg.TLS.Insecure
+}
+
+// Client creates new HTTP client based on configuration.
+func (cfg *HTTP) Client() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   cfg.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		transport.TLSHandshakeTimeout = cfg.ConnectTimeout
+	}
+	transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+
+	if cfg.TLS.CA != "" || cfg.TLS.Insecure {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: cfg.TLS.Insecure, //nolint:gosec
+		}
+		if cfg.TLS.CA != "" {
+			pool, err := loadCA(cfg.TLS.CA)
+			if err != nil {
+				return nil, fmt.Errorf("load CA: %w", err)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var headers = make(http.Header)
+	for _, h := range cfg.Headers {
+		value, err := h.Get()
+		if err != nil {
+			return nil, fmt.Errorf("get header %q value: %w", h.Name, err)
+		}
+		headers.Add(h.Name, value)
+	}
+
+	return WithHeaders(&http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}, headers), nil
+}
+
+// WithHeaders wraps client transport so every outgoing request will contain provided headers.
+// Headers replace values set by caller. Original client is not modified.
+func WithHeaders(client *http.Client, headers http.Header) *http.Client {
+	if len(headers) == 0 {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cp := *client
+	cp.Transport = &headerTransport{
+		base:    base,
+		headers: headers,
+	}
+	return &cp
+}
+
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (ht *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range ht.headers {
+		req.Header[k] = v
+	}
+	return ht.base.RoundTrip(req)
+}
+
+func loadCA(file string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, fmt.Errorf("read system certs: %w", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no certificates found in CA file")
+	}
+	return pool, nil
+}
@@ -0,0 +1,152 @@
+package utils_test
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pikocloud/pikobrain/internal/utils"
+)
+
+func get(t *testing.T, client *http.Client, url string, header http.Header) (string, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	return string(data), err
+}
+
+func TestHTTP_Client(t *testing.T) {
+	t.Run("headers", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = io.WriteString(writer, request.Header.Get("Authorization")+"|"+request.Header.Get("X-Caller"))
+		}))
+		defer srv.Close()
+
+		token := "Bearer secret"
+		cfg := utils.HTTP{Headers: []utils.Pair[string]{{Name: "Authorization", Value: utils.Value[string]{Value: &token}}}}
+		client, err := cfg.Client()
+		require.NoError(t, err)
+
+		// configured headers replace caller's, other headers are kept
+		out, err := get(t, client, srv.URL, http.Header{"Authorization": {"Bearer other"}, "X-Caller": {"test"}})
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer secret|test", out)
+	})
+
+	t.Run("with headers", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = io.WriteString(writer, request.Header.Get("X-Api-Key"))
+		}))
+		defer srv.Close()
+
+		base := &http.Client{}
+		client := utils.WithHeaders(base, http.Header{"X-Api-Key": {"key"}})
+		out, err := get(t, client, srv.URL, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "key", out)
+
+		// original client is not modified
+		assert.Nil(t, base.Transport)
+		out, err = get(t, base, srv.URL, nil)
+		require.NoError(t, err)
+		assert.Empty(t, out)
+		assert.Same(t, base, utils.WithHeaders(base, nil))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		done := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			select {
+			case <-done:
+			case <-request.Context().Done():
+			}
+		}))
+		defer srv.Close()
+		defer close(done)
+
+		for name, cfg := range map[string]utils.HTTP{
+			"total":    {Timeout: 50 * time.Millisecond},
+			"response": {ResponseHeaderTimeout: 50 * time.Millisecond},
+		} {
+			client, err := cfg.Client()
+			require.NoError(t, err)
+			started := time.Now()
+			_, err = get(t, client, srv.URL, nil)
+			require.Error(t, err, name)
+			assert.Less(t, time.Since(started), 5*time.Second, name)
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			// proxy receives absolute URL of the target
+			_, _ = io.WriteString(writer, "proxied "+request.URL.String())
+		}))
+		defer proxy.Close()
+
+		cfg := utils.HTTP{Proxy: proxy.URL}
+		client, err := cfg.Client()
+		require.NoError(t, err)
+		out, err := get(t, client, "http://upstream.invalid/path", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "proxied http://upstream.invalid/path", out)
+
+		cfg = utils.HTTP{Proxy: "://bad"}
+		_, err = cfg.Client()
+		require.Error(t, err)
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = io.WriteString(writer, "ok")
+		}))
+		defer srv.Close()
+
+		// self-signed certificate is rejected by default
+		var cfg utils.HTTP
+		client, err := cfg.Client()
+		require.NoError(t, err)
+		_, err = get(t, client, srv.URL, nil)
+		require.Error(t, err)
+
+		ca := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+		cfg.TLS.CA = ca
+		client, err = cfg.Client()
+		require.NoError(t, err)
+		out, err := get(t, client, srv.URL, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", out)
+
+		cfg = utils.HTTP{}
+		cfg.TLS.Insecure = true
+		client, err = cfg.Client()
+		require.NoError(t, err)
+		out, err = get(t, client, srv.URL, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", out)
+
+		cfg = utils.HTTP{}
+		cfg.TLS.CA = filepath.Join(t.TempDir(), "missing.pem")
+		_, err = cfg.Client()
+		require.Error(t, err)
+	})
+}
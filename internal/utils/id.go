@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RandomID generates random (128 bits) hex identifier with optional prefix.
+func RandomID(prefix string) string {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err) // should never happen
+	}
+	return prefix + hex.EncodeToString(buf[:])
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/pikocloud/pikobrain/internal/brain"
 	"github.com/pikocloud/pikobrain/internal/ent"
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
+	"github.com/pikocloud/pikobrain/internal/providers/types"
 )
 
 //go:embed all:views
@@ -56,6 +58,8 @@ func (b baseView) URL(params ...any) (template.URL, error) {
 type indexView struct {
 	baseView
 	Definition   brain.Definition
+	Capabilities types.Capabilities
+	RateLimit    *ratelimit.Stats
 	LastMessages []*ent.Message
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/pikocloud/pikobrain/internal/brain"
 	"github.com/pikocloud/pikobrain/internal/ent"
 	"github.com/pikocloud/pikobrain/internal/ent/message"
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
 )
 
 func New(db *ent.Client, brain *brain.Brain, baseURL string) (*Web, error) {
@@ -74,9 +75,16 @@ func (w *Web) Index(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	var rateLimit *ratelimit.Stats
+	if stats, ok := w.brain.RateLimit(); ok {
+		rateLimit = &stats
+	}
+
 	err = w.viewIndex.Render(res, indexView{
 		baseView:     w.base(),
 		Definition:   w.brain.Definition(),
+		Capabilities: w.brain.Capabilities(),
+		RateLimit:    rateLimit,
 		LastMessages: messages,
 	})
 	if err != nil {
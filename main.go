@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jessevdk/go-flags"
@@ -18,9 +20,13 @@ import (
 
 	"github.com/pikocloud/pikobrain/internal/brain"
 	"github.com/pikocloud/pikobrain/internal/ent"
+	"github.com/pikocloud/pikobrain/internal/metrics"
+	"github.com/pikocloud/pikobrain/internal/providers/ratelimit"
 	"github.com/pikocloud/pikobrain/internal/providers/types"
 	"github.com/pikocloud/pikobrain/internal/server"
 	"github.com/pikocloud/pikobrain/internal/tools/loader"
+	"github.com/pikocloud/pikobrain/internal/tools/mcp"
+	"github.com/pikocloud/pikobrain/internal/tracing"
 	"github.com/pikocloud/pikobrain/internal/web"
 )
 
@@ -28,12 +34,13 @@ type Config struct {
 	Debug struct {
 		Enable bool `long:"enable" env:"ENABLE" description:"Enable debug mode"`
 	} `group:"Debug" namespace:"debug" env-namespace:"DEBUG"`
-	DB      ent.Config    `group:"Database configuration" namespace:"db" env-namespace:"DB"`
-	Timeout time.Duration `long:"timeout" env:"TIMEOUT" description:"LLM timeout" default:"30s"`
-	Refresh time.Duration `long:"refresh" env:"REFRESH" description:"Refresh interval for tools" default:"30s"`
-	Config  string        `long:"config" env:"CONFIG" description:"Config file" default:"brain.yaml"`
-	Tools   string        `long:"tools" env:"TOOLS" description:"Tool file"`
-	BaseURL string        `long:"base-url" env:"BASE_URL" description:"Base URL for UI"`
+	DB      ent.Config     `group:"Database configuration" namespace:"db" env-namespace:"DB"`
+	Tracing tracing.Config `group:"Tracing configuration" namespace:"tracing" env-namespace:"TRACING"`
+	Timeout time.Duration  `long:"timeout" env:"TIMEOUT" description:"LLM timeout" default:"30s"`
+	Refresh time.Duration  `long:"refresh" env:"REFRESH" description:"Refresh interval for tools" default:"30s"`
+	Config  string         `long:"config" env:"CONFIG" description:"Config file" default:"brain.yaml"`
+	Tools   string         `long:"tools" env:"TOOLS" description:"Tool file"`
+	BaseURL string         `long:"base-url" env:"BASE_URL" description:"Base URL for UI"`
 	Server  struct {
 		Bind              string        `long:"bind" env:"BIND" description:"Bind address" default:":8080"`
 		TLS               bool          `long:"tls" env:"TLS" description:"Enable TLS"`
@@ -47,6 +54,10 @@ type Config struct {
 		Timeout           time.Duration `long:"timeout" env:"TIMEOUT" description:"Any request timeout" default:"30s"`
 		MaxBodySize       int64         `long:"max-body-size" env:"MAX_BODY_SIZE" description:"Maximum payload size in bytes" default:"1048576"` // 1 MiB
 	} `group:"HTTP server configuration" namespace:"http" env-namespace:"HTTP"`
+	MCP struct {
+		Enable bool   `long:"enable" env:"ENABLE" description:"Enable MCP (streamable HTTP) endpoint"`
+		Path   string `long:"path" env:"PATH" description:"Path of MCP endpoint" default:"/mcp"`
+	} `group:"MCP server configuration" namespace:"mcp" env-namespace:"MCP"`
 }
 
 func main() {
@@ -55,14 +66,25 @@ func main() {
 	parser.ShortDescription = `PikoBrain`
 	parser.LongDescription = `Server for orchestrating LLM providers and agents`
 
-	_, err := parser.Parse()
+	parser.SubcommandsOptional = true
+	_, err := parser.AddCommand("mcp", "Serve MCP over stdio", "Expose tools and chat over Model Context Protocol on stdin/stdout", &mcpCommand{config: &app})
 	if err != nil {
-		os.Exit(1)
+		panic(err)
+	}
+	// without sub-command HTTP server is started
+	parser.CommandHandler = func(command flags.Commander, args []string) error {
+		if command == nil {
+			command = &app
+		}
+		if err := command.Execute(args); err != nil {
+			slog.Error("failed run", "error", err)
+			os.Exit(2)
+		}
+		return nil
 	}
 
-	if err := app.Execute(nil); err != nil {
-		slog.Error("failed run", "error", err)
-		os.Exit(2)
+	if _, err := parser.Parse(); err != nil {
+		os.Exit(1)
 	}
 }
 
@@ -70,43 +92,21 @@ func (config *Config) Execute([]string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	config.setupLogging()
-	store, err := ent.New(ctx, config.DB)
-	if err != nil {
-		return fmt.Errorf("create store: %w", err)
-	}
-	defer store.Close()
-
-	var toolBox types.DynamicToolbox
-
-	if config.Tools != "" {
-		tools, err := loader.LoadFile(config.Tools)
-		if err != nil {
-			return fmt.Errorf("load tools: %w", err)
-		}
-
-		toolBox.Provider(tools...)
-	}
-
-	slog.Info("loading initial tools state...")
-	if err := toolBox.Update(ctx, true); err != nil {
-		return fmt.Errorf("load tools: %w", err)
-	}
-
-	slog.Info("loading brain config")
-	mind, err := brain.NewFromFile(ctx, store, &toolBox, config.Config)
+	env, cleanup, err := config.setup(ctx)
 	if err != nil {
-		return fmt.Errorf("load brain config: %w", err)
+		return err
 	}
-
-	slog.Info("configuration loaded")
+	defer cleanup()
 
 	// setup backend
 	srv := &server.Server{
-		Brain:   mind,
-		Timeout: config.Timeout,
+		Brain:    env.mind,
+		Timeout:  config.Timeout,
+		Reserved: config.reservedThreads(),
 	}
 	router := http.NewServeMux()
+	router.HandleFunc("POST /embeddings", srv.Embeddings)
+	router.HandleFunc("GET /rate-limit", srv.RateLimit)
 	router.HandleFunc("PUT /{thread}", srv.Append)
 	router.HandleFunc("POST /{thread}", srv.Chat)
 	router.HandleFunc("POST /{thread}/", srv.Chat)
@@ -114,9 +114,26 @@ func (config *Config) Execute([]string) error {
 	router.HandleFunc("GET /ready", func(writer http.ResponseWriter, _ *http.Request) {
 		writer.WriteHeader(http.StatusOK)
 	})
+	router.Handle("GET /metrics", metrics.Handler())
+	if _, ok := env.mind.RateLimit(); ok {
+		metrics.RateLimit(func() ratelimit.Stats {
+			stats, _ := env.mind.RateLimit()
+			return stats
+		})
+	}
+
+	// MCP (streamable HTTP)
+	var mcpServer *mcp.Server
+	if config.MCP.Enable {
+		mcpServer = newMCPServer(env, srv)
+		for _, method := range []string{http.MethodPost, http.MethodGet, http.MethodDelete} {
+			router.Handle(method+" "+config.MCP.Path, mcpServer)
+		}
+		slog.Info("MCP endpoint enabled", "path", config.MCP.Path)
+	}
 
 	// frontend
-	front, err := web.New(store, mind, config.BaseURL)
+	front, err := web.New(env.store, env.mind, config.BaseURL)
 	if err != nil {
 		return fmt.Errorf("create frontend: %w", err)
 	}
@@ -129,7 +146,7 @@ func (config *Config) Execute([]string) error {
 	// setup HTTP server
 	httpServer := &http.Server{
 		Addr:              config.Server.Bind,
-		Handler:           config.httpLimiter(ctx, router),
+		Handler:           config.httpLimiter(ctx, tracing.Instrument(router, metrics.Instrument(router))),
 		ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
 	}
 
@@ -168,26 +185,163 @@ func (config *Config) Execute([]string) error {
 
 	// periodically update
 	wg.Go(func(ctx context.Context) error {
-		t := time.NewTicker(config.Refresh)
-		defer t.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return nil
-			case <-t.C:
+		return config.refreshTools(ctx, env.toolBox, func() {
+			if mcpServer != nil {
+				mcpServer.Refresh()
 			}
+		})
+	})
 
-			slog.Debug("updating tools cache")
-			err := toolBox.Update(ctx, false)
-			if err != nil {
-				slog.Warn("failed update tools cache", "error", err)
-			}
-		}
+	return wg.Wait()
+}
+
+// ServeMCP serves tools and chat over MCP on stdin/stdout. Logs are written to stderr.
+func (config *Config) ServeMCP() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	// stdout is reserved for protocol messages
+	config.Tracing.Output = os.Stderr
+
+	env, cleanup, err := config.setup(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	mcpServer := newMCPServer(env, &server.Server{
+		Brain:    env.mind,
+		Timeout:  config.Timeout,
+		Reserved: config.reservedThreads(),
 	})
 
+	wg := pool.New().WithContext(ctx).WithCancelOnError()
+	wg.Go(func(ctx context.Context) error {
+		defer cancel() // client disconnected
+		slog.Info("serving MCP over stdio")
+		return mcpServer.ServeStdio(ctx, os.Stdin, os.Stdout)
+	})
+	wg.Go(func(ctx context.Context) error {
+		return config.refreshTools(ctx, env.toolBox, mcpServer.Refresh)
+	})
 	return wg.Wait()
 }
 
+// environment shared by HTTP server and MCP stdio server.
+type environment struct {
+	store   *ent.Client
+	toolBox *types.DynamicToolbox
+	mind    *brain.Brain
+}
+
+// setup tracing, storage, tools and brain. Cleanup should be called even on error.
+func (config *Config) setup(ctx context.Context) (*environment, func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	config.setupLogging()
+
+	shutdownTracing, err := tracing.Setup(ctx, config.Tracing)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("setup tracing: %w", err)
+	}
+	cleanups = append(cleanups, func() {
+		tctx, tcancel := context.WithTimeout(context.Background(), config.Server.Graceful)
+		defer tcancel()
+		if err := shutdownTracing(tctx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	})
+
+	store, err := ent.New(ctx, config.DB)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("create store: %w", err)
+	}
+	cleanups = append(cleanups, func() {
+		_ = store.Close()
+	})
+
+	var toolBox types.DynamicToolbox
+
+	if config.Tools != "" {
+		tools, err := loader.LoadFile(config.Tools, store)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("load tools: %w", err)
+		}
+
+		toolBox.Provider(tools...)
+	}
+
+	slog.Info("loading initial tools state...")
+	if err := toolBox.Update(ctx, true); err != nil {
+		return nil, cleanup, fmt.Errorf("load tools: %w", err)
+	}
+
+	slog.Info("loading brain config")
+	mind, err := brain.NewFromFile(ctx, store, &toolBox, config.Config)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("load brain config: %w", err)
+	}
+
+	slog.Info("configuration loaded")
+	return &environment{store: store, toolBox: &toolBox, mind: mind}, cleanup, nil
+}
+
+// refreshTools periodically until context canceled. Callback is invoked after each update.
+func (config *Config) refreshTools(ctx context.Context, toolBox *types.DynamicToolbox, updated func()) error {
+	t := time.NewTicker(config.Refresh)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+
+		slog.Debug("updating tools cache")
+		err := toolBox.Update(ctx, false)
+		metrics.ToolRefresh(err)
+		if err != nil {
+			slog.Warn("failed update tools cache", "error", err)
+		}
+		updated()
+	}
+}
+
+// newMCPServer publishes tools from toolbox and chat tool.
+func newMCPServer(env *environment, srv *server.Server) *mcp.Server {
+	mcpServer := &mcp.Server{
+		Toolbox: env.toolBox,
+		Tools:   []types.Tool{srv.ChatTool()},
+		Call:    brain.CallTool,
+	}
+	mcpServer.Refresh() // remember initial state
+	return mcpServer
+}
+
+// reservedThreads returns thread names shadowed by routes, including MCP endpoint. Threads created over MCP stdio
+// should be reachable by HTTP as well, so MCP path is reserved regardless of the endpoint state.
+func (config *Config) reservedThreads() []string {
+	reserved := slices.Clone(server.ReservedThreads)
+	if name := strings.Trim(config.MCP.Path, "/"); name != "" && !strings.Contains(name, "/") {
+		reserved = append(reserved, name)
+	}
+	return reserved
+}
+
+// mcpCommand serves MCP over stdio.
+type mcpCommand struct {
+	config *Config
+}
+
+func (cmd *mcpCommand) Execute([]string) error {
+	return cmd.config.ServeMCP()
+}
+
 func (config *Config) setupLogging() {
 	if !config.Debug.Enable {
 		return